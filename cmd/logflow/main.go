@@ -1,22 +1,103 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 	"syscall"
+	"text/tabwriter"
+	"time"
+	_ "time/tzdata" // --timezone names resolve without system zoneinfo, e.g. on Windows
 
 	"github.com/Yriskit-ai/logflow/internal/ipc"
+	logparser "github.com/Yriskit-ai/logflow/internal/log"
+	"github.com/Yriskit-ai/logflow/internal/paths"
+	"github.com/Yriskit-ai/logflow/internal/sinks"
 	"github.com/Yriskit-ai/logflow/internal/sources"
 	"github.com/Yriskit-ai/logflow/internal/ui"
+	"github.com/Yriskit-ai/logflow/internal/web"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
 )
 
 var (
 	sourceName      string
+	feedDashboard   bool
 	dockerContainer string
 	podmanContainer string
+	composeProject  string
+	fifoPath        string
+	duplicateNames  string
+	attachAll       bool
+	mergeContainers bool
+	sshTarget       string
+	timeLayouts     []string
+	maxLineBytes    int
+	maxMetaBytes    int
+	levelsFile      string
+	noColor         bool
+	socketPath      string
+	teeFile         string
+	groupSpecs      []string
+	saveHistory     bool
+	holdLevel       string
+	holdDuration    time.Duration
+	teeFormat       string
+	jsonIn          bool
+	skipLines       int
+	headLines       int
+	rawLines        bool
+	orderWindow     time.Duration
+	spillDir        string
+	showFields      []string
+	timezone        string
+	assumeTimezone  string
+	presetName      string
+	watchSources    []string
+	maxPanes        int
+	dropHidden      bool
+	lineFormat      string
+	rawLevel        bool
+	tickRate        time.Duration
+	singleView      bool
+	debugOverlay    bool
+	levelAlign      string
+	levelStyle      string
+	quiet           bool
+	logFile         string
+	ipcToken        string
+	tlsCert         string
+	tlsKey          string
+	useTLS          bool
+	caFile          string
+	traceKeys       []string
+	bufferSize      int
+	force           bool
+	strict          bool
+	onMatch         []string
+	exitOnIdle      time.Duration
+	highlightSpecs  []string
+	errorRateSpecs  []string
+	webAddr         string
+	lastWindow      time.Duration
+	useDockerAPI    bool
+
+	catFile   string
+	catFilter string
+	catFormat string
+
+	parseOutput string
 )
 
 var rootCmd = &cobra.Command{
@@ -27,30 +108,258 @@ var rootCmd = &cobra.Command{
 Examples:
   logflow                                    # Start the dashboard
   python app.py | logflow --source backend  # Pipe logs to dashboard
-  logflow --docker redis --source redis     # Attach to Docker container`,
-	Run: runDashboard,
+  logflow --docker redis --source redis     # Attach to Docker container
+  logflow --docker label=com.docker.compose.service=api  # Attach by label
+  logflow --ssh user@host --docker api      # Attach to a container on a remote host
+  logflow --compose shop                    # Attach to every service of a compose project`,
+	PersistentPreRun: applyGlobalFlags,
+	Run:              runDashboard,
+
+	// main reports errors through the diagnostics logger
+	SilenceErrors: true,
+}
+
+var catCmd = &cobra.Command{
+	Use:   "cat",
+	Short: "Parse a finite log stream and print it without the TUI",
+	Long: `cat runs logs through logflow's parser and prints the normalized entries, then exits.
+
+Examples:
+  logflow cat --file app.log                 # Print a log file
+  docker logs api | logflow cat --filter warn  # Only warnings and errors
+  logflow cat --file app.log --format json   # Emit one JSON entry per line`,
+	Args: cobra.NoArgs,
+	RunE: runCat,
+}
+
+var parseCmd = &cobra.Command{
+	Use:   "parse [file]",
+	Short: "Show how each line is parsed: level, timestamp, content and fields",
+	Long: `parse runs every line of a file, or stdin, through the parser and shows what it made
+of it, to track down why a line got a level or timestamp it shouldn't have.
+
+Examples:
+  logflow parse app.log                        # One row per line
+  docker logs api | logflow parse -o json      # One JSON object per line
+  logflow parse --input-format logfmt app.log  # Check a declared format`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runParse,
 }
 
 func init() {
 	rootCmd.Flags().StringVarP(&sourceName, "source", "s", "", "Source name for this log stream")
+	rootCmd.Flags().BoolVar(&feedDashboard, "feed", false, "Feed stdin to the running dashboard, named after the program writing to it unless --source is given")
 	rootCmd.Flags().StringVar(&dockerContainer, "docker", "", "Docker container name/ID to attach to")
 	rootCmd.Flags().StringVar(&podmanContainer, "podman", "", "Podman container name/ID to attach to")
+	rootCmd.Flags().StringVar(&fifoPath, "fifo", "", "Named pipe (made with mkfifo) to read logs from, reopened whenever its writers close it")
+	rootCmd.Flags().StringVar(&composeProject, "compose", "", "Docker Compose project to attach to, one pane per service")
+	rootCmd.PersistentFlags().StringVar(&timezone, "timezone", "Local", "Time zone timestamps are shown in: Local, UTC or an IANA name like Europe/Berlin")
+	rootCmd.PersistentFlags().StringVar(&assumeTimezone, "assume-timezone", "Local", "Time zone of timestamps that carry none, like 2006-01-02 15:04:05: Local, UTC or an IANA name")
+	rootCmd.PersistentFlags().StringSliceVar(&timeLayouts, "time-layout", nil, "Additional Go time layouts to try when parsing timestamps (repeatable)")
+	rootCmd.Flags().StringVar(&socketPath, "socket", paths.Socket(), "Socket path (host:port on Windows) the dashboard listens on and feeders connect to, in $LOGFLOW_HOME when set")
+	rootCmd.Flags().StringSliceVar(&watchSources, "watch", nil, "Show an empty pane for a source expected to connect, in the order given (repeatable or comma-separated)")
+	rootCmd.Flags().StringVar(&presetName, "preset", "", "Start with a filter preset applied: built-in all, errors-only or warnings, or one saved with P")
+	rootCmd.Flags().StringArrayVar(&groupSpecs, "group", nil, "Define a source group tab as name=source1,source2 (repeatable, globs allowed)")
+	rootCmd.Flags().BoolVar(&saveHistory, "save-search-history", false, "Persist search history across sessions in the state directory")
+	rootCmd.Flags().StringVar(&holdLevel, "pause-on", "", "Pause auto-scroll when an entry at this level or above arrives (e.g. error)")
+	rootCmd.Flags().DurationVar(&holdDuration, "pause-for", 3*time.Second, "How long --pause-on holds auto-scroll")
+	rootCmd.Flags().StringVar(&teeFile, "tee", "", "Also append every received entry to this file")
+	rootCmd.Flags().StringArrayVar(&onMatch, "on-match", nil, "Run a shell command with the entry as JSON on stdin when a line matches, as regex:command (repeatable, at most once a second each)")
+	rootCmd.Flags().StringVar(&teeFormat, "tee-format", "text", "Format for --tee: text or json")
+	rootCmd.PersistentFlags().StringVar(&levelsFile, "levels", "", "JSON file defining custom log levels, their order and colors (default levels.json in the config directory, if present)")
+	rootCmd.PersistentFlags().IntVar(&maxLineBytes, "max-line-bytes", sources.DefaultMaxLineBytes, "Truncate longer lines at ingest (0 disables)")
+	rootCmd.PersistentFlags().IntVar(&maxMetaBytes, "max-metadata-bytes", sources.DefaultMaxMetadataBytes, "Cap the metadata of each entry at ingest, flattening and dropping fields past it (0 disables)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress logflow's own diagnostic output")
+	rootCmd.Flags().StringVar(&duplicateNames, "duplicate-sources", "suffix", "When feeders share a --source name: suffix shows each as its own source (api, api#2), tag keeps one pane and tags entries with an instance field, merge mixes them untagged")
+	rootCmd.Flags().StringVar(&ipcToken, "token", "", "Shared secret feeders must present to the dashboard")
+	rootCmd.Flags().StringVar(&tlsCert, "tls-cert", "", "Serve TLS on a host:port --socket with this certificate (needs --tls-key)")
+	rootCmd.Flags().StringVar(&tlsKey, "tls-key", "", "Private key for --tls-cert")
+	rootCmd.Flags().BoolVar(&useTLS, "tls", false, "Connect to the dashboard over TLS, trusting the system roots")
+	rootCmd.Flags().StringVar(&caFile, "ca", "", "Connect over TLS trusting the CA certificates in this PEM file (implies --tls)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Write logflow's own diagnostics to this file instead of stderr")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also honors NO_COLOR and FORCE_COLOR)")
+	rootCmd.PersistentFlags().BoolVar(&rawLines, "raw", false, "Keep lines verbatim, skipping JSON and timestamp parsing (levels are still detected)")
+	rootCmd.PersistentFlags().BoolVar(&rawLevel, "raw-level", false, "Like --raw, and skip level detection too")
+	rootCmd.Flags().StringVar(&lineFormat, "format", "", "Parse every line as this format instead of detecting it: "+strings.Join(logparser.Formats(), ", "))
+	rootCmd.Flags().BoolVar(&jsonIn, "json-in", false, "Pass through stdin lines that are already logflow JSON entries")
+	rootCmd.Flags().IntVar(&skipLines, "skip", 0, "Drop the first N lines of stdin before showing any")
+	rootCmd.Flags().IntVar(&headLines, "head", 0, "Stop reading stdin after showing N lines, counted after --skip (0 for no limit)")
+	rootCmd.Flags().StringVar(&levelAlign, "level-align", "left", "Pad level badges so content lines up: left, right or none")
+	rootCmd.Flags().StringVar(&levelStyle, "level-style", "full", "Write levels as full names, their first letter (short) or icons (icon) to save width")
+	rootCmd.Flags().BoolVar(&debugOverlay, "debug", false, "Show the performance overlay (ingest rate, drops, render time)")
+	rootCmd.Flags().BoolVar(&singleView, "single", false, "Show one source at a time across the whole terminal, like tail -f, as a lone source always is (tab switches source)")
+	rootCmd.Flags().DurationVar(&tickRate, "tick-rate", ui.DefaultTickRate, "Idle refresh interval for the dashboard (0 disables)")
+	rootCmd.Flags().BoolVar(&useDockerAPI, "docker-api", false, "Read --docker logs from the Engine API socket instead of the docker CLI, falling back to the CLI if unreachable")
+	rootCmd.Flags().StringVar(&sshTarget, "ssh", "", "Run --docker/--podman on a remote host over SSH (user@host)")
+	rootCmd.Flags().BoolVar(&attachAll, "all", false, "Attach to every container matching --docker/--podman")
+	rootCmd.Flags().DurationVar(&orderWindow, "order-window", sources.DefaultOrderWindow, "Hold container lines this long to interleave stdout and stderr in emission order (0 disables)")
+	rootCmd.Flags().StringVar(&spillDir, "spill-dir", "", "Keep entries evicted from memory in a file per source in this directory, still searchable and dumped")
+	rootCmd.Flags().StringArrayVar(&errorRateSpecs, "error-rate", nil, "Alert when a source gets more than count errors within a window, as count/window like 10/30s; source=count/window or source=off overrides it per source (repeatable, globs allowed)")
+	rootCmd.Flags().StringArrayVar(&highlightSpecs, "highlight", nil, "Style matches of a pattern in every line, as pattern:style, e.g. 'panic:bold,red' (repeatable, earlier rules win)")
+	rootCmd.Flags().StringSliceVar(&showFields, "show-fields", nil, "Metadata fields to append to every line, e.g. req_id,http.status (I toggles)")
+	rootCmd.Flags().StringSliceVar(&traceKeys, "trace-keys", logparser.DefaultTraceKeys, "Metadata keys holding a trace or request ID, tried in order (R follows one)")
+	rootCmd.Flags().IntVar(&bufferSize, "buffer-size", ui.DefaultBufferSize, "Entries each pane keeps in memory")
+	rootCmd.Flags().BoolVar(&force, "force", false, "Start even if --buffer-size could exhaust the system's memory")
+	rootCmd.Flags().DurationVar(&exitOnIdle, "exit-on-idle", 0, "Quit once every source has disconnected and nothing has arrived for this long (0 disables)")
+	rootCmd.Flags().DurationVar(&lastWindow, "last", 0, "Show only entries from this far back, e.g. 5m, sliding as time passes (0 shows all)")
+	rootCmd.Flags().StringVar(&webAddr, "web", "", "Serve the logs read-only over HTTP at this address, e.g. :8088 (localhost unless a host is given)")
+	rootCmd.Flags().BoolVar(&strict, "strict", false, "Exit with status 2 if any source failed or entries were dropped during the session")
+	rootCmd.Flags().IntVar(&maxPanes, "max-panes", 0, "Show at most this many sources, hiding the least recently active past it (0 for no cap)")
+	rootCmd.Flags().BoolVar(&dropHidden, "drop-hidden", false, "With --max-panes, discard hidden sources' buffers instead of keeping them for search")
+	rootCmd.Flags().BoolVar(&mergeContainers, "merge", false, "With --all, show every matching container in one pane, tagged by container")
+
+	catCmd.Flags().StringVarP(&catFile, "file", "f", "", "Log file to read (defaults to stdin)")
+	catCmd.Flags().StringVar(&catFilter, "filter", "", "Minimum level to print, e.g. warn (default every level)")
+	catCmd.Flags().StringVar(&catFormat, "format", "auto", "Output format: auto, color, text or json")
+	catCmd.Flags().StringVar(&lineFormat, "input-format", "", "Parse every line as this format instead of detecting it: "+strings.Join(logparser.Formats(), ", "))
+	rootCmd.AddCommand(catCmd)
+
+	parseCmd.Flags().StringVarP(&parseOutput, "output", "o", "table", "Output: table or json")
+	parseCmd.Flags().StringVar(&lineFormat, "input-format", "", "Parse every line as this format instead of detecting it: "+strings.Join(logparser.Formats(), ", "))
+	rootCmd.AddCommand(parseCmd)
+
+	documentEnv(rootCmd)
+	documentEnv(catCmd)
+	documentEnv(parseCmd)
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(log.Writer(), "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func runDashboard(cmd *cobra.Command, args []string) {
-	// If source name is provided, we're a feeder process
-	if sourceName != "" {
-		runSourceFeeder()
+// envPrefix starts the name of every environment variable giving a flag
+// its default
+const envPrefix = "LOGFLOW_"
+
+// envName returns the environment variable a flag of cmd defaults from:
+// the flag name upper-cased with dashes as underscores after LOGFLOW_, with
+// the subcommand name in between for flags only a subcommand has. So
+// --max-panes reads LOGFLOW_MAX_PANES and cat's --format LOGFLOW_CAT_FORMAT.
+func envName(cmd *cobra.Command, flag *pflag.Flag) string {
+	name := flag.Name
+	if cmd.HasParent() && cmd.LocalNonPersistentFlags().Lookup(flag.Name) != nil {
+		name = cmd.Name() + "_" + name
+	}
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// documentEnv appends the environment variable of each of cmd's flags to
+// its help text
+func documentEnv(cmd *cobra.Command) {
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Name != "help" {
+			flag.Usage += fmt.Sprintf(" [$%s]", envName(cmd, flag))
+		}
+	})
+}
+
+// applyEnvDefaults sets every flag not given on the command line from its
+// environment variable, if that is set, so flags win over the environment
+func applyEnvDefaults(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Name == "help" {
+			return
+		}
+		name := envName(cmd, flag)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+		if setErr := flag.Value.Set(value); setErr != nil {
+			err = fmt.Errorf("invalid %s: %w", name, setErr)
+		}
+	})
+	return err
+}
+
+// applyGlobalFlags configures the packages shared by every subcommand
+func applyGlobalFlags(cmd *cobra.Command, args []string) {
+	if err := applyEnvDefaults(cmd); err != nil {
+		log.Fatalf("Failed to read flag defaults: %v", err)
+	}
+
+	logparser.SetTimeLayouts(timeLayouts)
+	loc, err := logparser.ParseTimezone(timezone)
+	if err != nil {
+		log.Fatalf("Invalid --timezone: %v", err)
+	}
+	logparser.SetDisplayLocation(loc)
+	parseLoc, err := logparser.ParseTimezone(assumeTimezone)
+	if err != nil {
+		log.Fatalf("Invalid --assume-timezone: %v", err)
+	}
+	logparser.SetParseLocation(parseLoc)
+	sources.SetMaxLineBytes(maxLineBytes)
+	sources.SetMaxMetadataBytes(maxMetaBytes)
+	if noColor {
+		logparser.SetColorMode(logparser.ColorNever)
+	}
+	setupDiagnostics(cmd)
+
+	if levelsFile != "" {
+		if err := logparser.LoadLevels(levelsFile); err != nil {
+			log.Fatalf("Failed to load --levels: %v", err)
+		}
+	} else if path, err := paths.LevelsFile(); err == nil {
+		// Levels kept in the config directory apply without the flag
+		if _, statErr := os.Stat(path); statErr == nil {
+			if err := logparser.LoadLevels(path); err != nil {
+				log.Fatalf("Failed to load levels from %s: %v", path, err)
+			}
+		}
+	}
+
+	format, err := logparser.ParseFormat(lineFormat)
+	if err != nil {
+		log.Fatalf("Invalid format: %v", err)
+	}
+	lineFormat = format
+}
+
+// parseOptions returns how much of each line --raw and --raw-level let
+// the parser interpret, and which format --format declares
+func parseOptions() logparser.ParseOptions {
+	return logparser.ParseOptions{
+		Raw:     rawLines || rawLevel,
+		NoLevel: rawLevel,
+		Format:  lineFormat,
+	}
+}
+
+// setupDiagnostics routes logflow's own messages, kept apart from the logs
+// it displays, to --log-file, nowhere with --quiet, or stderr by default.
+// Fatal errors still exit non-zero when silenced.
+func setupDiagnostics(cmd *cobra.Command) {
+	switch {
+	case logFile != "":
+		file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to open --log-file: %v\n", err)
+			os.Exit(1)
+		}
+		log.SetOutput(file)
+	case quiet:
+		log.SetOutput(io.Discard)
+	default:
 		return
 	}
 
+	// Cobra prints usage on errors straight to stderr
+	cmd.Root().SilenceUsage = true
+}
+
+func runDashboard(cmd *cobra.Command, args []string) {
+	if skipLines < 0 {
+		log.Fatalf("Invalid --skip: must not be negative")
+	}
+	if headLines < 0 {
+		log.Fatalf("Invalid --head: must not be negative")
+	}
+
 	// If container flags are provided, attach to container
 	if dockerContainer != "" {
 		runContainerFeeder("docker", dockerContainer)
@@ -62,12 +371,44 @@ func runDashboard(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if composeProject != "" {
+		runComposeFeeder(composeProject)
+		return
+	}
+
+	if fifoPath != "" {
+		runFifoFeeder(fifoPath)
+		return
+	}
+
+	// If source name is provided, or --feed asks for one to be made up,
+	// we're a feeder process
+	if sourceName != "" || feedDashboard {
+		runSourceFeeder()
+		return
+	}
+
 	// Otherwise, start the main TUI dashboard
 	startTUIDashboard()
 }
 
 func runSourceFeeder() {
-	client, err := ipc.NewClient()
+	input := io.Reader(os.Stdin)
+	if sourceName == "" {
+		// Until it first writes, the other end of the pipe may still be the
+		// shell about to start the program
+		buffered := bufio.NewReader(os.Stdin)
+		buffered.Peek(1)
+		input = buffered
+		sourceName = defaultSourceName()
+	}
+	name, err := ipc.ValidateSourceName(sourceName)
+	if err != nil {
+		log.Fatalf("Invalid --source: %v", err)
+	}
+	sourceName = name
+
+	client, err := connectFeeder()
 	if err != nil {
 		log.Fatalf("Failed to connect to logflow daemon: %v", err)
 	}
@@ -79,45 +420,206 @@ func runSourceFeeder() {
 	}
 
 	// Create pipe source and start feeding
-	pipeSource := sources.NewPipeSource(sourceName, os.Stdin)
+	pipeSource := sources.NewPipeSource(sourceName, input)
+	configurePipe(pipeSource)
+	streamFeeder(client, pipeSource)
+}
+
+// runFifoFeeder feeds the dashboard from a named pipe, named after the
+// pipe unless --source is given, until interrupted
+func runFifoFeeder(path string) {
+	if sourceName == "" {
+		sourceName = filepath.Base(path)
+	}
+	name, err := ipc.ValidateSourceName(sourceName)
+	if err != nil {
+		log.Fatalf("Invalid --source: %v", err)
+	}
+	sourceName = name
 
+	fifoSource, err := sources.NewFifoSource(sourceName, path)
+	if err != nil {
+		log.Fatalf("Invalid --fifo: %v", err)
+	}
+	configurePipe(fifoSource.PipeSource)
+
+	client, err := connectFeeder()
+	if err != nil {
+		log.Fatalf("Failed to connect to logflow daemon: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.InitSource(sourceName, fifoSource.Type()); err != nil {
+		log.Fatalf("Failed to initialize source: %v", err)
+	}
+	streamFeeder(client, fifoSource)
+}
+
+// configurePipe applies the line handling flags to a pipe source
+func configurePipe(pipeSource *sources.PipeSource) {
+	pipeSource.SetJSONInput(jsonIn)
+	pipeSource.SetParseOptions(parseOptions())
+	pipeSource.SetSkip(skipLines)
+	pipeSource.SetHead(headLines)
+}
+
+// streamFeeder streams a source over an initialized client until the
+// source ends, then reports its exit. An interrupt reports the exit early,
+// the dashboard closing stops the feeder.
+func streamFeeder(client *ipc.Client, source sources.Source) {
 	// Set up signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		<-sigChan
+		select {
+		case <-sigChan:
+			client.SendExit(source.Name())
+		case <-client.Shutdown():
+			if err := client.Err(); err != nil {
+				log.Fatalf("Source %s disconnected: %v", source.Name(), err)
+			}
+			log.Printf("Dashboard closed, stopping source %s", source.Name())
+		}
 		client.Close()
 		os.Exit(0)
 	}()
 
-	// Start streaming logs
-	if err := pipeSource.Stream(client); err != nil {
+	// Start streaming logs, this returns once the input is fully drained
+	if err := source.Stream(client); err != nil {
 		log.Fatalf("Failed to stream logs: %v", err)
 	}
+
+	// Every log line has been written, so the exit trails them
+	if err := client.SendExit(source.Name()); err != nil {
+		log.Fatalf("Failed to notify daemon of exit: %v", err)
+	}
+}
+
+func runContainerFeeder(containerType, selector string) {
+	if sourceName != "" {
+		name, err := ipc.ValidateSourceName(sourceName)
+		if err != nil {
+			log.Fatalf("Invalid --source: %v", err)
+		}
+		sourceName = name
+	}
+
+	containers, err := sources.ResolveContainers(containerType, sshTarget, selector, attachAll)
+	if err != nil {
+		log.Fatalf("Failed to resolve container: %v", err)
+	}
+
+	// Merged containers share one pane named after --source, or the
+	// selector when no name was given
+	merged := mergeContainers && len(containers) > 1
+	mergedName := sourceName
+	if merged && mergedName == "" {
+		mergedName, err = ipc.ValidateSourceName(selector)
+		if err != nil {
+			log.Fatalf("Invalid --source: %v", err)
+		}
+	}
+
+	var attachments []containerAttachment
+	for _, container := range containers {
+		attachment := containerAttachment{
+			source:    containerSourceName(container, len(containers) > 1),
+			container: container,
+		}
+		if merged {
+			attachment.source = mergedName
+			attachment.tagged = true
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	streamContainers(containerType, attachments)
 }
 
-func runContainerFeeder(containerType, containerID string) {
-	client, err := ipc.NewClient()
+func runComposeFeeder(project string) {
+	services, err := sources.ListComposeServices(project, sshTarget)
+	if err != nil {
+		log.Fatalf("Failed to list compose services: %v", err)
+	}
+
+	// One pane per service, replicas of a scaled service share it and are
+	// told apart by their container tag
+	replicas := make(map[string]int)
+	for _, service := range services {
+		replicas[service.Service]++
+	}
+
+	var attachments []containerAttachment
+	for _, service := range services {
+		name, err := ipc.ValidateSourceName(service.Service)
+		if err != nil {
+			log.Fatalf("Invalid service name: %v", err)
+		}
+		attachments = append(attachments, containerAttachment{
+			source:    name,
+			container: service.Container,
+			tagged:    replicas[service.Service] > 1,
+		})
+	}
+
+	streamContainers("docker", attachments)
+}
+
+// containerAttachment is a container to stream into the named source
+type containerAttachment struct {
+	source    string
+	container sources.Container
+	tagged    bool // Tag entries with the container, when it shares the source
+}
+
+// streamContainers streams every attachment into the dashboard until all
+// of them end. A source fed by several containers exits after the last one.
+func streamContainers(containerType string, attachments []containerAttachment) {
+	client, err := connectFeeder()
 	if err != nil {
 		log.Fatalf("Failed to connect to logflow daemon: %v", err)
 	}
 	defer client.Close()
 
-	// Initialize the source
-	if err := client.InitSource(sourceName, containerType); err != nil {
-		log.Fatalf("Failed to initialize source: %v", err)
-	}
+	// Create a container source per attachment, based on type. streams
+	// counts the containers feeding each source.
+	var containerSources []sources.Source
+	streams := make(map[string]int)
+	for _, attachment := range attachments {
+		name := attachment.source
 
-	// Create container source based on type
-	var containerSource sources.Source
-	switch containerType {
-	case "docker":
-		containerSource = sources.NewDockerSource(sourceName, containerID)
-	case "podman":
-		containerSource = sources.NewPodmanSource(sourceName, containerID)
-	default:
-		log.Fatalf("Unknown container type: %s", containerType)
+		// Initialize the source
+		if streams[name] == 0 {
+			if err := client.InitSource(name, containerType); err != nil {
+				log.Fatalf("Failed to initialize source: %v", err)
+			}
+		}
+		streams[name]++
+
+		switch containerType {
+		case "docker":
+			dockerSource := sources.NewDockerSource(name, attachment.container.ID)
+			dockerSource.SetSSHTarget(sshTarget)
+			dockerSource.SetParseOptions(parseOptions())
+			dockerSource.SetOrderWindow(orderWindow)
+			dockerSource.SetUseAPI(useDockerAPI)
+			if attachment.tagged {
+				dockerSource.SetContainerName(attachment.container.Name)
+			}
+			containerSources = append(containerSources, dockerSource)
+		case "podman":
+			podmanSource := sources.NewPodmanSource(name, attachment.container.ID)
+			podmanSource.SetSSHTarget(sshTarget)
+			podmanSource.SetParseOptions(parseOptions())
+			podmanSource.SetOrderWindow(orderWindow)
+			if attachment.tagged {
+				podmanSource.SetContainerName(attachment.container.Name)
+			}
+			containerSources = append(containerSources, podmanSource)
+		default:
+			log.Fatalf("Unknown container type: %s", containerType)
+		}
 	}
 
 	// Set up signal handling
@@ -125,40 +627,691 @@ func runContainerFeeder(containerType, containerID string) {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		<-sigChan
+		select {
+		case <-sigChan:
+		case <-client.Shutdown():
+			if err := client.Err(); err != nil {
+				log.Printf("Container sources disconnected: %v", err)
+			} else {
+				log.Printf("Dashboard closed, stopping container sources")
+			}
+		}
+		for _, src := range containerSources {
+			if closer, ok := src.(io.Closer); ok {
+				closer.Close()
+			}
+		}
 		client.Close()
 		os.Exit(0)
 	}()
 
 	// Start streaming logs
-	if err := containerSource.Stream(client); err != nil {
-		log.Fatalf("Failed to stream container logs: %v", err)
+	type streamResult struct {
+		name string
+		err  error
+	}
+	results := make(chan streamResult, len(containerSources))
+	for _, containerSource := range containerSources {
+		go func(src sources.Source) {
+			results <- streamResult{src.Name(), src.Stream(client)}
+		}(containerSource)
+	}
+
+	for range containerSources {
+		result := <-results
+		if streams[result.name]--; streams[result.name] == 0 {
+			client.SendExit(result.name)
+		}
+		if result.err != nil {
+			log.Fatalf("Failed to stream container logs: %v", result.err)
+		}
+	}
+}
+
+// containerSourceName picks the pane name for a resolved container,
+// qualifying it with the container name when several containers matched
+func containerSourceName(container sources.Container, multiple bool) string {
+	switch {
+	case sourceName == "":
+		return container.Name
+	case multiple:
+		return sourceName + "/" + container.Name
+	default:
+		return sourceName
 	}
 }
 
 func startTUIDashboard() {
+	// Without a terminal bubbletea hangs or writes escape codes into the
+	// pipe, so point at what was probably meant instead
+	if !isTerminal(os.Stdout) {
+		log.Fatalf("stdout is not a terminal, the dashboard needs one. Use 'logflow cat' to print logs non-interactively")
+	}
+
+	// Piped stdin becomes a source of its own while keys are read from the
+	// controlling terminal
+	pipedStdin := !isTerminal(os.Stdin)
+	if pipedStdin && !hasControllingTerminal() {
+		log.Fatalf("stdin is not a terminal and there is no terminal to read keys from. To feed logs to a running dashboard use --source NAME, to print them without the dashboard use 'logflow cat'")
+	}
+
+	var groups []ui.Group
+	for _, spec := range groupSpecs {
+		group, err := ui.ParseGroup(spec)
+		if err != nil {
+			log.Fatalf("Invalid --group: %v", err)
+		}
+		groups = append(groups, group)
+	}
+
+	var watched []string
+	for _, name := range watchSources {
+		name, err := ipc.ValidateSourceName(name)
+		if err != nil {
+			log.Fatalf("Invalid --watch: %v", err)
+		}
+		watched = append(watched, name)
+	}
+
+	var highlights []ui.HighlightRule
+	for _, spec := range highlightSpecs {
+		rule, err := ui.ParseHighlight(spec)
+		if err != nil {
+			log.Fatalf("Invalid --highlight: %v", err)
+		}
+		highlights = append(highlights, rule)
+	}
+
+	var errorRates []ui.ErrorRate
+	for _, spec := range errorRateSpecs {
+		rate, err := ui.ParseErrorRate(spec)
+		if err != nil {
+			log.Fatalf("Invalid --error-rate: %v", err)
+		}
+		errorRates = append(errorRates, rate)
+	}
+
+	var pauseLevel logparser.LogLevel
+	if holdLevel != "" {
+		level, err := logparser.ParseLogLevel(holdLevel)
+		if err != nil {
+			log.Fatalf("Invalid --pause-on: %v", err)
+		}
+		pauseLevel = level
+	}
+
+	align, err := ui.ParseLevelAlign(levelAlign)
+	if err != nil {
+		log.Fatalf("Invalid --level-align: %v", err)
+	}
+	style, err := ui.ParseLevelStyle(levelStyle)
+	if err != nil {
+		log.Fatalf("Invalid --level-style: %v", err)
+	}
+
+	duplicatePolicy, err := ipc.ParseDuplicatePolicy(duplicateNames)
+	if err != nil {
+		log.Fatalf("Invalid --duplicate-sources: %v", err)
+	}
+
+	if bufferSize < 1 {
+		log.Fatalf("Invalid --buffer-size: must be at least 1")
+	}
+	checkMemory()
+
+	// Serve TLS when given a certificate
+	var serverTLS *tls.Config
+	if tlsCert != "" || tlsKey != "" {
+		if tlsCert == "" || tlsKey == "" {
+			log.Fatalf("--tls-cert and --tls-key must be given together")
+		}
+		serverTLS, err = ipc.LoadServerTLS(tlsCert, tlsKey)
+		if err != nil {
+			log.Fatalf("Invalid TLS settings: %v", err)
+		}
+	}
+
 	// Start the IPC server
-	server, err := ipc.NewServer()
+	server, err := ipc.NewTLSServerAt(socketPath, serverTLS)
 	if err != nil {
 		log.Fatalf("Failed to start IPC server: %v", err)
 	}
+	server.SetToken(ipcToken)
+	server.SetDuplicatePolicy(duplicatePolicy)
+
+	if teeFile != "" {
+		fileSink, err := sinks.NewFileSink(teeFile, teeFormat)
+		if err != nil {
+			server.Close()
+			log.Fatalf("Failed to open --tee file: %v", err)
+		}
+		server.AddSink(fileSink)
+	}
+
+	for _, spec := range onMatch {
+		hook, err := sinks.NewHookSink(spec)
+		if err != nil {
+			server.Close()
+			log.Fatalf("Invalid --on-match: %v", err)
+		}
+		server.AddSink(hook)
+	}
+
+	if webAddr != "" {
+		webServer, err := web.NewServer(webAddr, bufferSize)
+		if err != nil {
+			server.Close()
+			log.Fatalf("Failed to start --web server: %v", err)
+		}
+		server.AddSink(webServer)
+	}
 
 	// Start the TUI application
 	app := ui.NewApp(server)
+	app.SetTickRate(tickRate)
+	app.SetSingle(singleView)
+	app.SetBufferSize(bufferSize)
+	app.SetExitOnIdle(exitOnIdle)
+	app.SetGroups(groups)
+	app.SetHoldOnLevel(pauseLevel, holdDuration)
+	app.SetShowMetrics(debugOverlay)
+	app.SetLevelAlign(align)
+	app.SetLevelStyle(style)
+	app.SetSpillDir(spillDir)
+	app.SetFields(showFields)
+	app.SetTraceKeys(traceKeys)
+	app.SetHighlights(highlights)
+	app.SetErrorRates(errorRates)
+	app.SetLastWindow(lastWindow)
+	app.SetMaxPanes(maxPanes, dropHidden)
+	app.SetWatched(watched)
+	if path, err := paths.PresetsFile(); err == nil {
+		if err := app.SetPresetsFile(path); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	if presetName != "" {
+		if err := app.ApplyPreset(presetName); err != nil {
+			log.Fatalf("Invalid --preset: %v", err)
+		}
+	}
+	if saveHistory {
+		path, err := paths.SearchHistoryFile()
+		if err != nil {
+			log.Fatalf("Failed to locate search history: %v", err)
+		}
+		app.SetSearchHistoryFile(path)
+	}
+
+	if pipedStdin {
+		app.SetInputTTY(true)
+		go feedStdin(server)
+	}
 
 	// Set up signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// Quit the way the quit key does, so the exit below still runs and
+	// --strict still reports. A second signal kills the process outright.
 	go func() {
 		<-sigChan
+		signal.Stop(sigChan)
 		app.Quit()
-		server.Close()
-		os.Exit(0)
 	}()
 
 	// Run the TUI
 	if err := app.Run(); err != nil {
+		server.Close()
 		log.Fatalf("Failed to run TUI: %v", err)
 	}
+
+	// Notifies connected feeders and removes the socket
+	server.Close()
+
+	if strict && !sessionClean(app, server) {
+		os.Exit(exitStrict)
+	}
+}
+
+// exitStrict is the exit status of a --strict session that had problems
+const exitStrict = 2
+
+// sessionClean reports whether the dashboard session ended without source
+// errors or dropped entries, logging whatever went wrong
+func sessionClean(app *ui.App, server *ipc.Server) bool {
+	clean := true
+	for _, err := range app.SourceErrors() {
+		log.Printf("Source error: %s", err)
+		clean = false
+	}
+	if dropped := server.Stats().Dropped; dropped > 0 {
+		log.Printf("Dropped %d entries the dashboard could not keep up with", dropped)
+		clean = false
+	}
+	return clean
+}
+
+// estimatedEntryBytes is a rough in-memory size of an average entry: its
+// content, raw line, metadata and bookkeeping
+const estimatedEntryBytes = 1024
+
+// estimatedCopyBytes is the size of an entry held a second time, by the
+// --web buffers: the copies share their text and metadata with the
+// dashboard's, so only the entry itself is new
+const estimatedCopyBytes = 192
+
+// estimatedPanes is how many sources an uncapped dashboard is assumed to
+// show when estimating its memory use
+const estimatedPanes = 16
+
+// checkMemory refuses to start, unless --force is given, when the buffers
+// could take more than half of the memory the system has available:
+// --buffer-size entries in each of --max-panes panes (or a typical count
+// if uncapped), plus the copies --web keeps of every source, which
+// --max-panes does not cap. The estimate is skipped where available memory
+// is unknown.
+func checkMemory() {
+	available, ok := availableMemory()
+	if !ok {
+		return
+	}
+
+	panes := estimatedPanes
+	if maxPanes > 0 {
+		panes = maxPanes
+	}
+	estimate := uint64(bufferSize) * uint64(panes) * estimatedEntryBytes
+	what := fmt.Sprintf("--buffer-size %d across %d panes", bufferSize, panes)
+	if webAddr != "" {
+		estimate += uint64(bufferSize) * uint64(max(panes, estimatedPanes)) * estimatedCopyBytes
+		what += " and the --web buffers"
+	}
+	if estimate <= available/2 {
+		return
+	}
+
+	msg := fmt.Sprintf("%s could use about %d MB, more than half of the %d MB available",
+		what, estimate>>20, available>>20)
+	if !force {
+		log.Fatalf("%s. Lower --buffer-size or --max-panes, or pass --force to start anyway", msg)
+	}
+	log.Printf("Warning: %s", msg)
+}
+
+// availableMemory reads how much memory the system can give new
+// allocations from /proc/meminfo, reporting false where that isn't
+// available
+func availableMemory() (uint64, bool) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// MemAvailable:   12345678 kB
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemAvailable:" {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return kb << 10, true
+	}
+	return 0, false
+}
+
+// stdinSourceName names the source created from stdin piped into the
+// dashboard itself
+const stdinSourceName = "stdin"
+
+// feedStdin streams stdin into the dashboard's own server, as if a
+// separate feeder had been started with --source stdin
+func feedStdin(server *ipc.Server) {
+	client, err := server.Connect()
+	if err != nil {
+		log.Printf("Failed to connect stdin source: %v", err)
+		return
+	}
+	defer client.Close()
+
+	if err := client.InitSource(stdinSourceName, "pipe"); err != nil {
+		log.Printf("Failed to initialize stdin source: %v", err)
+		return
+	}
+
+	pipeSource := sources.NewPipeSource(stdinSourceName, os.Stdin)
+	configurePipe(pipeSource)
+	if err := pipeSource.Stream(client); err != nil {
+		log.Printf("Failed to stream stdin: %v", err)
+	}
+	client.SendExit(stdinSourceName)
+}
+
+// connectFeeder connects a feeder to the dashboard at --socket, over TLS
+// if --tls or --ca was given, presenting --token
+func connectFeeder() (*ipc.Client, error) {
+	var config *tls.Config
+	if useTLS || caFile != "" {
+		var err error
+		if config, err = ipc.LoadClientTLS(caFile); err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := ipc.NewTLSClientAt(socketPath, config)
+	if err != nil {
+		return nil, err
+	}
+	client.SetToken(ipcToken)
+	return client, nil
+}
+
+// defaultSourceName names a source fed without --source after the program
+// writing to stdin, so 'python app.py | logflow' shows up as "python".
+// Without a program to name it after, the source is called "stdin".
+func defaultSourceName() string {
+	if name := pipeWriterName(); name != "" {
+		return name
+	}
+	return stdinSourceName
+}
+
+// pipeWriterName returns the command name of the process writing into the
+// pipe on stdin, found by looking for the pipe among every process's open
+// files in /proc. logflow's parent is usually the shell that set up the
+// pipeline rather than the program upstream, so the pipe is followed
+// instead. When the writer's children hold the pipe too, like the commands
+// run by make, the outermost process is named. Returns "" where /proc is
+// not available or the writer can't be seen.
+func pipeWriterName() string {
+	pipe, err := os.Readlink("/proc/self/fd/0")
+	if err != nil || !strings.HasPrefix(pipe, "pipe:") {
+		return ""
+	}
+
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return ""
+	}
+
+	self := os.Getpid()
+	writers := make(map[int]bool)
+	for _, proc := range procs {
+		pid, err := strconv.Atoi(proc.Name())
+		if err != nil || pid == self {
+			continue
+		}
+		fds, err := os.ReadDir(filepath.Join("/proc", proc.Name(), "fd"))
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join("/proc", proc.Name(), "fd", fd.Name()))
+			if err == nil && target == pipe && writeEnd(pid, fd.Name()) {
+				writers[pid] = true
+				break
+			}
+		}
+	}
+
+	writer := 0
+	for pid := range writers {
+		if writers[parentPID(pid)] {
+			continue
+		}
+		if writer == 0 || pid < writer {
+			writer = pid
+		}
+	}
+	if writer == 0 {
+		return ""
+	}
+
+	comm, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(writer), "comm"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(comm))
+}
+
+// writeEnd reports whether a process opened a file descriptor for writing,
+// telling the writers of a pipe from other readers
+func writeEnd(pid int, fd string) bool {
+	info, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "fdinfo", fd))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(info), "\n") {
+		if value, ok := strings.CutPrefix(line, "flags:"); ok {
+			flags, err := strconv.ParseUint(strings.TrimSpace(value), 8, 64)
+			return err == nil && flags&uint64(os.O_WRONLY|os.O_RDWR) != 0
+		}
+	}
+	return false
+}
+
+// parentPID returns the parent of a process from /proc, 0 if unknown
+func parentPID(pid int) int {
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0
+	}
+	// The command name in parentheses may contain spaces, the state and
+	// parent follow the last closing parenthesis
+	fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+	if len(fields) < 2 {
+		return 0
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	return ppid
+}
+
+// hasControllingTerminal reports whether a terminal can be opened for
+// keyboard input even though stdin is redirected
+func hasControllingTerminal() bool {
+	path := "/dev/tty"
+	if runtime.GOOS == "windows" {
+		path = "CONIN$"
+	}
+
+	tty, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	tty.Close()
+	return true
+}
+
+func runCat(cmd *cobra.Command, args []string) error {
+	minLevel := logparser.LowestLevel()
+	if catFilter != "" {
+		level, err := logparser.ParseLogLevel(catFilter)
+		if err != nil {
+			return err
+		}
+		minLevel = level
+	}
+
+	format := catFormat
+	if format == "auto" {
+		format = "text"
+		if logparser.ColorEnabled(isTerminal(os.Stdout)) {
+			format = "color"
+		}
+	}
+
+	var render func(entry *logparser.LogEntry) (string, error)
+	switch format {
+	case "color":
+		render = func(entry *logparser.LogEntry) (string, error) { return entry.ColorString(), nil }
+	case "text":
+		render = func(entry *logparser.LogEntry) (string, error) { return entry.String(), nil }
+	case "json":
+		render = func(entry *logparser.LogEntry) (string, error) {
+			data, err := json.Marshal(entry)
+			return string(data), err
+		}
+	default:
+		return fmt.Errorf("unknown format %q", catFormat)
+	}
+
+	input := io.Reader(os.Stdin)
+	name := "stdin"
+	if catFile != "" {
+		file, err := os.Open(catFile)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		defer file.Close()
+		input = file
+		name = filepath.Base(catFile)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	opts := parseOptions()
+
+	return sources.ReadLines(input, func(line string) error {
+		entry := sources.NewLineEntry(name, line, opts)
+		if !entry.Level.AtLeast(minLevel) {
+			return nil
+		}
+
+		text, err := render(entry)
+		if err != nil {
+			return fmt.Errorf("failed to format entry: %w", err)
+		}
+		_, err = fmt.Fprintln(out, text)
+		return err
+	})
+}
+
+// parsedLine is what the parser made of one line, as parse prints it
+type parsedLine struct {
+	Line      int                    `json:"line"`
+	Raw       string                 `json:"raw"`
+	Level     logparser.LogLevel     `json:"level"`
+	Timestamp *time.Time             `json:"timestamp"` // Nil when the line has none
+	Content   string                 `json:"content"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// parseTimeLayout shows parsed timestamps in full, with their zone
+const parseTimeLayout = "2006-01-02 15:04:05.000 MST"
+
+// parseFlushRows is how many table rows parse aligns and prints at a time,
+// so a large file does not pile up in the table before anything shows
+const parseFlushRows = 100
+
+func runParse(cmd *cobra.Command, args []string) error {
+	if parseOutput != "table" && parseOutput != "json" {
+		return fmt.Errorf("unknown output %q, want table or json", parseOutput)
+	}
+
+	input := os.Stdin
+	name := "stdin"
+	if len(args) == 1 {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		defer file.Close()
+		input = file
+		name = filepath.Base(args[0])
+	}
+
+	// A pipe such as tail -f may pause for good, so what has been read is
+	// printed whenever it runs dry rather than held for more rows
+	streaming := true
+	if info, err := input.Stat(); err == nil && info.Mode().IsRegular() {
+		streaming = false
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	table := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	if parseOutput == "table" {
+		fmt.Fprintln(table, "LINE\tLEVEL\tTIMESTAMP\tCONTENT\tFIELDS")
+		defer table.Flush()
+	}
+
+	opts := parseOptions()
+
+	// Blank lines are skipped like sources skip them, but still counted so
+	// line numbers match the file
+	reader := bufio.NewReader(input)
+	rows := 0
+	for number := 1; ; number++ {
+		line, readErr := reader.ReadString('\n')
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if line != "" {
+			if err := printParsed(out, table, name, number, line, opts); err != nil {
+				return err
+			}
+			rows++
+		}
+		if (line != "" && rows%parseFlushRows == 0) || (streaming && reader.Buffered() == 0) {
+			if parseOutput == "table" {
+				table.Flush()
+			}
+			if err := out.Flush(); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// printParsed parses a line and prints the result as a table row, or a
+// JSON object for parse -o json
+func printParsed(out, table io.Writer, name string, number int, line string, opts logparser.ParseOptions) error {
+	entry := sources.NewLineEntry(name, line, opts)
+	parsed := parsedLine{
+		Line:     number,
+		Raw:      entry.Raw,
+		Level:    entry.Level,
+		Content:  entry.Content,
+		Metadata: entry.Metadata,
+	}
+	if !entry.SyntheticTime {
+		parsed.Timestamp = &entry.Timestamp
+	}
+
+	if parseOutput == "json" {
+		data, err := json.Marshal(parsed)
+		if err != nil {
+			return fmt.Errorf("failed to format line %d: %w", number, err)
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	}
+
+	timestamp := "-"
+	if parsed.Timestamp != nil {
+		timestamp = logparser.DisplayTime(*parsed.Timestamp).Format(parseTimeLayout)
+	}
+	_, err := fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\n", parsed.Line, parsed.Level, timestamp,
+		tableCell(parsed.Content), tableCell(logparser.FormatMetadata(parsed.Metadata, logparser.DefaultMetadataDepth)))
+	return err
+}
+
+// tableCell keeps a value on one table row: tabs would start a new column
+func tableCell(s string) string {
+	return strings.ReplaceAll(s, "\t", " ")
+}
+
+// isTerminal reports whether f is attached to a terminal
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
 }
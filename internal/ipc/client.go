@@ -2,28 +2,127 @@
 package ipc
 
 import (
+	"bufio"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net"
+	"sync"
+	"sync/atomic"
 )
 
 // Client handles IPC communication to the server
 type Client struct {
-	conn net.Conn
+	conn  net.Conn
+	token string // Sent with every source_init
+
+	// serverErr is the last error the server reported before hanging up
+	serverErr atomic.Value
+
+	// shutdown is closed when the server announces it is shutting down or
+	// the connection drops without the client closing it
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+	closing      atomic.Bool
 }
 
-// NewClient creates a new IPC client
+// NewClient creates a new IPC client connected to the default socket path
 func NewClient() (*Client, error) {
-	conn, err := net.Dial("unix", SocketPath)
+	return NewClientAt(SocketPath)
+}
+
+// NewClientAt creates a new IPC client connected to the given socket path
+func NewClientAt(path string) (*Client, error) {
+	return NewTLSClientAt(path, nil)
+}
+
+// NewTLSClientAt creates a new IPC client connected over TLS with config
+// to the host:port path. A nil config connects in plain text.
+func NewTLSClientAt(path string, config *tls.Config) (*Client, error) {
+	if config != nil && !isTCPAddress(path) {
+		return nil, fmt.Errorf("TLS needs a host:port address, not %s", path)
+	}
+
+	conn, err := dial(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to logflow daemon: %w", err)
 	}
+	if config != nil {
+		conn, err = handshake(conn, path, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client := &Client{
+		conn:     conn,
+		shutdown: make(chan struct{}),
+	}
+	go client.readLoop()
+	return client, nil
+}
 
-	return &Client{conn: conn}, nil
+// handshake runs the TLS handshake over conn, closing it on failure so a
+// bad certificate is reported up front rather than on the first write
+func handshake(conn net.Conn, path string, config *tls.Config) (net.Conn, error) {
+	if config.ServerName == "" && !config.InsecureSkipVerify {
+		config = config.Clone()
+		config.ServerName, _, _ = net.SplitHostPort(path)
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("TLS handshake with logflow daemon failed: %w", err)
+	}
+	return tlsConn, nil
+}
+
+// Shutdown returns a channel that is closed once the server shuts down, so
+// feeders can stop before their next write fails
+func (c *Client) Shutdown() <-chan struct{} {
+	return c.shutdown
+}
+
+// readLoop handles messages sent by the server
+func (c *Client) readLoop() {
+	scanner := bufio.NewScanner(c.conn)
+	for scanner.Scan() {
+		var msg IPCMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		switch msg.Type {
+		case MessageTypeServerShutdown:
+			c.shutdownOnce.Do(func() { close(c.shutdown) })
+			return
+		case MessageTypeError:
+			c.serverErr.Store(fmt.Errorf("dashboard: %s", msg.Error))
+		}
+	}
+
+	// The server went away without saying so
+	if !c.closing.Load() {
+		c.shutdownOnce.Do(func() { close(c.shutdown) })
+	}
+}
+
+// SetToken sets the shared secret sent when initializing sources, needed by
+// a dashboard started with a token
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// Err returns the error the server reported before disconnecting, if any,
+// such as a rejected token
+func (c *Client) Err() error {
+	err, _ := c.serverErr.Load().(error)
+	return err
 }
 
 // Close closes the client connection
 func (c *Client) Close() error {
+	c.closing.Store(true)
 	if c.conn != nil {
 		return c.conn.Close()
 	}
@@ -44,7 +143,12 @@ func (c *Client) SendMessage(msg *IPCMessage) error {
 
 // InitSource initializes a source with the server
 func (c *Client) InitSource(name, sourceType string) error {
+	if _, err := ValidateSourceName(name); err != nil {
+		return err
+	}
+
 	msg := NewSourceInitMessage(name, sourceType)
+	msg.SourceInfo.Token = c.token
 	return c.SendMessage(msg)
 }
 
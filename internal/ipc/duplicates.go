@@ -0,0 +1,127 @@
+// internal/ipc/duplicates.go
+package ipc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+)
+
+// InstanceField is the metadata key DuplicateTag stores the instance
+// number of an entry's feeder under
+const InstanceField = "instance"
+
+// DuplicatePolicy decides what happens to a source connecting under a name
+// that another connected feeder already uses, as when a scaled service
+// runs several feeders with the same --source
+type DuplicatePolicy int
+
+const (
+	DuplicateSuffix DuplicatePolicy = iota // Shown as a source of its own: name#2, name#3...
+	DuplicateTag                           // Same source, entries tagged with their instance
+	DuplicateMerge                         // Same source, entries left as they are
+)
+
+// ParseDuplicatePolicy converts "suffix", "tag" or "merge" to a
+// DuplicatePolicy
+func ParseDuplicatePolicy(name string) (DuplicatePolicy, error) {
+	switch strings.ToLower(name) {
+	case "suffix":
+		return DuplicateSuffix, nil
+	case "tag":
+		return DuplicateTag, nil
+	case "merge":
+		return DuplicateMerge, nil
+	}
+	return 0, fmt.Errorf("unknown duplicate source policy %q, want suffix, tag or merge", name)
+}
+
+// SetDuplicatePolicy sets how sources sharing a name are told apart.
+// Call it before feeders connect.
+func (s *Server) SetDuplicatePolicy(policy DuplicatePolicy) {
+	s.duplicates = policy
+}
+
+// instanceName names the n-th feeder of a source, the first keeping the
+// name itself
+func instanceName(name string, n int) string {
+	if n == 1 {
+		return name
+	}
+	return name + "#" + strconv.Itoa(n)
+}
+
+// claimInstance takes the lowest instance number of name no connected
+// feeder holds
+func (s *Server) claimInstance(name string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	held := s.instances[name]
+	if held == nil {
+		held = make(map[int]bool)
+		s.instances[name] = held
+	}
+	n := 1
+	for held[n] {
+		n++
+	}
+	held[n] = true
+	return n
+}
+
+// releaseInstance gives back an instance number once its feeder is gone
+func (s *Server) releaseInstance(name string, n int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.instances[name], n)
+	if len(s.instances[name]) == 0 {
+		delete(s.instances, name)
+	}
+}
+
+// Connected reports whether a feeder is connected under source, the name
+// its entries arrive with, such as "api#2" for a second "api" given a
+// suffix
+func (s *Server) Connected(source string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.duplicates == DuplicateSuffix {
+		if i := strings.LastIndex(source, "#"); i > 0 {
+			if n, err := strconv.Atoi(source[i+1:]); err == nil && n > 1 {
+				return s.instances[source[:i]][n]
+			}
+		}
+		return s.instances[source][1]
+	}
+	return len(s.instances[source]) > 0
+}
+
+// sharedName reports whether more than one connected feeder uses name
+func (s *Server) sharedName(name string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.instances[name]) > 1
+}
+
+// applyInstance renames or tags an entry from the given instance of its
+// source, following the duplicate policy
+func (s *Server) applyInstance(entry *log.LogEntry, n int) {
+	switch s.duplicates {
+	case DuplicateSuffix:
+		entry.Source = instanceName(entry.Source, n)
+	case DuplicateTag:
+		// Only once the name is shared, a lone feeder needs no label
+		if !s.sharedName(entry.Source) {
+			return
+		}
+		if entry.Metadata == nil {
+			entry.Metadata = make(map[string]interface{})
+		}
+		entry.Metadata[InstanceField] = n
+	}
+}
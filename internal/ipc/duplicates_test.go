@@ -0,0 +1,50 @@
+// internal/ipc/duplicates_test.go
+package ipc
+
+import "testing"
+
+func TestConnected(t *testing.T) {
+	tests := []struct {
+		policy    DuplicatePolicy
+		connected []string // Shown names with a feeder while both are up
+	}{
+		{DuplicateSuffix, []string{"api", "api#2"}},
+		{DuplicateTag, []string{"api"}},
+		{DuplicateMerge, []string{"api"}},
+	}
+
+	for _, tt := range tests {
+		server := startServer(t)
+		server.SetDuplicatePolicy(tt.policy)
+
+		first := connect(t, server)
+		second := connect(t, server)
+		for _, client := range []*Client{first, second} {
+			if err := client.InitSource("api", "pipe"); err != nil {
+				t.Fatal(err)
+			}
+			sendLines(t, client, "api", 1, 1)
+		}
+		receive(t, server, 2)
+
+		for _, name := range tt.connected {
+			if !server.Connected(name) {
+				t.Errorf("policy %d: %s not connected", tt.policy, name)
+			}
+		}
+		for _, name := range []string{"api#3", "web", "api#x"} {
+			if server.Connected(name) {
+				t.Errorf("policy %d: %s connected", tt.policy, name)
+			}
+		}
+
+		first.Close()
+		second.Close()
+		waitFor(t, "both feeders to be gone", func() bool {
+			return server.Stats().Clients == 0
+		})
+		if server.Connected("api") || server.Connected("api#2") {
+			t.Errorf("policy %d: api still connected after its feeders left", tt.policy)
+		}
+	}
+}
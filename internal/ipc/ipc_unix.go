@@ -0,0 +1,67 @@
+// internal/ipc/ipc_unix.go
+//go:build !windows
+
+package ipc
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SocketPath is the default address the dashboard listens on: a Unix
+// domain socket
+const SocketPath = "/tmp/logflow.sock"
+
+// isTCPAddress reports whether path is a host:port to reach over TCP, for
+// feeders on other machines, rather than a Unix socket path
+func isTCPAddress(path string) bool {
+	if strings.Contains(path, "/") {
+		return false
+	}
+	_, _, err := net.SplitHostPort(path)
+	return err == nil
+}
+
+// listen creates the Unix socket at path, replacing a stale one, or
+// listens on TCP if path is a host:port
+func listen(path string) (net.Listener, error) {
+	if isTCPAddress(path) {
+		listener, err := net.Listen("tcp", path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+		}
+		return listener, nil
+	}
+
+	// Remove existing socket file
+	os.Remove(path)
+
+	// Create socket directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create unix socket: %w", err)
+	}
+	return listener, nil
+}
+
+// dial connects to the Unix socket at path, or over TCP to a host:port
+func dial(path string) (net.Conn, error) {
+	if isTCPAddress(path) {
+		return net.Dial("tcp", path)
+	}
+	return net.Dial("unix", path)
+}
+
+// cleanup removes the socket file once the server is closed
+func cleanup(path string) {
+	if !isTCPAddress(path) {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,37 @@
+// internal/ipc/ipc_windows.go
+//go:build windows
+
+package ipc
+
+import (
+	"fmt"
+	"net"
+)
+
+// SocketPath is the default address the dashboard listens on. Unix sockets
+// are not available on every supported Windows release, so the dashboard
+// listens on a loopback TCP port instead.
+const SocketPath = "127.0.0.1:47474"
+
+// isTCPAddress reports whether path is reached over TCP, always the case
+// on Windows
+func isTCPAddress(path string) bool {
+	return true
+}
+
+// listen opens the loopback TCP listener at addr
+func listen(addr string) (net.Listener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return listener, nil
+}
+
+// dial connects to the dashboard at addr
+func dial(addr string) (net.Conn, error) {
+	return net.Dial("tcp", addr)
+}
+
+// cleanup is a no-op, a TCP listener leaves nothing behind
+func cleanup(addr string) {}
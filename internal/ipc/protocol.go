@@ -3,9 +3,18 @@ package ipc
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
 )
 
+// MaxSourceNameLength bounds source names so they fit pane headers
+const MaxSourceNameLength = 64
+
 // MessageType defines the type of IPC message
 type MessageType string
 
@@ -15,6 +24,14 @@ const (
 	MessageTypeSourceExit MessageType = "source_exit"
 	MessageTypePing       MessageType = "ping"
 	MessageTypePong       MessageType = "pong"
+
+	// MessageTypeServerShutdown is sent by the dashboard to every
+	// connected feeder when it closes
+	MessageTypeServerShutdown MessageType = "server_shutdown"
+
+	// MessageTypeError is sent by the dashboard before it drops a feeder,
+	// such as one that failed to authenticate
+	MessageTypeError MessageType = "error"
 )
 
 // LogLevel represents the severity level of a log entry
@@ -29,18 +46,39 @@ const (
 
 // LogEntry represents a single log entry
 type LogEntry struct {
-	Timestamp time.Time              `json:"timestamp"`
-	Source    string                 `json:"source"`
-	Level     LogLevel               `json:"level"`
-	Content   string                 `json:"content"`
-	Raw       string                 `json:"raw"`
-	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Seq           uint64                 `json:"seq,omitempty"` // Assigned by the receiving buffer
+	Timestamp     time.Time              `json:"timestamp"`
+	IngestTime    time.Time              `json:"ingest_time"`
+	SyntheticTime bool                   `json:"synthetic_time,omitempty"`
+	Source        string                 `json:"source"`
+	Level         LogLevel               `json:"level"`
+	Content       string                 `json:"content"`
+	Raw           string                 `json:"raw"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ToLogEntry converts the wire entry into the internal log entry type
+func (e *LogEntry) ToLogEntry() log.LogEntry {
+	return log.LogEntry{
+		Seq:           e.Seq,
+		Timestamp:     e.Timestamp,
+		IngestTime:    e.IngestTime,
+		SyntheticTime: e.SyntheticTime,
+		Source:        e.Source,
+		Level:         log.LogLevel(e.Level),
+		Content:       e.Content,
+		Raw:           e.Raw,
+		Metadata:      e.Metadata,
+	}
 }
 
 // SourceInfo contains information about a log source
 type SourceInfo struct {
 	Name string `json:"name"`
 	Type string `json:"type"` // "pipe", "docker", "podman"
+
+	// Token is the shared secret of a dashboard started with one
+	Token string `json:"token,omitempty"`
 }
 
 // IPCMessage represents a message sent over the IPC channel
@@ -89,3 +127,42 @@ func NewSourceExitMessage(name string) *IPCMessage {
 		},
 	}
 }
+
+// NewServerShutdownMessage creates a message announcing that the server is
+// shutting down
+func NewServerShutdownMessage() *IPCMessage {
+	return &IPCMessage{
+		Type: MessageTypeServerShutdown,
+	}
+}
+
+// NewErrorMessage creates a message reporting an error to a feeder
+func NewErrorMessage(text string) *IPCMessage {
+	return &IPCMessage{
+		Type:  MessageTypeError,
+		Error: text,
+	}
+}
+
+// ValidateSourceName trims surrounding whitespace from a source name and
+// rejects names that are empty, too long or contain control characters
+func ValidateSourceName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return "", fmt.Errorf("source name must not be empty")
+	}
+	if !utf8.ValidString(name) {
+		return "", fmt.Errorf("source name %q is not valid UTF-8", name)
+	}
+	if utf8.RuneCountInString(name) > MaxSourceNameLength {
+		return "", fmt.Errorf("source name %q is longer than %d characters", name, MaxSourceNameLength)
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return "", fmt.Errorf("source name %q contains control characters", name)
+		}
+	}
+
+	return name, nil
+}
@@ -3,76 +3,279 @@ package ipc
 
 import (
 	"bufio"
+	"crypto/subtle"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	stdlog "log"
 	"net"
-	"os"
-	"path/filepath"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+	"github.com/Yriskit-ai/logflow/internal/sinks"
 )
 
-const SocketPath = "/tmp/logflow.sock"
+// maxMessageSize bounds a single newline-delimited IPC message, large
+// enough that long log lines are not cut off mid-stream
+const maxMessageSize = 16 * 1024 * 1024
+
+// maxParseErrors is how many malformed messages in a row a client may send
+// before it is dropped
+const maxParseErrors = 10
+
+// shutdownWriteTimeout bounds how long Close waits on a feeder that does
+// not read its shutdown notice
+const shutdownWriteTimeout = time.Second
 
 // Server handles IPC communication from source processes
 type Server struct {
+	path     string
 	listener net.Listener
 	clients  map[net.Conn]*Client
 	mutex    sync.RWMutex
-	logChan  chan *LogEntry
+	logSink  *sinks.ChannelSink
+	sinks    []sinks.Sink
+	subs     map[string][]sinks.Sink // Sinks taking a single source's entries
 	quit     chan struct{}
+	received atomic.Uint64
+	bytes    atomic.Uint64
+	rejected atomic.Uint64
+	badLines atomic.Uint64 // Malformed messages skipped mid-stream
+	closed   sync.Once
+
+	// handlers tracks the accept loop and every connection handler, so
+	// Close can wait for entries in flight before closing the sinks
+	handlers sync.WaitGroup
+
+	// token, when set, must be sent in a source_init before anything else
+	// on a connection is accepted
+	token string
+
+	tlsConfig *tls.Config // Set when the listener serves TLS
+
+	// instances holds, per source name, the instance numbers of the
+	// connected feeders declaring it, told apart as duplicates says
+	instances  map[string]map[int]bool
+	duplicates DuplicatePolicy
+}
+
+// Stats is a snapshot of the server's ingest counters
+type Stats struct {
+	Received  uint64 // Entries received from sources
+	Bytes     uint64 // Bytes of log lines received
+	Dropped   uint64 // Entries dropped because the dashboard fell behind
+	Rejected  uint64 // Clients dropped for breaking the protocol
+	Malformed uint64 // Unparsable messages skipped from accepted clients
+	Clients   int    // Connected sources
 }
 
-// NewServer creates a new IPC server
+// NewServer creates a new IPC server on the default socket path
 func NewServer() (*Server, error) {
-	// Remove existing socket file
-	os.Remove(SocketPath)
+	return NewServerAt(SocketPath)
+}
+
+// NewServerAt creates a new IPC server listening on the given socket path
+// (a loopback host:port on Windows)
+func NewServerAt(path string) (*Server, error) {
+	return NewTLSServerAt(path, nil)
+}
 
-	// Create socket directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(SocketPath), 0755); err != nil {
-		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+// NewTLSServerAt creates a new IPC server that serves TLS with config on
+// the host:port path, so feeders on other machines don't ship logs in
+// plain text. A nil config serves plain connections, and Unix socket paths
+// only accept a nil config.
+func NewTLSServerAt(path string, config *tls.Config) (*Server, error) {
+	if config != nil && !isTCPAddress(path) {
+		return nil, fmt.Errorf("TLS needs a host:port address, not %s", path)
 	}
 
-	listener, err := net.Listen("unix", SocketPath)
+	listener, err := listen(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create unix socket: %w", err)
+		return nil, err
+	}
+	if config != nil {
+		listener = tls.NewListener(listener, config)
 	}
 
+	logSink := sinks.NewChannelSink(1000) // Buffered channel
 	server := &Server{
+		path:     path,
 		listener: listener,
 		clients:  make(map[net.Conn]*Client),
-		logChan:  make(chan *LogEntry, 1000), // Buffered channel
+		logSink:  logSink,
+		sinks:    []sinks.Sink{logSink},
+		subs:     make(map[string][]sinks.Sink),
 		quit:     make(chan struct{}),
+
+		tlsConfig: config,
+		instances: make(map[string]map[int]bool),
 	}
 
+	server.handlers.Add(1)
 	go server.acceptConnections()
 	return server, nil
 }
 
-// LogChannel returns the channel for receiving log entries
-func (s *Server) LogChannel() <-chan *LogEntry {
-	return s.logChan
+// SetToken requires feeders to present token in their source_init message.
+// Connections that send anything else first, or a wrong token, are
+// dropped. An empty token accepts every connection. This is a basic guard
+// against stray writers on a TCP port, not encryption: the token crosses
+// the wire in plain text.
+func (s *Server) SetToken(token string) {
+	s.token = token
 }
 
-// Close shuts down the server
-func (s *Server) Close() error {
-	close(s.quit)
+// Token returns the shared secret feeders must present, empty if none
+func (s *Server) Token() string {
+	return s.token
+}
+
+// Connect opens a client connection to the server from the same process,
+// with the server's TLS certificate and token
+func (s *Server) Connect() (*Client, error) {
+	client, err := NewTLSClientAt(s.path, pinnedTLS(s.tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	client.SetToken(s.token)
+	return client, nil
+}
+
+// Path returns the socket path the server listens on
+func (s *Server) Path() string {
+	return s.path
+}
 
+// LogChannel returns the channel for receiving log entries, fed by the
+// server's built-in channel sink
+func (s *Server) LogChannel() <-chan log.LogEntry {
+	return s.logSink.Entries()
+}
+
+// AddSink registers an additional sink that receives every log entry
+func (s *Server) AddSink(sink sinks.Sink) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sinks = append(s.sinks, sink)
+}
+
+// Subscribe registers a sink that receives only the entries of source,
+// until Unsubscribe. Unlike sinks added with AddSink, the caller closes it.
+func (s *Server) Subscribe(source string, sink sinks.Sink) {
 	s.mutex.Lock()
-	for conn := range s.clients {
-		conn.Close()
+	defer s.mutex.Unlock()
+	s.subs[source] = append(s.subs[source], sink)
+}
+
+// Unsubscribe stops sending source's entries to sink
+func (s *Server) Unsubscribe(source string, sink sinks.Sink) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	subs := s.subs[source]
+	for i, sub := range subs {
+		if sub == sink {
+			subs = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
 	}
-	s.mutex.Unlock()
+	if len(subs) == 0 {
+		delete(s.subs, source)
+	} else {
+		s.subs[source] = subs
+	}
+}
+
+// Stats returns the current ingest counters
+func (s *Server) Stats() Stats {
+	s.mutex.RLock()
+	clients := len(s.clients)
+	s.mutex.RUnlock()
+
+	return Stats{
+		Received:  s.received.Load(),
+		Bytes:     s.bytes.Load(),
+		Dropped:   s.logSink.Dropped(),
+		Rejected:  s.rejected.Load(),
+		Malformed: s.badLines.Load(),
+		Clients:   clients,
+	}
+}
 
-	if s.listener != nil {
-		s.listener.Close()
+// dispatch fans a log entry out to every sink and its source's subscribers
+func (s *Server) dispatch(entry log.LogEntry) {
+	s.received.Add(1)
+	if entry.Raw != "" {
+		s.bytes.Add(uint64(len(entry.Raw)))
+	} else {
+		s.bytes.Add(uint64(len(entry.Content)))
 	}
 
-	os.Remove(SocketPath)
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, sink := range s.sinks {
+		// A failing sink must not starve the others
+		sink.Write(entry)
+	}
+	for _, sink := range s.subs[entry.Source] {
+		sink.Write(entry)
+	}
+}
+
+// Close shuts down the server: it stops accepting connections, hangs up on
+// feeders and waits for their handlers to finish before closing the sinks.
+// Entries already dispatched stay readable from LogChannel, which is then
+// closed. Calling it again does nothing.
+func (s *Server) Close() error {
+	s.closed.Do(func() {
+		close(s.quit)
+
+		if s.listener != nil {
+			s.listener.Close()
+		}
+
+		// Tell feeders before hanging up so they can exit cleanly instead of
+		// failing on their next write
+		shutdown := NewServerShutdownMessage()
+		s.mutex.Lock()
+		for conn, client := range s.clients {
+			conn.SetWriteDeadline(time.Now().Add(shutdownWriteTimeout))
+			client.SendMessage(shutdown)
+			conn.Close()
+		}
+		s.mutex.Unlock()
+
+		// No handler dispatches once they have all returned, so no sink is
+		// written to after it is closed
+		s.handlers.Wait()
+
+		s.mutex.Lock()
+		for _, sink := range s.sinks {
+			sink.Close()
+		}
+		s.mutex.Unlock()
+
+		cleanup(s.path)
+	})
 	return nil
 }
 
+// validToken reports whether msg is a source_init carrying the server's
+// token
+func (s *Server) validToken(msg *IPCMessage) bool {
+	if msg.Type != MessageTypeSourceInit || msg.SourceInfo == nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(msg.SourceInfo.Token), []byte(s.token)) == 1
+}
+
 // acceptConnections handles incoming client connections
 func (s *Server) acceptConnections() {
+	defer s.handlers.Done()
 	for {
 		select {
 		case <-s.quit:
@@ -82,6 +285,7 @@ func (s *Server) acceptConnections() {
 			if err != nil {
 				continue
 			}
+			s.handlers.Add(1)
 			go s.handleClient(conn)
 		}
 	}
@@ -89,11 +293,20 @@ func (s *Server) acceptConnections() {
 
 // handleClient processes messages from a connected client
 func (s *Server) handleClient(conn net.Conn) {
+	defer s.handlers.Done()
 	defer conn.Close()
 
 	client := &Client{conn: conn}
 
+	// Close hangs up on the clients registered when it runs, so one
+	// arriving after that has to notice by itself
 	s.mutex.Lock()
+	select {
+	case <-s.quit:
+		s.mutex.Unlock()
+		return
+	default:
+	}
 	s.clients[conn] = client
 	s.mutex.Unlock()
 
@@ -103,26 +316,101 @@ func (s *Server) handleClient(conn net.Conn) {
 		s.mutex.Unlock()
 	}()
 
+	// The first message must be a source_init, carrying the token if one
+	// is required; after that, a run of unparsable lines means the client
+	// is not speaking the protocol at all
+	initialized := false
+	parseErrors := 0
+	source := ""
+
+	// claimed maps each source this connection declared to its instance
+	// number, given back when the connection ends
+	claimed := make(map[string]int)
+	defer func() {
+		for name, n := range claimed {
+			s.releaseInstance(name, n)
+		}
+	}()
+
 	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
 	for scanner.Scan() {
 		var msg IPCMessage
 		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			parseErrors++
+			if !initialized {
+				s.reject(client, source, fmt.Sprintf("malformed first message: %v", err))
+				return
+			}
+			if parseErrors >= maxParseErrors {
+				s.reject(client, source, fmt.Sprintf("%d malformed messages in a row: %v", parseErrors, err))
+				return
+			}
+			s.badLines.Add(1)
 			continue
 		}
+		parseErrors = 0
+
+		if !initialized {
+			if msg.Type != MessageTypeSourceInit || msg.SourceInfo == nil {
+				s.reject(client, source, fmt.Sprintf("expected source_init, got %q", msg.Type))
+				return
+			}
+			source = msg.SourceInfo.Name
+			if s.token != "" && !s.validToken(&msg) {
+				s.reject(client, source, "invalid or missing token")
+				return
+			}
+			initialized = true
+		}
 
 		switch msg.Type {
 		case MessageTypeLog:
 			if msg.LogEntry != nil {
-				select {
-				case s.logChan <- msg.LogEntry:
-				default:
-					// Channel full, drop message
+				entry := msg.LogEntry.ToLogEntry()
+				if entry.IngestTime.IsZero() {
+					// Sent by a producer that does not record it
+					entry.IngestTime = time.Now()
 				}
+				if n, ok := claimed[entry.Source]; ok {
+					s.applyInstance(&entry, n)
+				}
+				s.dispatch(entry)
 			}
 		case MessageTypeSourceInit:
-			// Handle source initialization
+			// A name another feeder already uses gets the next instance
+			if msg.SourceInfo == nil {
+				break
+			}
+			if name, err := ValidateSourceName(msg.SourceInfo.Name); err == nil {
+				if _, ok := claimed[name]; !ok {
+					claimed[name] = s.claimInstance(name)
+				}
+			}
 		case MessageTypeSourceExit:
 			// Handle source exit
 		}
 	}
+
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		s.reject(client, source, fmt.Sprintf("message over %d bytes", maxMessageSize))
+	}
+}
+
+// reject tells a client why it is being dropped for breaking the protocol
+// and logs it to logflow's diagnostics; the caller closes the connection
+func (s *Server) reject(client *Client, source, reason string) {
+	s.rejected.Add(1)
+
+	who := source
+	if who == "" {
+		who = "unidentified client"
+		if addr := client.conn.RemoteAddr(); addr != nil && addr.String() != "" && addr.String() != "@" {
+			who += " " + addr.String()
+		}
+	}
+	stdlog.Printf("Dropped IPC client (%s): %s", who, reason)
+
+	client.conn.SetWriteDeadline(time.Now().Add(shutdownWriteTimeout))
+	client.SendMessage(NewErrorMessage(reason))
 }
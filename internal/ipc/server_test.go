@@ -0,0 +1,373 @@
+// internal/ipc/server_test.go
+package ipc
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+)
+
+// testTimeout bounds every wait on the server, so a broken round trip
+// fails instead of hanging
+const testTimeout = 5 * time.Second
+
+// startServer starts a server on a socket in a temporary directory,
+// closed when the test ends
+func startServer(t *testing.T) *Server {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the round trip tests use a Unix socket")
+	}
+
+	server, err := NewServerAt(filepath.Join(t.TempDir(), "logflow.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+// connect opens a client to server that is closed when the test ends
+func connect(t *testing.T, server *Server) *Client {
+	t.Helper()
+	client, err := NewClientAt(server.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// receive reads n entries from the server's log channel
+func receive(t *testing.T, server *Server, n int) []log.LogEntry {
+	t.Helper()
+	var entries []log.LogEntry
+	timeout := time.After(testTimeout)
+	for len(entries) < n {
+		select {
+		case entry, ok := <-server.LogChannel():
+			if !ok {
+				t.Fatalf("log channel closed after %d of %d entries", len(entries), n)
+			}
+			entries = append(entries, entry)
+		case <-timeout:
+			t.Fatalf("received %d of %d entries", len(entries), n)
+		}
+	}
+	return entries
+}
+
+// waitFor polls cond until it holds, failing the test after testTimeout
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// sendLines sends entries numbered first to last from source
+func sendLines(t *testing.T, client *Client, source string, first, last int) {
+	t.Helper()
+	for i := first; i <= last; i++ {
+		err := client.SendLog(&LogEntry{
+			Timestamp: time.Now(),
+			Source:    source,
+			Level:     LogLevelInfo,
+			Content:   fmt.Sprintf("line %d", i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRoundTripInOrder(t *testing.T) {
+	server := startServer(t)
+	client := connect(t, server)
+	if err := client.InitSource("api", "pipe"); err != nil {
+		t.Fatal(err)
+	}
+
+	timestamp := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	const count = 200
+	for i := 0; i < count; i++ {
+		err := client.SendLog(&LogEntry{
+			Timestamp:     timestamp.Add(time.Duration(i) * time.Second),
+			SyntheticTime: i%2 == 0,
+			Source:        "api",
+			Level:         LogLevelError,
+			Content:       fmt.Sprintf("line %d", i),
+			Raw:           fmt.Sprintf(`{"msg":"line %d"}`, i),
+			Metadata:      map[string]interface{}{"n": i, "http": map[string]interface{}{"status": 500}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, entry := range receive(t, server, count) {
+		if entry.Content != fmt.Sprintf("line %d", i) {
+			t.Fatalf("entry %d has content %q, out of order", i, entry.Content)
+		}
+		if entry.Source != "api" || entry.Level != log.LogLevelError || entry.Raw != fmt.Sprintf(`{"msg":"line %d"}`, i) {
+			t.Errorf("entry %d came through as %+v", i, entry)
+		}
+		if want := timestamp.Add(time.Duration(i) * time.Second); !entry.Timestamp.Equal(want) {
+			t.Errorf("entry %d has timestamp %v, want %v", i, entry.Timestamp, want)
+		}
+		if entry.SyntheticTime != (i%2 == 0) {
+			t.Errorf("entry %d lost its synthetic time flag", i)
+		}
+		if entry.IngestTime.IsZero() {
+			t.Errorf("entry %d has no ingest time", i)
+		}
+		// JSON numbers arrive as float64, nested objects as maps
+		if n, _ := entry.Metadata["n"].(float64); int(n) != i {
+			t.Errorf("entry %d has metadata n = %v", i, entry.Metadata["n"])
+		}
+		if value, ok := log.LookupMetadata(entry.Metadata, "http.status"); !ok || value != "500" {
+			t.Errorf("entry %d has http.status = %q", i, value)
+		}
+	}
+
+	if stats := server.Stats(); stats.Received != count || stats.Dropped != 0 {
+		t.Errorf("stats after the round trip: %+v", stats)
+	}
+}
+
+func TestClientDroppedMidStream(t *testing.T) {
+	server := startServer(t)
+	client := connect(t, server)
+	if err := client.InitSource("api", "pipe"); err != nil {
+		t.Fatal(err)
+	}
+	sendLines(t, client, "api", 1, 5)
+
+	// Hang up halfway through a message
+	if _, err := client.conn.Write([]byte(`{"type":"log","log_entry":{"source":"api","cont`)); err != nil {
+		t.Fatal(err)
+	}
+	client.Close()
+
+	for i, entry := range receive(t, server, 5) {
+		if want := fmt.Sprintf("line %d", i+1); entry.Content != want {
+			t.Errorf("entry %d = %q, want %q", i, entry.Content, want)
+		}
+	}
+	waitFor(t, "the dropped client to be forgotten", func() bool {
+		return server.Stats().Clients == 0
+	})
+	if stats := server.Stats(); stats.Rejected != 0 {
+		t.Errorf("a client that hung up was counted as rejected: %+v", stats)
+	}
+
+	// The server keeps serving, and the name is free again
+	again := connect(t, server)
+	if err := again.InitSource("api", "pipe"); err != nil {
+		t.Fatal(err)
+	}
+	sendLines(t, again, "api", 6, 6)
+	if entry := receive(t, server, 1)[0]; entry.Source != "api" || entry.Content != "line 6" {
+		t.Errorf("entry after reconnecting = %+v", entry)
+	}
+}
+
+func TestMalformedFirstMessageIsRejected(t *testing.T) {
+	server := startServer(t)
+	client := connect(t, server)
+
+	if _, err := client.conn.Write([]byte("not json\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-client.Shutdown():
+	case <-time.After(testTimeout):
+		t.Fatal("the server did not hang up on the client")
+	}
+	if err := client.Err(); err == nil || !strings.Contains(err.Error(), "malformed first message") {
+		t.Errorf("client error = %v, want the rejection reason", err)
+	}
+	if stats := server.Stats(); stats.Rejected != 1 {
+		t.Errorf("rejected = %d, want 1", stats.Rejected)
+	}
+}
+
+func TestMalformedMessagesAreCounted(t *testing.T) {
+	server := startServer(t)
+	client := connect(t, server)
+	if err := client.InitSource("api", "pipe"); err != nil {
+		t.Fatal(err)
+	}
+
+	sendLines(t, client, "api", 1, 1)
+	for _, line := range []string{"{broken", `{"type":"log","log_entry":`, "]"} {
+		if _, err := client.conn.Write([]byte(line + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sendLines(t, client, "api", 2, 2)
+
+	entries := receive(t, server, 2)
+	if entries[0].Content != "line 1" || entries[1].Content != "line 2" {
+		t.Errorf("entries around the malformed lines = %q, %q", entries[0].Content, entries[1].Content)
+	}
+	if stats := server.Stats(); stats.Malformed != 3 || stats.Rejected != 0 {
+		t.Errorf("stats after 3 malformed lines: %+v", stats)
+	}
+}
+
+func TestMalformedRunIsRejected(t *testing.T) {
+	server := startServer(t)
+	client := connect(t, server)
+	if err := client.InitSource("api", "pipe"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < maxParseErrors; i++ {
+		if _, err := client.conn.Write([]byte("garbage\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case <-client.Shutdown():
+	case <-time.After(testTimeout):
+		t.Fatal("the server did not hang up on the client")
+	}
+	if err := client.Err(); err == nil || !strings.Contains(err.Error(), "malformed messages in a row") {
+		t.Errorf("client error = %v, want the rejection reason", err)
+	}
+	if stats := server.Stats(); stats.Rejected != 1 {
+		t.Errorf("rejected = %d, want 1", stats.Rejected)
+	}
+}
+
+func TestCloseDrainsAndClosesLogChannel(t *testing.T) {
+	server := startServer(t)
+	client := connect(t, server)
+	if err := client.InitSource("api", "pipe"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nobody reads while the entries arrive, as when the dashboard quits
+	// with entries still queued
+	const count = 300
+	sendLines(t, client, "api", 1, count)
+	waitFor(t, "every entry to be dispatched", func() bool {
+		return server.Stats().Received == count
+	})
+
+	closed := make(chan struct{})
+	go func() {
+		server.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(testTimeout):
+		t.Fatal("Close did not return with a client still connected")
+	}
+
+	// Everything dispatched before Close is still readable, then the
+	// channel ends so a range over it returns
+	n := 0
+	for entry := range server.LogChannel() {
+		n++
+		if want := fmt.Sprintf("line %d", n); entry.Content != want {
+			t.Fatalf("entry %d = %q, want %q", n, entry.Content, want)
+		}
+	}
+	if n != count {
+		t.Errorf("drained %d entries after Close, want %d", n, count)
+	}
+
+	select {
+	case <-client.Shutdown():
+	case <-time.After(testTimeout):
+		t.Error("the client was not told about the shutdown")
+	}
+
+	// A second Close is harmless
+	server.Close()
+}
+
+// countingSink counts the entries written to it and fails the test if one
+// arrives after it was closed
+type countingSink struct {
+	t      *testing.T
+	mutex  sync.Mutex
+	n      int
+	closed bool
+}
+
+func (c *countingSink) Write(entry log.LogEntry) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		c.t.Error("entry written to a closed sink")
+	}
+	c.n++
+	return nil
+}
+
+func (c *countingSink) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.closed = true
+	return nil
+}
+
+func TestCloseWaitsForHandlersBeforeClosingSinks(t *testing.T) {
+	server := startServer(t)
+	sink := &countingSink{t: t}
+	server.AddSink(sink)
+
+	// Several feeders still writing while the server shuts down
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		client := connect(t, server)
+		if err := client.InitSource(fmt.Sprintf("feeder-%d", i), "pipe"); err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func(client *Client, source string) {
+			defer wg.Done()
+			for j := 0; ; j++ {
+				entry := &LogEntry{Source: source, Level: LogLevelInfo, Content: fmt.Sprintf("line %d", j)}
+				if client.SendLog(entry) != nil {
+					return
+				}
+			}
+		}(client, fmt.Sprintf("feeder-%d", i))
+	}
+	go func() {
+		for range server.LogChannel() {
+		}
+	}()
+
+	waitFor(t, "entries to flow", func() bool {
+		return server.Stats().Received > 100
+	})
+	server.Close()
+	wg.Wait()
+
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	if !sink.closed {
+		t.Error("Close did not close the added sink")
+	}
+	if received := server.Stats().Received; uint64(sink.n) != received {
+		t.Errorf("the sink got %d entries of %d dispatched", sink.n, received)
+	}
+}
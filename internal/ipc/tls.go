@@ -0,0 +1,65 @@
+// internal/ipc/tls.go
+package ipc
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// LoadServerTLS loads the certificate and key a dashboard serves TLS with
+func LoadServerTLS(certFile, keyFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// LoadClientTLS returns the TLS settings a feeder connects with, trusting
+// the PEM certificates in caFile or, if it is empty, the system roots
+func LoadClientTLS(caFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile == "" {
+		return config, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	config.RootCAs = pool
+	return config, nil
+}
+
+// pinnedTLS returns client settings that accept exactly the certificate
+// of a server config, for connections a dashboard makes to itself. The
+// certificate need not name the address dialed, so the usual hostname
+// checks are replaced by comparing it byte for byte.
+func pinnedTLS(server *tls.Config) *tls.Config {
+	if server == nil {
+		return nil
+	}
+
+	leaf := server.Certificates[0].Certificate[0]
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 || !bytes.Equal(rawCerts[0], leaf) {
+				return errors.New("dashboard presented an unexpected certificate")
+			}
+			return nil
+		},
+	}
+}
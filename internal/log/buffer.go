@@ -2,8 +2,10 @@
 package log
 
 import (
+	"os"
 	"strings"
 	"sync"
+	"time"
 )
 
 // Buffer manages a circular buffer of log entries for a source
@@ -12,56 +14,181 @@ type Buffer struct {
 	size    int
 	index   int
 	count   int
+	seq     uint64
 	mutex   sync.RWMutex
+
+	// levelIndex holds, per minimum level, the sequence numbers of held
+	// entries at that level or above so filtering skips non-matches
+	levelIndex map[LogLevel][]uint64
+
+	// levelCounts counts held entries per level, kept up to date as
+	// entries are added and evicted
+	levelCounts map[LogLevel]int
+
+	// spill, when enabled, keeps evicted entries on disk
+	spill *spill
 }
 
-// NewBuffer creates a new log buffer with the specified size
+// NewBuffer creates a new log buffer with the specified size. Sizes below
+// one are raised to one so the ring arithmetic stays defined.
 func NewBuffer(size int) *Buffer {
+	if size < 1 {
+		size = 1
+	}
+
 	return &Buffer{
 		entries: make([]LogEntry, size),
 		size:    size,
 		index:   0,
 		count:   0,
+
+		levelIndex:  make(map[LogLevel][]uint64),
+		levelCounts: make(map[LogLevel]int),
 	}
 }
 
-// Add appends a log entry to the buffer
+// Add appends a log entry to the buffer and assigns it the next
+// sequence number
 func (b *Buffer) Add(entry LogEntry) {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
+	b.seq++
+	entry.Seq = b.seq
+
+	if b.count == b.size {
+		b.uncount(b.entries[b.index])
+		if b.spill != nil {
+			b.spill.write(b.entries[b.index])
+		}
+	}
 	b.entries[b.index] = entry
 	b.index = (b.index + 1) % b.size
 
 	if b.count < b.size {
 		b.count++
 	}
+
+	b.indexEntry(entry)
+	b.levelCounts[entry.Level]++
+}
+
+// uncount takes an evicted entry out of the level counts. Must be called
+// with the lock held.
+func (b *Buffer) uncount(entry LogEntry) {
+	b.levelCounts[entry.Level]--
+	if b.levelCounts[entry.Level] <= 0 {
+		delete(b.levelCounts, entry.Level)
+	}
+}
+
+// LevelCounts returns how many held entries there are of each level,
+// leaving out levels with none
+func (b *Buffer) LevelCounts() map[LogLevel]int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	counts := make(map[LogLevel]int, len(b.levelCounts))
+	for level, n := range b.levelCounts {
+		counts[level] = n
+	}
+	return counts
+}
+
+// indexEntry records the entry in the level index and drops indexed
+// sequence numbers that have been evicted. Must be called with the lock held.
+func (b *Buffer) indexEntry(entry LogEntry) {
+	first := b.firstSeq()
+
+	for level := range levelOrder {
+		seqs := b.levelIndex[level]
+		for len(seqs) > 0 && seqs[0] < first {
+			seqs = seqs[1:]
+		}
+		if entry.Level.AtLeast(level) {
+			seqs = append(seqs, entry.Seq)
+		}
+		b.levelIndex[level] = seqs
+	}
+}
+
+// firstSeq returns the sequence number of the oldest held entry. Must be
+// called with the lock held.
+func (b *Buffer) firstSeq() uint64 {
+	return b.seq - uint64(b.count) + 1
+}
+
+// at returns the held entry with the given sequence number. Must be called
+// with the lock held and a sequence number within the buffer.
+func (b *Buffer) at(seq uint64) LogEntry {
+	// Sequence numbers are contiguous, so the offset from the oldest
+	// entry maps directly onto a ring position
+	offset := int(seq - b.firstSeq())
+	return b.entries[(b.oldestIndex()+offset)%b.size]
+}
+
+// oldestIndex returns the ring position of the oldest held entry. Must be
+// called with the lock held.
+func (b *Buffer) oldestIndex() int {
+	return (b.index - b.count + b.size) % b.size
 }
 
 // GetAll returns all log entries in chronological order
 func (b *Buffer) GetAll() []LogEntry {
 	b.mutex.RLock()
 	defer b.mutex.RUnlock()
+	return b.held()
+}
 
+// held copies the entries in memory, oldest first. Must be called with the
+// lock held.
+func (b *Buffer) held() []LogEntry {
 	if b.count == 0 {
 		return nil
 	}
 
 	result := make([]LogEntry, b.count)
 
-	if b.count < b.size {
-		// Buffer not full yet, entries are from 0 to count-1
-		copy(result, b.entries[:b.count])
-	} else {
-		// Buffer is full, entries wrap around
-		startIdx := b.index
-		copy(result, b.entries[startIdx:])
-		copy(result[b.size-startIdx:], b.entries[:startIdx])
-	}
+	// The oldest entry sits count slots behind the write index. Copy from
+	// there to the end of the backing slice, then wrap around to the start.
+	start := b.oldestIndex()
+	n := copy(result, b.entries[start:min(start+b.count, b.size)])
+	copy(result[n:], b.entries[:b.count-n])
 
 	return result
 }
 
+// Get returns the entry with the given sequence number, if it is still
+// held in the buffer
+func (b *Buffer) Get(seq uint64) (LogEntry, bool) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if b.count == 0 || seq < b.firstSeq() || seq > b.seq {
+		return LogEntry{}, false
+	}
+
+	return b.at(seq), true
+}
+
+// Since returns all entries with a sequence number greater than seq
+func (b *Buffer) Since(seq uint64) []LogEntry {
+	all := b.GetAll()
+	for i, entry := range all {
+		if entry.Seq > seq {
+			return all[i:]
+		}
+	}
+	return nil
+}
+
+// LastSeq returns the sequence number of the most recently added entry
+func (b *Buffer) LastSeq() uint64 {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.seq
+}
+
 // GetRecent returns the most recent n log entries
 func (b *Buffer) GetRecent(n int) []LogEntry {
 	all := b.GetAll()
@@ -71,13 +198,114 @@ func (b *Buffer) GetRecent(n int) []LogEntry {
 	return all[len(all)-n:]
 }
 
-// Clear removes all entries from the buffer
+// Clear removes all entries from the buffer, including those spilled to
+// disk
 func (b *Buffer) Clear() {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
 	b.count = 0
 	b.index = 0
+	b.levelIndex = make(map[LogLevel][]uint64)
+	b.levelCounts = make(map[LogLevel]int)
+	if b.spill != nil {
+		b.spill.reset()
+	}
+}
+
+// EnableSpill keeps entries evicted from memory in a JSON lines file at
+// path, created or truncated, where EachSpilled, EachEntry and Search
+// still reach them. Memory use stays bounded by the buffer size while
+// history grows on disk.
+func (b *Buffer) EnableSpill(path string) error {
+	s, err := openSpill(path)
+	if err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.spill != nil {
+		b.spill.close()
+	}
+	b.spill = s
+	return nil
+}
+
+// Close closes the spill file, if any. Entries spilled so far stay
+// readable, later evictions are dropped.
+func (b *Buffer) Close() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.spill == nil {
+		return nil
+	}
+	err := b.spill.close()
+	b.spill.err = os.ErrClosed
+	return err
+}
+
+// Spilled returns the number of entries kept on disk
+func (b *Buffer) Spilled() int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if b.spill == nil {
+		return 0
+	}
+	return b.spill.count
+}
+
+// EachSpilled calls fn for every entry kept on disk, oldest first,
+// stopping at the first error fn returns. Entries still in memory are not
+// included, GetAll has those.
+func (b *Buffer) EachSpilled(fn func(LogEntry) error) error {
+	b.mutex.RLock()
+	s := b.spill
+	limit := 0
+	if s != nil {
+		limit = s.count
+	}
+	b.mutex.RUnlock()
+
+	if limit == 0 {
+		return nil
+	}
+	return s.each(limit, fn)
+}
+
+// EachEntry calls fn for every entry kept, those spilled to disk first
+// and then those in memory, stopping at the first error fn returns. Both
+// tiers are taken at the same moment, so an entry evicted to disk while
+// fn runs is neither missed nor seen twice. A spill file that can't be
+// read still leaves the entries in memory, its error is returned after.
+func (b *Buffer) EachEntry(fn func(LogEntry) error) error {
+	b.mutex.RLock()
+	s := b.spill
+	limit := 0
+	if s != nil {
+		limit = s.count
+	}
+	entries := b.held()
+	b.mutex.RUnlock()
+
+	var readErr, fnErr error
+	if limit > 0 {
+		readErr = s.each(limit, func(entry LogEntry) error {
+			fnErr = fn(entry)
+			return fnErr
+		})
+		if fnErr != nil {
+			return fnErr
+		}
+	}
+	for _, entry := range entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return readErr
 }
 
 // Count returns the number of entries in the buffer
@@ -87,40 +315,124 @@ func (b *Buffer) Count() int {
 	return b.count
 }
 
+// Capacity returns how many entries the buffer holds before evicting the
+// oldest
+func (b *Buffer) Capacity() int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.size
+}
+
+// Resize changes how many entries the buffer holds, keeping the newest.
+// Shrinking evicts the oldest entries past the new size, to the spill
+// file when one is enabled. Sizes below one are raised to one.
+func (b *Buffer) Resize(size int) {
+	if size < 1 {
+		size = 1
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if size == b.size {
+		return
+	}
+
+	kept := min(b.count, size)
+	entries := make([]LogEntry, size)
+	oldest := b.oldestIndex()
+	for i := 0; i < b.count; i++ {
+		entry := b.entries[(oldest+i)%b.size]
+		if i < b.count-kept {
+			b.uncount(entry)
+			if b.spill != nil {
+				b.spill.write(entry)
+			}
+			continue
+		}
+		entries[i-(b.count-kept)] = entry
+	}
+
+	b.entries = entries
+	b.size = size
+	b.count = kept
+	b.index = kept % size
+
+	first := b.firstSeq()
+	for level, seqs := range b.levelIndex {
+		for len(seqs) > 0 && seqs[0] < first {
+			seqs = seqs[1:]
+		}
+		b.levelIndex[level] = seqs
+	}
+}
+
 // Filter returns entries matching the specified log level or higher
 func (b *Buffer) Filter(minLevel LogLevel) []LogEntry {
-	all := b.GetAll()
-	var filtered []LogEntry
+	return b.FilterWithContext(minLevel, 0)
+}
 
-	levelOrder := map[LogLevel]int{
-		LogLevelDebug: 0,
-		LogLevelInfo:  1,
-		LogLevelWarn:  2,
-		LogLevelError: 3,
+// FilterWithContext returns entries matching the specified log level or
+// higher, each preceded by up to before entries of any level, like grep -B.
+// Overlapping context windows are merged so no entry appears twice.
+func (b *Buffer) FilterWithContext(minLevel LogLevel, before int) []LogEntry {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if b.count == 0 {
+		return nil
 	}
 
-	minLevelOrder := levelOrder[minLevel]
+	first := b.firstSeq()
+	// An unknown level matches nothing, as in AtLeast
+	matches := b.levelIndex[minLevel]
+
+	var filtered []LogEntry
+
+	// next is the sequence number of the first entry not yet emitted
+	next := first
+	for _, seq := range matches {
+		if seq < first {
+			continue
+		}
 
-	for _, entry := range all {
-		if levelOrder[entry.Level] >= minLevelOrder {
-			filtered = append(filtered, entry)
+		start := seq - min(uint64(before), seq-next)
+		for s := start; s <= seq; s++ {
+			filtered = append(filtered, b.at(s))
 		}
+		next = seq + 1
 	}
 
 	return filtered
 }
 
+// FilterTimeRange keeps the entries timestamped within [start, end), in
+// their original order. A zero start or end leaves that side open.
+func FilterTimeRange(entries []LogEntry, start, end time.Time) []LogEntry {
+	var filtered []LogEntry
+	for _, entry := range entries {
+		if !start.IsZero() && entry.Timestamp.Before(start) {
+			continue
+		}
+		if !end.IsZero() && !entry.Timestamp.Before(end) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered
+}
+
 // Search returns entries containing the specified search term
 func (b *Buffer) Search(term string) []LogEntry {
-	all := b.GetAll()
 	var matches []LogEntry
 
-	for _, entry := range all {
+	// Entries spilled to disk come first, they are the oldest
+	b.EachEntry(func(entry LogEntry) error {
 		if strings.Contains(strings.ToLower(entry.Content), strings.ToLower(term)) ||
 			strings.Contains(strings.ToLower(entry.Raw), strings.ToLower(term)) {
 			matches = append(matches, entry)
 		}
-	}
+		return nil
+	})
 
 	return matches
 }
@@ -0,0 +1,219 @@
+// internal/log/buffer_test.go
+package log
+
+import (
+	"fmt"
+	"testing"
+)
+
+// fillBuffer adds n entries numbered from 1 to a buffer of the given size
+func fillBuffer(size, n int) *Buffer {
+	b := NewBuffer(size)
+	for i := 1; i <= n; i++ {
+		b.Add(LogEntry{Level: LogLevelInfo, Content: fmt.Sprintf("line %d", i)})
+	}
+	return b
+}
+
+// wantLines lists the contents of entries first through last
+func wantLines(first, last int) []string {
+	var lines []string
+	for i := first; i <= last; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	return lines
+}
+
+func TestBufferGetAllWrapPositions(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		added  int
+		resize int // Applied after adding when non-zero
+		want   []string
+	}{
+		{name: "empty", size: 4, added: 0},
+		{name: "one entry", size: 4, added: 1, want: wantLines(1, 1)},
+		{name: "partly full", size: 4, added: 3, want: wantLines(1, 3)},
+		{name: "exactly full", size: 4, added: 4, want: wantLines(1, 4)},
+		{name: "wrapped by one", size: 4, added: 5, want: wantLines(2, 5)},
+		{name: "wrapped to the middle", size: 4, added: 6, want: wantLines(3, 6)},
+		{name: "wrapped to the last slot", size: 4, added: 7, want: wantLines(4, 7)},
+		{name: "wrapped once exactly", size: 4, added: 8, want: wantLines(5, 8)},
+		{name: "wrapped several times", size: 4, added: 23, want: wantLines(20, 23)},
+		{name: "size one", size: 1, added: 5, want: wantLines(5, 5)},
+		{name: "grown while partly full", size: 4, added: 3, resize: 8, want: wantLines(1, 3)},
+		{name: "grown after wrapping", size: 4, added: 6, resize: 8, want: wantLines(3, 6)},
+		{name: "shrunk while partly full", size: 4, added: 3, resize: 2, want: wantLines(2, 3)},
+		{name: "shrunk after wrapping", size: 4, added: 7, resize: 2, want: wantLines(6, 7)},
+		{name: "shrunk to the same count", size: 4, added: 6, resize: 4, want: wantLines(3, 6)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := fillBuffer(tt.size, tt.added)
+			if tt.resize != 0 {
+				b.Resize(tt.resize)
+			}
+
+			got := b.GetAll()
+			if len(got) != len(tt.want) || b.Count() != len(tt.want) {
+				t.Fatalf("GetAll returned %d entries, Count %d, want %d", len(got), b.Count(), len(tt.want))
+			}
+			for i, entry := range got {
+				if entry.Content != tt.want[i] {
+					t.Errorf("entry %d = %q, want %q", i, entry.Content, tt.want[i])
+				}
+				if i > 0 && entry.Seq != got[i-1].Seq+1 {
+					t.Errorf("entry %d has seq %d after %d", i, entry.Seq, got[i-1].Seq)
+				}
+			}
+		})
+	}
+}
+
+func TestBufferAddAfterResize(t *testing.T) {
+	b := fillBuffer(4, 6)
+	b.Resize(3)
+	for i := 7; i <= 8; i++ {
+		b.Add(LogEntry{Level: LogLevelInfo, Content: fmt.Sprintf("line %d", i)})
+	}
+
+	want := wantLines(6, 8)
+	got := b.GetAll()
+	if len(got) != len(want) {
+		t.Fatalf("GetAll returned %d entries, want %d", len(got), len(want))
+	}
+	for i, entry := range got {
+		if entry.Content != want[i] {
+			t.Errorf("entry %d = %q, want %q", i, entry.Content, want[i])
+		}
+	}
+}
+
+func TestBufferClearThenAdd(t *testing.T) {
+	b := fillBuffer(4, 6)
+	b.Clear()
+	if got := b.GetAll(); got != nil {
+		t.Fatalf("GetAll after Clear = %v, want nil", got)
+	}
+
+	b.Add(LogEntry{Level: LogLevelInfo, Content: "after"})
+	got := b.GetAll()
+	if len(got) != 1 || got[0].Content != "after" {
+		t.Fatalf("GetAll after Clear and Add = %v, want one entry", got)
+	}
+}
+
+// errorBuffer returns a full buffer of size entries where one in every
+// hundred is an error, the case the level index is for
+func errorBuffer(size int) *Buffer {
+	b := NewBuffer(size)
+	for i := 0; i < size; i++ {
+		level := LogLevelInfo
+		if i%100 == 0 {
+			level = LogLevelError
+		}
+		b.Add(LogEntry{Level: level, Content: fmt.Sprintf("line %d", i)})
+	}
+	return b
+}
+
+func BenchmarkFilterErrorIndexed(b *testing.B) {
+	buf := errorBuffer(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Filter(LogLevelError)
+	}
+}
+
+// BenchmarkFilterErrorScan is the full scan the level index replaced, as
+// the baseline for BenchmarkFilterErrorIndexed
+func BenchmarkFilterErrorScan(b *testing.B) {
+	buf := errorBuffer(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var filtered []LogEntry
+		for _, entry := range buf.GetAll() {
+			if entry.Level.AtLeast(LogLevelError) {
+				filtered = append(filtered, entry)
+			}
+		}
+	}
+}
+
+func TestFilterMatchesScan(t *testing.T) {
+	b := errorBuffer(1000)
+	for i := 0; i < 250; i++ {
+		b.Add(LogEntry{Level: LogLevelWarn, Content: "more"})
+	}
+
+	for _, level := range []LogLevel{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError} {
+		var want []uint64
+		for _, entry := range b.GetAll() {
+			if entry.Level.AtLeast(level) {
+				want = append(want, entry.Seq)
+			}
+		}
+
+		got := b.Filter(level)
+		if len(got) != len(want) {
+			t.Fatalf("Filter(%s) returned %d entries, want %d", level, len(got), len(want))
+		}
+		for i, entry := range got {
+			if entry.Seq != want[i] {
+				t.Fatalf("Filter(%s) entry %d has seq %d, want %d", level, i, entry.Seq, want[i])
+			}
+		}
+	}
+}
+
+// benchmarkSize is the default pane buffer size, what the hot paths see
+const benchmarkSize = 1000
+
+// fullBuffer returns a full buffer of size entries with a mix of levels
+func fullBuffer(size int) *Buffer {
+	levels := []LogLevel{LogLevelDebug, LogLevelInfo, LogLevelInfo, LogLevelWarn, LogLevelError}
+	b := NewBuffer(size)
+	for i := 0; i < size; i++ {
+		b.Add(LogEntry{
+			Level:   levels[i%len(levels)],
+			Content: fmt.Sprintf("request %d handled in %dms", i, i%250),
+			Raw:     fmt.Sprintf(`{"msg":"request %d handled in %dms"}`, i, i%250),
+		})
+	}
+	return b
+}
+
+func BenchmarkBufferAdd(b *testing.B) {
+	buf := fullBuffer(benchmarkSize)
+	entry := LogEntry{Level: LogLevelInfo, Content: "request handled in 12ms"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Add(entry)
+	}
+}
+
+func BenchmarkBufferGetAll(b *testing.B) {
+	buf := fullBuffer(benchmarkSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.GetAll()
+	}
+}
+
+func BenchmarkBufferFilter(b *testing.B) {
+	buf := fullBuffer(benchmarkSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Filter(LogLevelWarn)
+	}
+}
+
+func BenchmarkBufferSearch(b *testing.B) {
+	buf := fullBuffer(benchmarkSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Search("in 42ms")
+	}
+}
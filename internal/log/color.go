@@ -0,0 +1,52 @@
+package log
+
+import "os"
+
+// ColorMode controls whether output is colored
+type ColorMode int
+
+const (
+	ColorAuto   ColorMode = iota // Color only when writing to a terminal
+	ColorNever                   // Never emit color
+	ColorAlways                  // Always emit color, even when piped
+)
+
+// colorMode is the process-wide color decision, seeded from the environment
+var colorMode = colorModeFromEnv()
+
+// colorModeFromEnv honors the NO_COLOR and FORCE_COLOR conventions, with
+// NO_COLOR taking precedence when both are set
+func colorModeFromEnv() ColorMode {
+	if os.Getenv("NO_COLOR") != "" {
+		return ColorNever
+	}
+	switch os.Getenv("FORCE_COLOR") {
+	case "", "0", "false":
+		return ColorAuto
+	default:
+		return ColorAlways
+	}
+}
+
+// SetColorMode overrides the color decision, e.g. from a --no-color flag
+func SetColorMode(mode ColorMode) {
+	colorMode = mode
+}
+
+// CurrentColorMode returns the process-wide color decision
+func CurrentColorMode() ColorMode {
+	return colorMode
+}
+
+// ColorEnabled reports whether output to a destination should be colored,
+// given whether that destination is a terminal
+func ColorEnabled(isTerminal bool) bool {
+	switch colorMode {
+	case ColorNever:
+		return false
+	case ColorAlways:
+		return true
+	default:
+		return isTerminal
+	}
+}
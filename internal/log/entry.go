@@ -3,6 +3,7 @@ package log
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -16,36 +17,106 @@ const (
 	LogLevelError LogLevel = "ERROR"
 )
 
+// ParseLogLevel converts a level name such as "warn" to a LogLevel
+func ParseLogLevel(name string) (LogLevel, error) {
+	level := LogLevel(strings.ToUpper(strings.TrimSpace(name)))
+	if _, ok := levelOrder[level]; !ok {
+		return "", fmt.Errorf("unknown log level %q", name)
+	}
+	return level, nil
+}
+
+// AtLeast reports whether the level is as severe as min or more, by the
+// order of the level registry. An unregistered level ranks with the least
+// severe one, but an unregistered min matches nothing, so a filter for a
+// level the registry lacks does not quietly show everything.
+func (l LogLevel) AtLeast(min LogLevel) bool {
+	rank, ok := levelOrder[min]
+	return ok && levelOrder[l] >= rank
+}
+
 // LogEntry represents a structured log entry
 type LogEntry struct {
-	Timestamp time.Time              `json:"timestamp"`
-	Source    string                 `json:"source"`
-	Level     LogLevel               `json:"level"`
-	Content   string                 `json:"content"`
-	Raw       string                 `json:"raw"`
-	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Seq        uint64                 `json:"seq,omitempty"`
+	Timestamp  time.Time              `json:"timestamp"`
+	IngestTime time.Time              `json:"ingest_time"` // When logflow received the line
+	Source     string                 `json:"source"`
+	Level      LogLevel               `json:"level"`
+	Content    string                 `json:"content"`
+	Raw        string                 `json:"raw"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+
+	// SyntheticTime marks a Timestamp that is only the ingest time because
+	// the line carried none of its own
+	SyntheticTime bool `json:"synthetic_time,omitempty"`
+}
+
+// ParseOptions controls how much of a raw line NewLogEntryWithOptions
+// interprets. The zero value parses everything.
+type ParseOptions struct {
+	// Raw keeps the line verbatim, skipping structured parsing so the
+	// content, timestamp and metadata are left alone
+	Raw bool
+
+	// NoLevel skips level detection, giving every line the default level
+	NoLevel bool
+
+	// Format names the parser for every line, as listed by Formats. Empty
+	// or FormatAuto detects each line's format.
+	Format string
 }
 
 // NewLogEntry creates a new log entry from raw log line
 func NewLogEntry(source, rawLine string) *LogEntry {
-	parser := NewParser()
+	return NewLogEntryWithOptions(source, rawLine, ParseOptions{})
+}
+
+// NewLogEntryWithOptions creates a new log entry from a raw log line,
+// interpreting it as far as opts allow
+func NewLogEntryWithOptions(source, rawLine string, opts ParseOptions) *LogEntry {
+	parser := sharedParser
 
+	now := time.Now()
 	entry := &LogEntry{
-		Timestamp: time.Now(),
-		Source:    source,
-		Raw:       rawLine,
-		Content:   rawLine,
-		Metadata:  make(map[string]interface{}),
+		Timestamp:     now,
+		IngestTime:    now,
+		SyntheticTime: true,
+		Source:        source,
+		Raw:           rawLine,
+		Content:       rawLine,
+		Level:         defaultLevel,
+		Metadata:      make(map[string]interface{}),
 	}
 
-	// Parse log level from the raw line
-	entry.Level = parser.ParseLevel(rawLine)
+	if opts.Raw {
+		if !opts.NoLevel {
+			entry.Level = parser.ParseLevel(rawLine)
+		}
+		return entry
+	}
+
+	structured := lookupFormat(opts.Format)(parser, rawLine)
+
+	// A declared format is trusted for the level it carries; the raw line
+	// is only scanned for one when it has none, or the format is detected
+	if !opts.NoLevel {
+		declared := false
+		if opts.Format != "" && opts.Format != FormatAuto {
+			if name, ok := structured["level"].(string); ok {
+				entry.Level, declared = parser.matchLevel(name)
+			}
+		}
+		if !declared {
+			entry.Level = parser.ParseLevel(rawLine)
+		}
+	}
 
 	// Extract structured content if possible
-	if structured := parser.ParseStructured(rawLine); structured != nil {
+	if structured != nil {
 		if ts, ok := structured["timestamp"]; ok {
 			if timestamp, ok := ts.(time.Time); ok {
 				entry.Timestamp = timestamp
+				entry.SyntheticTime = false
 			}
 		}
 		if content, ok := structured["message"]; ok {
@@ -66,26 +137,22 @@ func NewLogEntry(source, rawLine string) *LogEntry {
 
 // String returns a formatted string representation of the log entry
 func (e *LogEntry) String() string {
-	timestamp := e.Timestamp.Format("15:04:05")
+	timestamp := DisplayTime(e.Timestamp).Format("15:04:05")
 	return fmt.Sprintf("%s %s %s", timestamp, e.Level, e.Content)
 }
 
-// ColorString returns a colored string representation based on log level
+// ColorString returns a colored string representation based on log level,
+// falling back to String when color is disabled
 func (e *LogEntry) ColorString() string {
-	timestamp := e.Timestamp.Format("15:04:05")
-
-	var levelColor string
-	switch e.Level {
-	case LogLevelError:
-		levelColor = "\033[31m" // Red
-	case LogLevelWarn:
-		levelColor = "\033[33m" // Yellow
-	case LogLevelInfo:
-		levelColor = "\033[34m" // Blue
-	case LogLevelDebug:
-		levelColor = "\033[37m" // Gray
-	default:
-		levelColor = "\033[0m" // Reset
+	if colorMode == ColorNever {
+		return e.String()
+	}
+
+	timestamp := DisplayTime(e.Timestamp).Format("15:04:05")
+
+	levelColor := "\033[0m" // Reset
+	if color := LevelColor(e.Level); color != "" {
+		levelColor = "\033[38;5;" + color + "m"
 	}
 
 	reset := "\033[0m"
@@ -0,0 +1,74 @@
+// internal/log/entry_test.go
+package log
+
+import "testing"
+
+func TestNewLogEntryLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		opts ParseOptions
+		want LogLevel
+	}{
+		{
+			name: "declared format level wins",
+			line: `{"level":"info","msg":"retrying after ERROR from upstream"}`,
+			opts: ParseOptions{Format: "json"},
+			want: LogLevelInfo,
+		},
+		{
+			name: "declared logfmt level wins",
+			line: `level=warn msg="debug endpoint hit"`,
+			opts: ParseOptions{Format: "logfmt"},
+			want: LogLevelWarn,
+		},
+		{
+			name: "declared format without a level is scanned",
+			line: `{"msg":"ERROR connecting"}`,
+			opts: ParseOptions{Format: "json"},
+			want: LogLevelError,
+		},
+		{
+			name: "declared format with an unknown level is scanned",
+			line: `{"level":"verbose","msg":"WARN disk at 91%"}`,
+			opts: ParseOptions{Format: "json"},
+			want: LogLevelWarn,
+		},
+		{
+			name: "detected format is scanned",
+			line: `{"level":"info","msg":"retrying after ERROR from upstream"}`,
+			want: LogLevelError,
+		},
+		{
+			name: "raw line is scanned",
+			line: `{"level":"info","msg":"retrying after ERROR from upstream"}`,
+			opts: ParseOptions{Raw: true, Format: "json"},
+			want: LogLevelError,
+		},
+		{
+			name: "no level detection",
+			line: `{"level":"error","msg":"ERROR"}`,
+			opts: ParseOptions{NoLevel: true, Format: "json"},
+			want: defaultLevel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewLogEntryWithOptions("api", tt.line, tt.opts).Level; got != tt.want {
+				t.Errorf("level = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// BenchmarkNewLogEntryDeclaredFormat is the per-line cost when the format,
+// and so the level field, is known
+func BenchmarkNewLogEntryDeclaredFormat(b *testing.B) {
+	line := `{"time":"2024-05-01T12:00:00Z","level":"info","msg":"request handled","status":200,"path":"/api/users/42"}`
+	opts := ParseOptions{Format: "json"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		NewLogEntryWithOptions("api", line, opts)
+	}
+}
@@ -0,0 +1,281 @@
+// internal/log/formats.go
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatAuto detects the format of every line, the default
+const FormatAuto = "auto"
+
+// LineFormat parses a raw line of a known format into structured fields,
+// the same shape ParseStructured returns: "timestamp" as a time.Time,
+// "message", "level" and any other fields, which become metadata. It
+// returns nil for a line that is not in the format.
+type LineFormat func(p *Parser, line string) map[string]interface{}
+
+// formats are the parsers a source can select by name instead of having
+// each line's format guessed
+var formats = map[string]LineFormat{
+	FormatAuto: (*Parser).ParseStructured,
+	"json":     parseJSONLine,
+	"logfmt":   parseLogfmtLine,
+	"syslog":   parseSyslogLine,
+	"nginx":    parseNginxLine,
+	"plain":    parsePlainLine,
+}
+
+// Formats returns the names of the registered line formats, sorted
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseFormat normalizes a format name, reporting unknown ones. An empty
+// name selects auto-detection.
+func ParseFormat(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return FormatAuto, nil
+	}
+	if _, ok := formats[name]; !ok {
+		return "", fmt.Errorf("unknown format %q (known: %s)", name, strings.Join(Formats(), ", "))
+	}
+	return name, nil
+}
+
+// lookupFormat returns the parser for name, auto-detection for an empty or
+// unknown name
+func lookupFormat(name string) LineFormat {
+	if format, ok := formats[name]; ok {
+		return format
+	}
+	return formats[FormatAuto]
+}
+
+// parseJSONLine parses a line that is a single JSON object
+func parseJSONLine(p *Parser, line string) map[string]interface{} {
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &data); err != nil {
+		return nil
+	}
+	return p.normalizeJSONFields(data)
+}
+
+// parseLogfmtLine parses key=value pairs, values optionally double quoted,
+// as written by logrus, slog's text handler and many Go services
+func parseLogfmtLine(p *Parser, line string) map[string]interface{} {
+	data := make(map[string]interface{})
+	rest := strings.TrimSpace(line)
+
+	for rest != "" {
+		eq := strings.IndexByte(rest, '=')
+		space := strings.IndexByte(rest, ' ')
+		if eq <= 0 || (space >= 0 && space < eq) {
+			// A bare word, logfmt allows it as a key without value
+			if space < 0 {
+				data[rest] = true
+				break
+			}
+			data[rest[:space]] = true
+			rest = strings.TrimLeft(rest[space:], " ")
+			continue
+		}
+
+		key := rest[:eq]
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := closingQuote(rest)
+			if end < 0 {
+				return nil
+			}
+			unquoted, err := strconv.Unquote(rest[:end+1])
+			if err != nil {
+				return nil
+			}
+			value, rest = unquoted, rest[end+1:]
+		} else if space := strings.IndexByte(rest, ' '); space >= 0 {
+			value, rest = rest[:space], rest[space:]
+		} else {
+			value, rest = rest, ""
+		}
+
+		data[key] = value
+		rest = strings.TrimLeft(rest, " ")
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+	return p.normalizeJSONFields(data)
+}
+
+// closingQuote returns the index of the quote closing the one s starts
+// with, skipping escaped quotes, or -1
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+var (
+	// syslog5424Pattern matches RFC 5424: <PRI>1 TIMESTAMP HOST APP PROCID MSGID SD MSG
+	syslog5424Pattern = regexp.MustCompile(`^<(\d{1,3})>1 (\S+) (\S+) (\S+) (\S+) (\S+) (-|\[.*?\]) ?(.*)$`)
+
+	// syslog3164Pattern matches RFC 3164: [<PRI>]Mmm dd hh:mm:ss HOST TAG[PID]: MSG
+	syslog3164Pattern = regexp.MustCompile(`^(?:<(\d{1,3})>)?(\w{3} +\d{1,2} \d{2}:\d{2}:\d{2}) (\S+) ([^\s:\[]+)(?:\[(\d+)\])?: ?(.*)$`)
+)
+
+// syslogSeverities maps syslog severities 0-7 to built-in levels
+var syslogSeverities = []LogLevel{
+	LogLevelError, // emerg
+	LogLevelError, // alert
+	LogLevelError, // crit
+	LogLevelError, // err
+	LogLevelWarn,  // warning
+	LogLevelInfo,  // notice
+	LogLevelInfo,  // info
+	LogLevelDebug, // debug
+}
+
+// parseSyslogLine parses RFC 5424 and RFC 3164 syslog lines
+func parseSyslogLine(p *Parser, line string) map[string]interface{} {
+	result := make(map[string]interface{})
+	var pri string
+
+	if m := syslog5424Pattern.FindStringSubmatch(line); m != nil {
+		pri = m[1]
+		if ts, err := parseTime(time.RFC3339Nano, m[2]); err == nil {
+			result["timestamp"] = ts
+		}
+		setUnlessNil(result, "host", m[3])
+		setUnlessNil(result, "app", m[4])
+		setUnlessNil(result, "pid", m[5])
+		setUnlessNil(result, "msgid", m[6])
+		setUnlessNil(result, "structured_data", m[7])
+		result["message"] = m[8]
+	} else if m := syslog3164Pattern.FindStringSubmatch(line); m != nil {
+		pri = m[1]
+		if ts, err := parseTime(time.Stamp, m[2]); err == nil {
+			result["timestamp"] = withCurrentYear(ts)
+		}
+		result["host"] = m[3]
+		result["app"] = m[4]
+		setUnlessNil(result, "pid", m[5])
+		result["message"] = m[6]
+	} else {
+		return nil
+	}
+
+	if n, err := strconv.Atoi(pri); err == nil {
+		result["facility"] = n / 8
+		result["level"] = string(syslogSeverities[n%8])
+	}
+	return result
+}
+
+// setUnlessNil sets key unless value is empty or syslog's "-" nil value
+func setUnlessNil(result map[string]interface{}, key, value string) {
+	if value != "" && value != "-" {
+		result[key] = value
+	}
+}
+
+var (
+	// nginxAccessPattern matches the combined access log format
+	nginxAccessPattern = regexp.MustCompile(`^(\S+) \S+ (\S+) \[([^\]]+)\] "([^"]*)" (\d{3}) (\d+|-)(?: "([^"]*)" "([^"]*)")?`)
+
+	// nginxErrorPattern matches the error log format
+	nginxErrorPattern = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}) \[(\w+)\] (\d+)#\d+: (?:\*\d+ )?(.*)$`)
+)
+
+// nginxErrorLevels maps nginx error log severities to built-in levels
+var nginxErrorLevels = map[string]LogLevel{
+	"emerg":  LogLevelError,
+	"alert":  LogLevelError,
+	"crit":   LogLevelError,
+	"error":  LogLevelError,
+	"warn":   LogLevelWarn,
+	"notice": LogLevelInfo,
+	"info":   LogLevelInfo,
+	"debug":  LogLevelDebug,
+}
+
+// parseNginxLine parses nginx access lines in the combined format and
+// nginx error log lines. Access lines are leveled by status: 5xx as
+// errors, 4xx as warnings.
+func parseNginxLine(p *Parser, line string) map[string]interface{} {
+	if m := nginxAccessPattern.FindStringSubmatch(line); m != nil {
+		result := map[string]interface{}{
+			"remote_addr": m[1],
+			"request":     m[4],
+			"message":     m[4] + " " + m[5],
+		}
+		if ts, err := parseTime(clfLayout, m[3]); err == nil {
+			result["timestamp"] = ts
+		}
+		setUnlessNil(result, "remote_user", m[2])
+		setUnlessNil(result, "referer", m[7])
+		setUnlessNil(result, "user_agent", m[8])
+
+		status, _ := strconv.Atoi(m[5])
+		result["status"] = status
+		if bytes, err := strconv.Atoi(m[6]); err == nil {
+			result["body_bytes"] = bytes
+		}
+
+		switch {
+		case status >= 500:
+			result["level"] = string(LogLevelError)
+		case status >= 400:
+			result["level"] = string(LogLevelWarn)
+		default:
+			result["level"] = string(LogLevelInfo)
+		}
+		return result
+	}
+
+	if m := nginxErrorPattern.FindStringSubmatch(line); m != nil {
+		result := map[string]interface{}{
+			"pid":     m[3],
+			"message": m[4],
+		}
+		if ts, err := parseTime("2006/01/02 15:04:05", m[1]); err == nil {
+			result["timestamp"] = ts
+		}
+		if level, ok := nginxErrorLevels[m[2]]; ok {
+			result["level"] = string(level)
+		}
+		return result
+	}
+
+	return nil
+}
+
+// parsePlainLine only looks for a timestamp, leaving the line as its
+// message
+func parsePlainLine(p *Parser, line string) map[string]interface{} {
+	result := make(map[string]interface{})
+	if ts, ok := p.parsePlainTimestamp(line); ok {
+		result["timestamp"] = ts
+	}
+	return result
+}
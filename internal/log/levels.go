@@ -0,0 +1,173 @@
+// internal/log/levels.go
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LevelDef describes a log level in the level registry
+type LevelDef struct {
+	Name LogLevel `json:"name"`
+
+	// Patterns are substrings that mark a raw line as this level, matched
+	// case-insensitively
+	Patterns []string `json:"patterns"`
+
+	// Color is an ANSI 256 color number such as "9", empty for no color
+	Color string `json:"color"`
+}
+
+// LevelConfig is the on-disk form of the level registry
+type LevelConfig struct {
+	// Levels run from least to most severe
+	Levels []LevelDef `json:"levels"`
+
+	// Default is the level of lines no pattern matches, INFO if omitted
+	Default LogLevel `json:"default"`
+}
+
+// defaultLevels are the built-in levels, least severe first
+var defaultLevels = []LevelDef{
+	{Name: LogLevelDebug, Patterns: []string{"DEBUG", "DBG"}, Color: "8"},
+	{Name: LogLevelInfo, Patterns: []string{"INFO", "INFORMATION"}, Color: "12"},
+	{Name: LogLevelWarn, Patterns: []string{"WARN", "WARNING"}, Color: "11"},
+	{Name: LogLevelError, Patterns: []string{"ERROR", "ERR"}, Color: "9"},
+}
+
+// The registry is set up once at startup, before any entries are parsed,
+// and only read afterwards
+var (
+	levels       = defaultLevels
+	levelOrder   = rankLevels(defaultLevels)
+	defaultLevel = LogLevelInfo
+)
+
+// rankLevels maps each level to its position in defs
+func rankLevels(defs []LevelDef) map[LogLevel]int {
+	order := make(map[LogLevel]int, len(defs))
+	for i, def := range defs {
+		order[def.Name] = i
+	}
+	return order
+}
+
+// SetLevels replaces the level registry. defs run from least to most
+// severe; fallback is the level of lines no pattern matches and must be
+// one of defs. Call it at startup, before any entries are parsed.
+func SetLevels(defs []LevelDef, fallback LogLevel) error {
+	if len(defs) == 0 {
+		return fmt.Errorf("no levels defined")
+	}
+
+	normalized := make([]LevelDef, len(defs))
+	for i, def := range defs {
+		name := LogLevel(strings.ToUpper(strings.TrimSpace(string(def.Name))))
+		if name == "" {
+			return fmt.Errorf("level %d has no name", i+1)
+		}
+
+		patterns := make([]string, 0, len(def.Patterns))
+		for _, pattern := range def.Patterns {
+			if pattern = strings.ToUpper(pattern); pattern != "" {
+				patterns = append(patterns, pattern)
+			}
+		}
+		normalized[i] = LevelDef{Name: name, Patterns: patterns, Color: def.Color}
+	}
+
+	order := rankLevels(normalized)
+	if len(order) != len(normalized) {
+		return fmt.Errorf("duplicate level names")
+	}
+
+	fallback = LogLevel(strings.ToUpper(strings.TrimSpace(string(fallback))))
+	if _, ok := order[fallback]; !ok {
+		return fmt.Errorf("default level %q is not defined", fallback)
+	}
+
+	levels = normalized
+	levelOrder = order
+	defaultLevel = fallback
+	return nil
+}
+
+// LoadLevels replaces the level registry with the levels in the JSON file
+// at path
+func LoadLevels(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read level config: %w", err)
+	}
+
+	var config LevelConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("failed to parse level config: %w", err)
+	}
+	if config.Default == "" {
+		config.Default = LogLevelInfo
+	}
+
+	if err := SetLevels(config.Levels, config.Default); err != nil {
+		return fmt.Errorf("invalid level config: %w", err)
+	}
+	return nil
+}
+
+// Levels returns the registered levels, least severe first
+func Levels() []LevelDef {
+	return levels
+}
+
+// LevelWidth returns the length of the longest registered level name, the
+// width level badges are padded to
+func LevelWidth() int {
+	width := 0
+	for _, def := range levels {
+		width = max(width, len(def.Name))
+	}
+	return width
+}
+
+// LowestLevel returns the least severe registered level, which lets every
+// entry through a filter
+func LowestLevel() LogLevel {
+	return levels[0].Name
+}
+
+// LevelColor returns the ANSI 256 color number registered for level, or an
+// empty string when it has none
+func LevelColor(level LogLevel) string {
+	if rank, ok := levelOrder[level]; ok {
+		return levels[rank].Color
+	}
+	return ""
+}
+
+// LevelRank returns the position of level in the registry, least severe
+// first, or -1 if it is not registered
+func LevelRank(level LogLevel) int {
+	if rank, ok := levelOrder[level]; ok {
+		return rank
+	}
+	return -1
+}
+
+// ResolveLevel returns name if it is registered, otherwise the level
+// fromTop places below the most severe one. Keys and checks written for
+// the built-in levels, such as showing errors only, then still pick a
+// sensible level of a custom scheme.
+func ResolveLevel(name LogLevel, fromTop int) LogLevel {
+	if IsKnownLevel(name) {
+		return name
+	}
+	return levels[max(0, len(levels)-1-fromTop)].Name
+}
+
+// IsKnownLevel reports whether level is in the registry
+func IsKnownLevel(level LogLevel) bool {
+	_, ok := levelOrder[level]
+	return ok
+}
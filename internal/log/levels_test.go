@@ -0,0 +1,97 @@
+// internal/log/levels_test.go
+package log
+
+import "testing"
+
+// withLevels installs a level registry for the test, restoring the
+// built-in one after it
+func withLevels(t *testing.T, defs []LevelDef, fallback LogLevel) {
+	t.Helper()
+	if err := SetLevels(defs, fallback); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetLevels(defaultLevels, LogLevelInfo) })
+}
+
+// customLevels is a scheme without the built-in WARN and ERROR
+var customLevels = []LevelDef{
+	{Name: "TRACE", Patterns: []string{"TRACE"}},
+	{Name: "NOTICE", Patterns: []string{"NOTICE"}},
+	{Name: "ALERT", Patterns: []string{"ALERT"}},
+	{Name: "FATAL", Patterns: []string{"FATAL"}},
+}
+
+func TestAtLeast(t *testing.T) {
+	tests := []struct {
+		level, min LogLevel
+		want       bool
+	}{
+		{LogLevelError, LogLevelWarn, true},
+		{LogLevelWarn, LogLevelWarn, true},
+		{LogLevelInfo, LogLevelWarn, false},
+		{LogLevelDebug, LogLevelDebug, true},
+		// Unknown entry levels rank with the least severe level
+		{"NOTICE", LogLevelDebug, true},
+		{"NOTICE", LogLevelInfo, false},
+		// An unknown minimum matches nothing
+		{LogLevelError, "FATAL", false},
+		{"FATAL", "FATAL", false},
+		{LogLevelError, "", false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.level.AtLeast(tt.min); got != tt.want {
+			t.Errorf("%q.AtLeast(%q) = %v, want %v", tt.level, tt.min, got, tt.want)
+		}
+	}
+}
+
+func TestFilterUnknownLevel(t *testing.T) {
+	b := fullBuffer(20)
+	if got := b.Filter("FATAL"); got != nil {
+		t.Errorf("Filter(FATAL) returned %d entries, want none", len(got))
+	}
+	if got := b.FilterWithContext("FATAL", 3); got != nil {
+		t.Errorf("FilterWithContext(FATAL) returned %d entries, want none", len(got))
+	}
+	if got := b.Filter(LowestLevel()); len(got) != 20 {
+		t.Errorf("Filter(%s) returned %d entries, want all 20", LowestLevel(), len(got))
+	}
+}
+
+func TestResolveLevel(t *testing.T) {
+	if got := ResolveLevel(LogLevelWarn, 1); got != LogLevelWarn {
+		t.Errorf("built-in ResolveLevel(WARN, 1) = %s", got)
+	}
+
+	withLevels(t, customLevels, "NOTICE")
+	tests := []struct {
+		name    LogLevel
+		fromTop int
+		want    LogLevel
+	}{
+		{LogLevelError, 0, "FATAL"},
+		{LogLevelWarn, 1, "ALERT"},
+		{LogLevelInfo, 2, "NOTICE"},
+		{LogLevelDebug, 3, "TRACE"},
+		{LogLevelDebug, 9, "TRACE"},
+		{"NOTICE", 0, "NOTICE"},
+	}
+	for _, tt := range tests {
+		if got := ResolveLevel(tt.name, tt.fromTop); got != tt.want {
+			t.Errorf("ResolveLevel(%s, %d) = %s, want %s", tt.name, tt.fromTop, got, tt.want)
+		}
+	}
+}
+
+func TestLevelRank(t *testing.T) {
+	withLevels(t, customLevels, "NOTICE")
+	for i, def := range customLevels {
+		if got := LevelRank(def.Name); got != i {
+			t.Errorf("LevelRank(%s) = %d, want %d", def.Name, got, i)
+		}
+	}
+	if got := LevelRank(LogLevelError); got != -1 {
+		t.Errorf("LevelRank of an unregistered level = %d, want -1", got)
+	}
+}
@@ -0,0 +1,77 @@
+// internal/log/merge.go
+package log
+
+import "container/heap"
+
+// Merge interleaves per-source entry streams into one stream ordered by
+// timestamp. Each stream must be in arrival order, as returned by
+// Buffer.GetAll, and keeps that order in the result even when its
+// timestamps are coarse or run backwards. Entries with equal timestamps
+// are ordered by the position of their stream in streams, then by Seq, so
+// the result is the same on every call.
+func Merge(streams ...[]LogEntry) []LogEntry {
+	total := 0
+	h := make(mergeHeap, 0, len(streams))
+	for i, stream := range streams {
+		total += len(stream)
+		if len(stream) > 0 {
+			h = append(h, mergeCursor{entries: stream, stream: i})
+		}
+	}
+	heap.Init(&h)
+
+	merged := make([]LogEntry, 0, total)
+	for h.Len() > 0 {
+		cursor := &h[0]
+		merged = append(merged, cursor.entries[cursor.pos])
+
+		cursor.pos++
+		if cursor.pos == len(cursor.entries) {
+			heap.Pop(&h)
+		} else {
+			heap.Fix(&h, 0)
+		}
+	}
+
+	return merged
+}
+
+// mergeCursor is the read position in one stream being merged
+type mergeCursor struct {
+	entries []LogEntry
+	pos     int
+	stream  int
+}
+
+// head returns the next entry of the stream
+func (c mergeCursor) head() LogEntry {
+	return c.entries[c.pos]
+}
+
+// mergeHeap orders stream cursors by their next entry
+type mergeHeap []mergeCursor
+
+func (h mergeHeap) Len() int { return len(h) }
+
+func (h mergeHeap) Less(i, j int) bool {
+	a, b := h[i].head(), h[j].head()
+	if !a.Timestamp.Equal(b.Timestamp) {
+		return a.Timestamp.Before(b.Timestamp)
+	}
+	if h[i].stream != h[j].stream {
+		return h[i].stream < h[j].stream
+	}
+	return a.Seq < b.Seq
+}
+
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *mergeHeap) Push(x interface{}) { *h = append(*h, x.(mergeCursor)) }
+
+func (h *mergeHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	cursor := old[n-1]
+	*h = old[:n-1]
+	return cursor
+}
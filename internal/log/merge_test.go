@@ -0,0 +1,98 @@
+// internal/log/merge_test.go
+package log
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// mergeBase is the time the seconds in stream are counted from
+var mergeBase = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// stream builds one source's entries in arrival order from "content@second"
+// pairs, numbering their Seq as a buffer would
+func stream(specs ...string) []LogEntry {
+	var entries []LogEntry
+	for i, spec := range specs {
+		content, second, _ := strings.Cut(spec, "@")
+		offset, err := time.ParseDuration(second + "s")
+		if err != nil {
+			panic(err)
+		}
+		entries = append(entries, LogEntry{
+			Timestamp: mergeBase.Add(offset),
+			Content:   content,
+			Seq:       uint64(i + 1),
+		})
+	}
+	return entries
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name    string
+		streams [][]LogEntry
+		want    string
+	}{
+		{name: "no streams"},
+		{name: "nil stream", streams: [][]LogEntry{nil}},
+		{name: "empty streams", streams: [][]LogEntry{{}, {}}},
+		{
+			name:    "nil and empty around a stream",
+			streams: [][]LogEntry{nil, stream("a1@1", "a2@2"), {}},
+			want:    "a1,a2",
+		},
+		{
+			name:    "interleaved by timestamp",
+			streams: [][]LogEntry{stream("a1@1", "a2@3"), stream("b1@2", "b2@4")},
+			want:    "a1,b1,a2,b2",
+		},
+		{
+			name:    "equal timestamps order by stream index",
+			streams: [][]LogEntry{stream("a1@1", "a2@2"), stream("b1@1", "b2@2"), stream("c1@1")},
+			want:    "a1,b1,c1,a2,b2",
+		},
+		{
+			name:    "stream index before seq on equal timestamps",
+			streams: [][]LogEntry{stream("a0@0", "a1@5"), stream("b1@5")},
+			want:    "a0,a1,b1",
+		},
+		{
+			name:    "coarse timestamps keep arrival order",
+			streams: [][]LogEntry{stream("a1@1", "a2@1", "a3@1"), stream("b1@1")},
+			want:    "a1,a2,a3,b1",
+		},
+		{
+			name:    "backwards timestamps keep arrival order",
+			streams: [][]LogEntry{stream("a1@3", "a2@1"), stream("b1@2")},
+			want:    "b1,a1,a2",
+		},
+		{
+			name:    "backwards timestamps in every stream",
+			streams: [][]LogEntry{stream("a1@4", "a2@2"), stream("b1@3", "b2@1")},
+			want:    "b1,b2,a1,a2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := Merge(tt.streams...)
+			var got []string
+			for _, entry := range merged {
+				got = append(got, entry.Content)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("Merge = %s, want %s", strings.Join(got, ","), tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeLeavesStreamsAlone(t *testing.T) {
+	a, b := stream("a1@2", "a2@1"), stream("b1@1")
+	Merge(a, b)
+	if a[0].Content != "a1" || a[1].Content != "a2" || b[0].Content != "b1" {
+		t.Errorf("Merge reordered its input: %v %v", a, b)
+	}
+}
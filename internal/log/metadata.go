@@ -0,0 +1,134 @@
+// internal/log/metadata.go
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultMetadataDepth is how many levels of nested metadata are flattened
+// into dotted keys before the rest is shown as compact JSON
+const DefaultMetadataDepth = 3
+
+// metadataTruncatedKey records how many fields CapMetadata dropped
+const metadataTruncatedKey = "metadata_truncated"
+
+// MetadataField is a single flattened metadata value
+type MetadataField struct {
+	Key   string
+	Value string
+}
+
+// FlattenMetadata flattens nested metadata into dotted keys such as
+// "http.request.method", sorted by key. Objects nested deeper than
+// maxDepth, and arrays, are rendered as compact JSON. A maxDepth of zero
+// or less means no limit.
+func FlattenMetadata(meta map[string]interface{}, maxDepth int) []MetadataField {
+	var fields []MetadataField
+	flattenInto(&fields, "", meta, 1, maxDepth)
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Key < fields[j].Key
+	})
+	return fields
+}
+
+// flattenInto appends the fields of obj under prefix, depth being the
+// nesting level of obj itself
+func flattenInto(fields *[]MetadataField, prefix string, obj map[string]interface{}, depth, maxDepth int) {
+	for k, v := range obj {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 && (maxDepth <= 0 || depth < maxDepth) {
+			flattenInto(fields, key, nested, depth+1, maxDepth)
+			continue
+		}
+		*fields = append(*fields, MetadataField{Key: key, Value: formatMetadataValue(v)})
+	}
+}
+
+// formatMetadataValue renders a metadata value for display. Strings are
+// shown bare, everything else as compact JSON so numbers, arrays and
+// objects never fall back to Go's %v syntax.
+func formatMetadataValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// FormatMetadata renders metadata as space-separated key=value pairs
+func FormatMetadata(meta map[string]interface{}, maxDepth int) string {
+	fields := FlattenMetadata(meta, maxDepth)
+
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field.Key + "=" + field.Value
+	}
+	return strings.Join(parts, " ")
+}
+
+// LookupMetadata renders the value at a dotted key such as "http.status",
+// walking nested objects. Keys that themselves contain dots, as left by
+// CapMetadata, are matched as they are.
+func LookupMetadata(meta map[string]interface{}, key string) (string, bool) {
+	if v, ok := meta[key]; ok {
+		return formatMetadataValue(v), true
+	}
+
+	for i := 0; i < len(key); i++ {
+		if key[i] != '.' {
+			continue
+		}
+		if nested, ok := meta[key[:i]].(map[string]interface{}); ok {
+			if value, ok := LookupMetadata(nested, key[i+1:]); ok {
+				return value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// CapMetadata bounds the JSON size of metadata to about maxBytes. Metadata
+// within the cap is returned unchanged. Larger metadata is flattened to
+// DefaultMetadataDepth and its fields kept in key order while they fit;
+// the number of dropped fields is recorded under "metadata_truncated".
+// A maxBytes of zero or less disables the cap.
+func CapMetadata(meta map[string]interface{}, maxBytes int) map[string]interface{} {
+	if maxBytes <= 0 || len(meta) == 0 {
+		return meta
+	}
+	if data, err := json.Marshal(meta); err == nil && len(data) <= maxBytes {
+		return meta
+	}
+
+	capped := make(map[string]interface{})
+	fields := FlattenMetadata(meta, DefaultMetadataDepth)
+	size, dropped := 2, 0 // The enclosing braces
+	for _, field := range fields {
+		key, _ := json.Marshal(field.Key)
+		value, _ := json.Marshal(field.Value)
+		fieldSize := len(key) + len(value) + 2 // Colon and comma
+		if size+fieldSize > maxBytes {
+			dropped++
+			continue
+		}
+		capped[field.Key] = field.Value
+		size += fieldSize
+	}
+
+	if dropped > 0 {
+		capped[metadataTruncatedKey] = dropped
+	}
+	return capped
+}
@@ -0,0 +1,133 @@
+// internal/log/metadata_test.go
+package log
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// nestedMetadata decodes metadata the way it arrives over IPC, with nested
+// objects as maps and numbers as float64
+func nestedMetadata(t *testing.T) map[string]interface{} {
+	t.Helper()
+	var meta map[string]interface{}
+	err := json.Unmarshal([]byte(`{
+		"http": {"method": "GET", "status": 200, "request": {"headers": {"accept": "*/*"}}},
+		"tags": ["a", "b"],
+		"user": "ana",
+		"empty": {}
+	}`), &meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return meta
+}
+
+func TestFlattenMetadata(t *testing.T) {
+	meta := nestedMetadata(t)
+
+	tests := []struct {
+		name     string
+		maxDepth int
+		want     string
+	}{
+		{
+			name:     "no limit",
+			maxDepth: 0,
+			want:     `empty={} http.method=GET http.request.headers.accept=*/* http.status=200 tags=["a","b"] user=ana`,
+		},
+		{
+			name:     "default depth",
+			maxDepth: DefaultMetadataDepth,
+			want:     `empty={} http.method=GET http.request.headers={"accept":"*/*"} http.status=200 tags=["a","b"] user=ana`,
+		},
+		{
+			name:     "top level only",
+			maxDepth: 1,
+			want:     `empty={} http={"method":"GET","request":{"headers":{"accept":"*/*"}},"status":200} tags=["a","b"] user=ana`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatMetadata(meta, tt.maxDepth); got != tt.want {
+				t.Errorf("FormatMetadata = %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataRoundTrip(t *testing.T) {
+	entry := LogEntry{Source: "api", Level: LogLevelInfo, Content: "done", Metadata: nestedMetadata(t)}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded LogEntry
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	want := FormatMetadata(entry.Metadata, DefaultMetadataDepth)
+	if got := FormatMetadata(decoded.Metadata, DefaultMetadataDepth); got != want {
+		t.Errorf("after a round trip metadata renders as %s\nwant %s", got, want)
+	}
+}
+
+func TestLookupMetadata(t *testing.T) {
+	meta := nestedMetadata(t)
+	meta["dotted.key"] = "kept"
+
+	tests := []struct {
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{"user", "ana", true},
+		{"http.status", "200", true},
+		{"http.request.headers.accept", "*/*", true},
+		{"http.request", `{"headers":{"accept":"*/*"}}`, true},
+		{"tags", `["a","b"]`, true},
+		{"dotted.key", "kept", true},
+		{"http.missing", "", false},
+		{"user.name", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := LookupMetadata(meta, tt.key)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("LookupMetadata(%q) = %q, %v, want %q, %v", tt.key, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestCapMetadata(t *testing.T) {
+	meta := nestedMetadata(t)
+
+	if got := CapMetadata(meta, 0); len(got) != len(meta) {
+		t.Errorf("a zero cap changed the metadata to %v", got)
+	}
+	if got := CapMetadata(meta, 1<<20); len(got) != len(meta) {
+		t.Errorf("metadata within the cap changed to %v", got)
+	}
+
+	capped := CapMetadata(meta, 60)
+	data, err := json.Marshal(capped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The truncation marker may take the size a little past the cap
+	if len(data) > 60+len(`,"metadata_truncated":9`) {
+		t.Errorf("capped metadata is %d bytes: %s", len(data), data)
+	}
+	if dropped, ok := capped[metadataTruncatedKey].(int); !ok || dropped == 0 {
+		t.Errorf("capped metadata does not record the dropped fields: %v", capped)
+	}
+	for key := range capped {
+		if key != metadataTruncatedKey && !strings.Contains(FormatMetadata(meta, DefaultMetadataDepth), key+"=") {
+			t.Errorf("capped metadata has a key %q the flattened original lacks", key)
+		}
+	}
+}
@@ -3,15 +3,48 @@ package log
 
 import (
 	"encoding/json"
+	"math"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// clfLayout is the Common Log Format timestamp used by Apache and nginx
+const clfLayout = "02/Jan/2006:15:04:05 -0700"
+
+// timeLayouts are the timestamp layouts tried for string timestamp fields
+var timeLayouts = []string{
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.000",
+	clfLayout,
+}
+
+// customTimeLayouts are user-supplied layouts tried after the built-in ones
+var customTimeLayouts []string
+
+// SetTimeLayouts registers additional time layouts for parsers created
+// afterwards, and for the shared one. It is meant to be called once at
+// startup.
+func SetTimeLayouts(layouts []string) {
+	customTimeLayouts = append([]string(nil), layouts...)
+	sharedParser = NewParser()
+}
+
+// sharedParser parses every line NewLogEntryWithOptions is given, so its
+// patterns are compiled once rather than per line. A Parser is not changed
+// after it is created, so it is safe to share between sources.
+var sharedParser = NewParser()
+
 // Parser handles parsing of log lines
 type Parser struct {
 	levelPatterns    []*regexp.Regexp
 	timestampPattern *regexp.Regexp
+	clfPattern       *regexp.Regexp
+	customLayouts    []string
 }
 
 // NewParser creates a new log parser
@@ -24,35 +57,35 @@ func NewParser() *Parser {
 			regexp.MustCompile(`(?i)\b(DEBUG|DBG)\b`),
 		},
 		timestampPattern: regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}`),
+		clfPattern:       regexp.MustCompile(`\[(\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4})\]`),
+		customLayouts:    customTimeLayouts,
 	}
 }
 
-// ParseLevel extracts the log level from a raw log line
+// ParseLevel extracts the log level from a raw log line, checking the
+// registered levels from most to least severe
 func (p *Parser) ParseLevel(line string) LogLevel {
-	upperLine := strings.ToUpper(line)
-
-	// Check for ERROR patterns
-	if strings.Contains(upperLine, "ERROR") || strings.Contains(upperLine, "ERR") {
-		return LogLevelError
+	if level, ok := p.matchLevel(line); ok {
+		return level
 	}
 
-	// Check for WARN patterns
-	if strings.Contains(upperLine, "WARN") || strings.Contains(upperLine, "WARNING") {
-		return LogLevelWarn
-	}
+	// Fall back to the default level if no level detected
+	return defaultLevel
+}
 
-	// Check for INFO patterns
-	if strings.Contains(upperLine, "INFO") || strings.Contains(upperLine, "INFORMATION") {
-		return LogLevelInfo
-	}
+// matchLevel returns the most severe registered level whose patterns
+// appear in s
+func (p *Parser) matchLevel(s string) (LogLevel, bool) {
+	upper := strings.ToUpper(s)
 
-	// Check for DEBUG patterns
-	if strings.Contains(upperLine, "DEBUG") || strings.Contains(upperLine, "DBG") {
-		return LogLevelDebug
+	for i := len(levels) - 1; i >= 0; i-- {
+		for _, pattern := range levels[i].Patterns {
+			if strings.Contains(upper, pattern) {
+				return levels[i].Name, true
+			}
+		}
 	}
-
-	// Default to INFO if no level detected
-	return LogLevelInfo
+	return "", false
 }
 
 // ParseStructured attempts to parse structured log formats (JSON, etc.)
@@ -65,17 +98,180 @@ func (p *Parser) ParseStructured(line string) map[string]interface{} {
 		}
 	}
 
+	// Then a JSON payload behind a prefix, such as a logger's timestamp
+	if prefix, jsonData, suffix, ok := extractJSONObject(line); ok {
+		result := p.normalizeJSONFields(jsonData)
+		if msg, ok := result["message"].(string); ok {
+			result["message"] = joinText(prefix, msg, suffix)
+		}
+		if _, ok := result["timestamp"]; !ok {
+			if ts, ok := p.parsePlainTimestamp(prefix); ok {
+				result["timestamp"] = ts
+			}
+		}
+		return result
+	}
+
 	// Try to extract timestamp using regex
 	result := make(map[string]interface{})
+	if ts, ok := p.parsePlainTimestamp(line); ok {
+		result["timestamp"] = ts
+	}
+
+	return result
+}
+
+// parsePlainTimestamp finds a timestamp in a line that is not JSON
+func (p *Parser) parsePlainTimestamp(line string) (time.Time, bool) {
 	if match := p.timestampPattern.FindString(line); match != "" {
-		if ts, err := time.Parse("2006-01-02 15:04:05", match); err == nil {
-			result["timestamp"] = ts
-		} else if ts, err := time.Parse("2006-01-02T15:04:05", match); err == nil {
-			result["timestamp"] = ts
+		if ts, err := parseTime("2006-01-02 15:04:05", match); err == nil {
+			return ts, true
+		} else if ts, err := parseTime("2006-01-02T15:04:05", match); err == nil {
+			return ts, true
 		}
+	} else if match := p.clfPattern.FindStringSubmatch(line); match != nil {
+		if ts, err := parseTime(clfLayout, match[1]); err == nil {
+			return ts, true
+		}
+	} else if ts, ok := p.parseLeadingTimestamp(line); ok {
+		return ts, true
 	}
+	return time.Time{}, false
+}
 
-	return result
+// maxJSONCandidates bounds how many opening braces extractJSONObject tries,
+// so lines full of stray braces stay cheap
+const maxJSONCandidates = 4
+
+// extractJSONObject finds the first balanced {...} in line that parses as
+// a non-empty JSON object and returns it with the text around it. Braces
+// that do not open a valid object, like "{}" or "took {n}ms", are skipped.
+func extractJSONObject(line string) (prefix string, object map[string]interface{}, suffix string, ok bool) {
+	offset := 0
+	for tries := 0; tries < maxJSONCandidates; tries++ {
+		start := strings.IndexByte(line[offset:], '{')
+		if start < 0 {
+			return "", nil, "", false
+		}
+		start += offset
+
+		if end, balanced := matchBrace(line, start); balanced {
+			var data map[string]interface{}
+			if err := json.Unmarshal([]byte(line[start:end+1]), &data); err == nil && len(data) > 0 {
+				return line[:start], data, line[end+1:], true
+			}
+		}
+		offset = start + 1
+	}
+	return "", nil, "", false
+}
+
+// matchBrace returns the index of the brace closing the one at start,
+// ignoring braces inside JSON strings
+func matchBrace(line string, start int) (int, bool) {
+	depth := 0
+	inString := false
+	escaped := false
+
+	for i := start; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// joinText joins the non-empty trimmed parts with single spaces
+func joinText(parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// parseLeadingTimestamp tries the custom layouts against the first few
+// space-separated fields of a plain line
+func (p *Parser) parseLeadingTimestamp(line string) (time.Time, bool) {
+	if len(p.customLayouts) == 0 {
+		return time.Time{}, false
+	}
+
+	fields := strings.Fields(line)
+	for n := 1; n <= 4 && n <= len(fields); n++ {
+		candidate := strings.Trim(strings.Join(fields[:n], " "), "[]")
+		for _, layout := range p.customLayouts {
+			if ts, err := parseTime(layout, candidate); err == nil {
+				return withCurrentYear(ts), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseTimestamp converts a JSON timestamp value, either a formatted
+// string or a numeric epoch, into a time
+func (p *Parser) parseTimestamp(val interface{}) (time.Time, bool) {
+	switch v := val.(type) {
+	case float64:
+		return parseEpoch(v), true
+	case string:
+		for _, layout := range timeLayouts {
+			if ts, err := parseTime(layout, v); err == nil {
+				return ts, true
+			}
+		}
+		for _, layout := range p.customLayouts {
+			if ts, err := parseTime(layout, v); err == nil {
+				return withCurrentYear(ts), true
+			}
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return parseEpoch(f), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// withCurrentYear fills in the year for layouts that omit it, like syslog's
+func withCurrentYear(ts time.Time) time.Time {
+	if ts.Year() != 0 {
+		return ts
+	}
+	return ts.AddDate(time.Now().Year(), 0, 0)
+}
+
+// parseEpoch interprets a numeric timestamp, telling seconds, milliseconds,
+// microseconds and nanoseconds apart by magnitude
+func parseEpoch(v float64) time.Time {
+	abs := math.Abs(v)
+	switch {
+	case abs < 1e11: // seconds, good until the year 5138
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(frac*1e9))
+	case abs < 1e14:
+		return time.UnixMilli(int64(v))
+	case abs < 1e17:
+		return time.UnixMicro(int64(v))
+	default:
+		return time.Unix(0, int64(v))
+	}
 }
 
 // normalizeJSONFields normalizes common JSON log field names
@@ -86,21 +282,8 @@ func (p *Parser) normalizeJSONFields(data map[string]interface{}) map[string]int
 	timestampFields := []string{"timestamp", "ts", "time", "@timestamp", "datetime"}
 	for _, field := range timestampFields {
 		if val, ok := data[field]; ok {
-			if timeStr, ok := val.(string); ok {
-				// Try common timestamp formats
-				formats := []string{
-					time.RFC3339,
-					time.RFC3339Nano,
-					"2006-01-02 15:04:05",
-					"2006-01-02T15:04:05",
-					"2006-01-02 15:04:05.000",
-				}
-				for _, format := range formats {
-					if ts, err := time.Parse(format, timeStr); err == nil {
-						result["timestamp"] = ts
-						break
-					}
-				}
+			if ts, ok := p.parseTimestamp(val); ok {
+				result["timestamp"] = ts
 			}
 			break
 		}
@@ -0,0 +1,209 @@
+// internal/log/parser_test.go
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+// mixedLines are representative feeder input: JSON, JSON behind a prefix
+// and plain text
+var mixedLines = []string{
+	`{"time":"2024-05-01T12:00:00Z","level":"info","msg":"request handled","path":"/api/users","status":200}`,
+	`2024-05-01 12:00:01 INFO starting worker pool size=8`,
+	`{"ts":1714564802.5,"level":"error","message":"upstream timeout","upstream":{"host":"db","port":5432}}`,
+	`2024-05-01T12:00:03 WARN {"msg":"slow query","duration_ms":812}`,
+	`127.0.0.1 - - [01/May/2024:12:00:04 +0000] "GET /health HTTP/1.1" 200 2`,
+	`panic: runtime error: index out of range [3] with length 3`,
+	`DEBUG cache miss key=user:42`,
+}
+
+func BenchmarkParseLevel(b *testing.B) {
+	p := NewParser()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.ParseLevel(mixedLines[i%len(mixedLines)])
+	}
+}
+
+func BenchmarkParseStructured(b *testing.B) {
+	p := NewParser()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.ParseStructured(mixedLines[i%len(mixedLines)])
+	}
+}
+
+// BenchmarkNewLogEntry is the per-line cost of a feeder, parser included
+func BenchmarkNewLogEntry(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		NewLogEntry("api", mixedLines[i%len(mixedLines)])
+	}
+}
+
+func TestParseEpochMagnitudes(t *testing.T) {
+	want := time.Date(2024, 5, 1, 12, 0, 0, 500_000_000, time.UTC)
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{"seconds", 1714564800.5},
+		{"seconds as a string", "1714564800.5"},
+		{"milliseconds", float64(1714564800500)},
+		{"microseconds", float64(1714564800500000)},
+		{"nanoseconds", float64(1714564800500000000)},
+	}
+
+	p := NewParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := p.parseTimestamp(tt.value)
+			if !ok {
+				t.Fatalf("parseTimestamp(%v) failed", tt.value)
+			}
+			// Floats lose sub-microsecond precision at these magnitudes
+			if diff := got.Sub(want); diff < -time.Microsecond || diff > time.Microsecond {
+				t.Errorf("parseTimestamp(%v) = %v, want %v", tt.value, got.UTC(), want)
+			}
+		})
+	}
+}
+
+func TestParseTimestampLayouts(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+	}{
+		{"RFC 3339", "2024-05-01T12:00:00Z", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"RFC 3339 with an offset", "2024-05-01T14:00:00+02:00", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"RFC 3339 with nanoseconds", "2024-05-01T12:00:00.123456789Z", time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)},
+		{"Common Log Format", "01/May/2024:08:00:00 -0400", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+	}
+
+	p := NewParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := p.parseTimestamp(tt.value)
+			if !ok {
+				t.Fatalf("parseTimestamp(%q) failed", tt.value)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseTimestamp(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCommonLogFormatLine(t *testing.T) {
+	p := NewParser()
+	result := p.ParseStructured(`127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`)
+
+	want := time.Date(2000, 10, 10, 20, 55, 36, 0, time.UTC)
+	if ts, ok := result["timestamp"].(time.Time); !ok || !ts.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", result["timestamp"], want)
+	}
+}
+
+func TestCustomTimeLayouts(t *testing.T) {
+	SetTimeLayouts([]string{"2006/01/02 15:04:05.000 -0700", "Jan _2 15:04:05"})
+	defer SetTimeLayouts(nil)
+	p := NewParser()
+
+	got, ok := p.parseTimestamp("2024/05/01 14:00:00.250 +0200")
+	want := time.Date(2024, 5, 1, 12, 0, 0, 250_000_000, time.UTC)
+	if !ok || !got.Equal(want) {
+		t.Errorf("custom layout parsed to %v, %v, want %v", got, ok, want)
+	}
+
+	// A layout without a year gets the current one
+	got, ok = p.parseTimestamp("May  1 12:00:00")
+	if !ok || got.Year() != time.Now().Year() || got.Month() != time.May || got.Hour() != 12 {
+		t.Errorf("yearless layout parsed to %v, %v", got, ok)
+	}
+
+	// Custom layouts also find a timestamp leading a plain line
+	result := p.ParseStructured("2024/05/01 14:00:00.250 +0200 worker started")
+	if ts, ok := result["timestamp"].(time.Time); !ok || !ts.Equal(want) {
+		t.Errorf("leading timestamp = %v, want %v", result["timestamp"], want)
+	}
+
+	// Parsers created before the layouts were set do not use them
+	SetTimeLayouts(nil)
+	if _, ok := NewParser().parseTimestamp("2024/05/01 14:00:00.250 +0200"); ok {
+		t.Error("layout still used after it was unset")
+	}
+}
+
+func TestParseStructuredEmbeddedJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		wantMsg   interface{} // nil when no message should be extracted
+		wantField string      // A field the object carries, empty for none
+	}{
+		{
+			name:      "timestamp prefix",
+			line:      `2024-05-01 12:00:00 {"msg":"slow query","duration_ms":812}`,
+			wantMsg:   "2024-05-01 12:00:00 slow query",
+			wantField: "duration_ms",
+		},
+		{
+			name:      "text around the object",
+			line:      `worker-3: {"message":"job done","job":"resize"} (took 2s)`,
+			wantMsg:   "worker-3: job done (took 2s)",
+			wantField: "job",
+		},
+		{
+			name:      "braces inside strings",
+			line:      `api {"msg":"template {name} missing }","code":"E1"}`,
+			wantMsg:   "api template {name} missing }",
+			wantField: "code",
+		},
+		{
+			name:      "stray braces before the object",
+			line:      `took {n}ms for {} then {"msg":"ok","n":3}`,
+			wantMsg:   "took {n}ms for {} then ok",
+			wantField: "n",
+		},
+		{name: "placeholder only", line: `retrying in {delay} seconds`},
+		{name: "empty object", line: `response was {} again`},
+		{name: "unbalanced", line: `prefix {"msg":"cut off`},
+		{name: "not JSON inside", line: `set {a, b, c} resolved`},
+	}
+
+	p := NewParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := p.ParseStructured(tt.line)
+			if got := result["message"]; got != tt.wantMsg {
+				t.Errorf("message = %#v, want %#v", got, tt.wantMsg)
+			}
+			if tt.wantField != "" {
+				if _, ok := result[tt.wantField]; !ok {
+					t.Errorf("field %q missing from %v", tt.wantField, result)
+				}
+			}
+		})
+	}
+}
+
+func TestParseStructuredEmbeddedTimestamp(t *testing.T) {
+	SetParseLocation(time.UTC)
+	defer SetParseLocation(time.Local)
+
+	p := NewParser()
+	result := p.ParseStructured(`2024-05-01 12:00:00 {"msg":"no time of its own"}`)
+	want := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if ts, ok := result["timestamp"].(time.Time); !ok || !ts.Equal(want) {
+		t.Errorf("timestamp = %v, want the prefix's %v", result["timestamp"], want)
+	}
+
+	// The object's own timestamp wins over the prefix's
+	result = p.ParseStructured(`2024-05-01 12:00:00 {"msg":"x","ts":"2024-05-01T13:00:00Z"}`)
+	want = time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC)
+	if ts, ok := result["timestamp"].(time.Time); !ok || !ts.Equal(want) {
+		t.Errorf("timestamp = %v, want the object's %v", result["timestamp"], want)
+	}
+}
@@ -0,0 +1,111 @@
+// internal/log/spill.go
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// spill is the on-disk tier of a Buffer: entries evicted from memory are
+// appended to a JSON lines file so search and export can still reach them
+type spill struct {
+	path  string
+	file  *os.File // Nil until the next eviction after a reset
+	count int
+	err   error // First write failure, after which spilling stops
+}
+
+// openSpill creates or truncates the spill file at path
+func openSpill(path string) (*spill, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create spill directory: %w", err)
+	}
+
+	s := &spill{path: path}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open creates or truncates the spill file
+func (s *spill) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open spill file: %w", err)
+	}
+	s.file = file
+	return nil
+}
+
+// write appends an evicted entry. A failed write disables the tier rather
+// than holding up ingest.
+func (s *spill) write(entry LogEntry) {
+	if s.err != nil {
+		return
+	}
+	if s.file == nil {
+		if s.err = s.open(); s.err != nil {
+			return
+		}
+	}
+
+	data, err := json.Marshal(entry)
+	if err == nil {
+		_, err = s.file.Write(append(data, '\n'))
+	}
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.count++
+}
+
+// each calls fn for the first limit spilled entries, oldest first,
+// stopping at the first error fn returns. The file is only appended to, so
+// it can be read while later entries are being written.
+func (s *spill) each(limit int, fn func(LogEntry) error) error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read spill file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := limit; n > 0 && scanner.Scan(); n-- {
+		var entry LogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// reset closes and removes the spill file, the next eviction starts a new
+// one
+func (s *spill) reset() {
+	s.close()
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		s.err = err
+		return
+	}
+	s.count = 0
+	s.err = nil
+}
+
+// close closes the spill file, keeping what was spilled readable
+func (s *spill) close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,193 @@
+// internal/log/spill_test.go
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// spilledLines returns the contents of the entries a buffer kept on disk
+func spilledLines(t *testing.T, b *Buffer) []string {
+	t.Helper()
+	var lines []string
+	err := b.EachSpilled(func(entry LogEntry) error {
+		lines = append(lines, entry.Content)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
+
+// addLines adds entries first to last to b
+func addLines(b *Buffer, first, last int) {
+	for i := first; i <= last; i++ {
+		b.Add(LogEntry{Level: LogLevelInfo, Content: fmt.Sprintf("line %d", i)})
+	}
+}
+
+func checkLines(t *testing.T, what string, got, want []string) {
+	t.Helper()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("%s = %q, want %q", what, got, want)
+	}
+}
+
+func TestSpillKeepsEvictedEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spill", "api.jsonl")
+	b := NewBuffer(3)
+	if err := b.EnableSpill(path); err != nil {
+		t.Fatal(err)
+	}
+	addLines(b, 1, 7)
+
+	if b.Spilled() != 4 {
+		t.Errorf("Spilled = %d, want 4", b.Spilled())
+	}
+	checkLines(t, "spilled", spilledLines(t, b), wantLines(1, 4))
+	checkLines(t, "search", contents(b.Search("line")), wantLines(1, 7))
+}
+
+func TestSpillClearRemovesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.jsonl")
+	b := NewBuffer(2)
+	if err := b.EnableSpill(path); err != nil {
+		t.Fatal(err)
+	}
+	addLines(b, 1, 5)
+
+	b.Clear()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("spill file still there after Clear: %v", err)
+	}
+	if b.Spilled() != 0 || spilledLines(t, b) != nil {
+		t.Errorf("entries spilled before Clear are still reachable")
+	}
+
+	// The next eviction starts a new file
+	addLines(b, 6, 9)
+	checkLines(t, "spilled after Clear", spilledLines(t, b), wantLines(6, 7))
+}
+
+func TestSpillClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.jsonl")
+	b := NewBuffer(2)
+	if err := b.EnableSpill(path); err != nil {
+		t.Fatal(err)
+	}
+	addLines(b, 1, 4)
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Close(); err != nil {
+		t.Errorf("second Close = %v", err)
+	}
+
+	// What was spilled stays readable, later evictions are dropped
+	addLines(b, 5, 6)
+	checkLines(t, "spilled after Close", spilledLines(t, b), wantLines(1, 2))
+	checkLines(t, "in memory after Close", contents(b.GetAll()), wantLines(5, 6))
+}
+
+func TestSpillTruncatesOnEnable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.jsonl")
+	if err := os.WriteFile(path, []byte(`{"content":"stale"}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBuffer(1)
+	if err := b.EnableSpill(path); err != nil {
+		t.Fatal(err)
+	}
+	addLines(b, 1, 2)
+	checkLines(t, "spilled", spilledLines(t, b), wantLines(1, 1))
+}
+
+// contents lists the contents of entries
+func contents(entries []LogEntry) []string {
+	var lines []string
+	for _, entry := range entries {
+		lines = append(lines, entry.Content)
+	}
+	return lines
+}
+
+func TestEachEntryWithEvictionsDuringRead(t *testing.T) {
+	b := NewBuffer(3)
+	if err := b.EnableSpill(filepath.Join(t.TempDir(), "api.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	addLines(b, 1, 7)
+
+	// Lines 5 to 9 go to disk while the first spilled entry is being read,
+	// after the tiers were taken
+	var lines []string
+	err := b.EachEntry(func(entry LogEntry) error {
+		if len(lines) == 0 {
+			addLines(b, 8, 12)
+		}
+		lines = append(lines, entry.Content)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLines(t, "entries", lines, wantLines(1, 7))
+	checkLines(t, "entries read again", contents(b.Search("line")), wantLines(1, 12))
+}
+
+func TestSearchWhileEvicting(t *testing.T) {
+	b := NewBuffer(10)
+	if err := b.EnableSpill(filepath.Join(t.TempDir(), "api.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	addLines(b, 1, 20)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		addLines(b, 21, 2000)
+	}()
+
+	for searching := true; searching; {
+		select {
+		case <-done:
+			searching = false
+		default:
+		}
+
+		// Every entry up to the newest one found is there exactly once
+		matches := b.Search("line")
+		for i, entry := range matches {
+			if entry.Seq != uint64(i+1) {
+				t.Fatalf("search result %d has seq %d, entries went missing or repeated", i, entry.Seq)
+			}
+		}
+	}
+}
+
+func TestEachEntryUnreadableSpill(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.jsonl")
+	b := NewBuffer(2)
+	if err := b.EnableSpill(path); err != nil {
+		t.Fatal(err)
+	}
+	addLines(b, 1, 5)
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	err := b.EachEntry(func(entry LogEntry) error {
+		lines = append(lines, entry.Content)
+		return nil
+	})
+	if err == nil {
+		t.Error("EachEntry hid the spill file's read error")
+	}
+	checkLines(t, "entries", lines, wantLines(4, 5))
+}
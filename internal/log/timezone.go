@@ -0,0 +1,56 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// displayLocation is the time zone timestamps are shown in. Entries keep
+// their timestamps as parsed, only the display is converted.
+var displayLocation = time.Local
+
+// parseLocation is the time zone assumed for timestamps that do not carry
+// one, like "2006-01-02 15:04:05". Local by default, as that is what
+// applications writing such timestamps usually mean.
+var parseLocation = time.Local
+
+// ParseTimezone resolves "Local", "UTC" or an IANA zone name such as
+// "America/New_York"
+func ParseTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	switch strings.ToLower(name) {
+	case "", "local":
+		return time.Local, nil
+	case "utc":
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q, want Local, UTC or an IANA name like Europe/Berlin", name)
+	}
+	return loc, nil
+}
+
+// SetDisplayLocation sets the time zone timestamps are shown in
+func SetDisplayLocation(loc *time.Location) {
+	displayLocation = loc
+}
+
+// SetParseLocation sets the time zone assumed for timestamps without one.
+// It is meant to be called once at startup.
+func SetParseLocation(loc *time.Location) {
+	parseLocation = loc
+}
+
+// parseTime parses value with layout, placing it in the parse location
+// when it has no zone or offset of its own
+func parseTime(layout, value string) (time.Time, error) {
+	return time.ParseInLocation(layout, value, parseLocation)
+}
+
+// DisplayTime returns t in the time zone timestamps are shown in
+func DisplayTime(t time.Time) time.Time {
+	return t.In(displayLocation)
+}
@@ -0,0 +1,125 @@
+package log
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // America/New_York without the system zone database
+)
+
+// withParseLocation sets the zone assumed for zone-less timestamps for the
+// rest of the test
+func withParseLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetParseLocation(loc)
+	t.Cleanup(func() { SetParseLocation(time.Local) })
+	return loc
+}
+
+func TestZonelessTimestampsUseParseLocation(t *testing.T) {
+	ny := withParseLocation(t, "America/New_York")
+	p := NewParser()
+
+	// 12:00 in New York is 16:00 UTC during daylight saving time
+	want := time.Date(2024, 5, 1, 16, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"JSON field", `{"time":"2024-05-01 12:00:00","msg":"x"}`},
+		{"JSON field with T", `{"time":"2024-05-01T12:00:00","msg":"x"}`},
+		{"JSON field with milliseconds", `{"time":"2024-05-01 12:00:00.000","msg":"x"}`},
+		{"plain line", `2024-05-01 12:00:00 INFO started`},
+		{"prefix of embedded JSON", `2024-05-01 12:00:00 {"msg":"x"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, ok := p.ParseStructured(tt.line)["timestamp"].(time.Time)
+			if !ok {
+				t.Fatalf("no timestamp parsed from %s", tt.line)
+			}
+			if !ts.Equal(want) {
+				t.Errorf("parsed %v, want %v", ts.UTC(), want)
+			}
+			if ts.Location() != ny {
+				t.Errorf("parsed in %v, want %v", ts.Location(), ny)
+			}
+		})
+	}
+
+	// In winter the same wall clock time is an hour later in UTC
+	ts, _ := p.ParseStructured(`{"time":"2024-01-15 12:00:00"}`)["timestamp"].(time.Time)
+	if want := time.Date(2024, 1, 15, 17, 0, 0, 0, time.UTC); !ts.Equal(want) {
+		t.Errorf("winter timestamp parsed to %v, want %v", ts.UTC(), want)
+	}
+}
+
+func TestZonedTimestampsIgnoreParseLocation(t *testing.T) {
+	withParseLocation(t, "America/New_York")
+	p := NewParser()
+
+	want := time.Date(2000, 10, 10, 20, 55, 36, 0, time.UTC)
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"Common Log Format", `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.0" 200 2326`},
+		{"RFC 3339", `{"time":"2000-10-10T20:55:36Z"}`},
+		{"RFC 3339 with an offset", `{"time":"2000-10-10T22:55:36+02:00"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, ok := p.ParseStructured(tt.line)["timestamp"].(time.Time)
+			if !ok || !ts.Equal(want) {
+				t.Errorf("parsed %v, want %v", ts.UTC(), want)
+			}
+		})
+	}
+
+	result := parseNginxLine(p, `127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.0" 200 2326 "-" "curl"`)
+	if ts, ok := result["timestamp"].(time.Time); !ok || !ts.Equal(want) {
+		t.Errorf("nginx access timestamp = %v, want %v", result["timestamp"], want)
+	}
+}
+
+func TestSyslogStampUsesParseLocation(t *testing.T) {
+	ny := withParseLocation(t, "America/New_York")
+	p := NewParser()
+
+	// RFC 3164 stamps carry no year or zone: the year is filled in from
+	// year 0, whose New York offset is local mean time, not EST
+	result := parseSyslogLine(p, `<34>Oct 11 22:14:15 mymachine su: 'su root' failed`)
+	ts, ok := result["timestamp"].(time.Time)
+	if !ok {
+		t.Fatal("no timestamp parsed")
+	}
+	want := time.Date(time.Now().Year(), time.October, 11, 22, 14, 15, 0, ny)
+	if !ts.Equal(want) {
+		t.Errorf("parsed %v, want %v", ts, want)
+	}
+	if _, offset := ts.Zone(); offset != -4*3600 {
+		t.Errorf("parsed with offset %ds, want EDT's -14400s", offset)
+	}
+
+	// The same through withCurrentYear on a custom yearless layout
+	SetTimeLayouts([]string{"Jan _2 15:04:05"})
+	defer SetTimeLayouts(nil)
+	ts, ok = NewParser().parseTimestamp("Jan  5 08:00:00")
+	want = time.Date(time.Now().Year(), time.January, 5, 8, 0, 0, 0, ny)
+	if !ok || !ts.Equal(want) {
+		t.Errorf("yearless custom layout parsed to %v, want %v", ts, want)
+	}
+}
+
+func TestNginxErrorUsesParseLocation(t *testing.T) {
+	ny := withParseLocation(t, "America/New_York")
+
+	result := parseNginxLine(NewParser(), `2024/05/01 12:00:00 [error] 7#7: *1 open() failed`)
+	want := time.Date(2024, 5, 1, 12, 0, 0, 0, ny)
+	if ts, ok := result["timestamp"].(time.Time); !ok || !ts.Equal(want) {
+		t.Errorf("nginx error timestamp = %v, want %v", result["timestamp"], want)
+	}
+}
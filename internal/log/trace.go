@@ -0,0 +1,17 @@
+// internal/log/trace.go
+package log
+
+// DefaultTraceKeys are the metadata keys a trace or request ID is looked
+// up under, in order of preference
+var DefaultTraceKeys = []string{"trace_id", "traceId", "traceID", "request_id", "requestId", "req_id"}
+
+// TraceID returns the value of the first of keys, dotted for nested ones,
+// present and non-empty in an entry's metadata
+func TraceID(meta map[string]interface{}, keys []string) (string, bool) {
+	for _, key := range keys {
+		if id, ok := LookupMetadata(meta, key); ok && id != "" {
+			return id, true
+		}
+	}
+	return "", false
+}
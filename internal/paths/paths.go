@@ -0,0 +1,112 @@
+// internal/paths/paths.go
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/Yriskit-ai/logflow/internal/ipc"
+)
+
+// HomeEnv names the environment variable that, when set, puts every file
+// logflow keeps under one directory instead of the XDG locations
+const HomeEnv = "LOGFLOW_HOME"
+
+// home returns $LOGFLOW_HOME, empty when unset
+func home() string {
+	return os.Getenv(HomeEnv)
+}
+
+// ConfigDir returns the directory logflow reads its configuration from:
+// $LOGFLOW_HOME/config, else logflow under the user's config directory
+// ($XDG_CONFIG_HOME or ~/.config on Linux)
+func ConfigDir() (string, error) {
+	if dir := home(); dir != "" {
+		return filepath.Join(dir, "config"), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "logflow"), nil
+}
+
+// StateDir returns the directory logflow keeps state in across sessions:
+// $LOGFLOW_HOME/state, else logflow under $XDG_STATE_HOME or
+// ~/.local/state. Windows and macOS have no separate place for state, it
+// goes in the config directory there.
+func StateDir() (string, error) {
+	if dir := home(); dir != "" {
+		return filepath.Join(dir, "state"), nil
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "logflow"), nil
+	}
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return ConfigDir()
+	}
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ".local", "state", "logflow"), nil
+}
+
+// LevelsFile returns the custom levels file loaded when --levels is not
+// given, if it exists
+func LevelsFile() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "levels.json"), nil
+}
+
+// PresetsFile returns the file filter presets are saved to
+func PresetsFile() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "presets.json"), nil
+}
+
+// SearchHistoryFile returns the file search history persists to. History
+// kept in the config directory by earlier versions is moved over on first
+// use.
+func SearchHistoryFile() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "search_history")
+
+	if home() == "" {
+		if configDir, err := os.UserConfigDir(); err == nil {
+			legacy := filepath.Join(configDir, "logflow", "search_history")
+			if legacy != path && !exists(path) && exists(legacy) {
+				if os.MkdirAll(dir, 0755) != nil || os.Rename(legacy, path) != nil {
+					return legacy, nil
+				}
+			}
+		}
+	}
+	return path, nil
+}
+
+// Socket returns the address the dashboard listens on by default: a
+// socket in $LOGFLOW_HOME when set, so dashboards under different homes
+// don't meet, else ipc.SocketPath. Windows always uses the loopback port.
+func Socket() string {
+	if dir := home(); dir != "" && runtime.GOOS != "windows" {
+		return filepath.Join(dir, "logflow.sock")
+	}
+	return ipc.SocketPath
+}
+
+// exists reports whether a file is there
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,64 @@
+package sinks
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+)
+
+// ChannelSink delivers entries on a buffered channel, dropping them when the
+// reader falls behind so a slow consumer never stalls the sources
+type ChannelSink struct {
+	ch      chan log.LogEntry
+	closed  bool
+	dropped atomic.Uint64
+	mutex   sync.Mutex
+}
+
+// NewChannelSink creates a channel sink buffering up to size entries
+func NewChannelSink(size int) *ChannelSink {
+	return &ChannelSink{
+		ch: make(chan log.LogEntry, size),
+	}
+}
+
+// Entries returns the channel entries are delivered on
+func (c *ChannelSink) Entries() <-chan log.LogEntry {
+	return c.ch
+}
+
+// Write queues the entry, dropping it if the channel is full
+func (c *ChannelSink) Write(entry log.LogEntry) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	select {
+	case c.ch <- entry:
+	default:
+		// Channel full, drop message
+		c.dropped.Add(1)
+	}
+	return nil
+}
+
+// Dropped returns how many entries were dropped because the channel was full
+func (c *ChannelSink) Dropped() uint64 {
+	return c.dropped.Load()
+}
+
+// Close closes the entries channel
+func (c *ChannelSink) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.ch)
+	}
+	return nil
+}
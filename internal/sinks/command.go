@@ -0,0 +1,174 @@
+package sinks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+)
+
+// commandQueueSize is how many entries may wait on a slow command before
+// newer ones are dropped
+const commandQueueSize = 1000
+
+// commandStopTimeout is how long a command gets to exit after its stdin
+// closes before it is killed
+const commandStopTimeout = 2 * time.Second
+
+// shellCommand runs command through the platform's shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// CommandSink streams entries into a long-running command's stdin, one
+// line each in the --tee text form, so logs can be piped on to other
+// tools. Entries queue up for a slow command and are dropped once the
+// queue is full, so the command never holds up ingest. Its output is
+// discarded: it should write to a file or a tool of its own rather than
+// the terminal, which the dashboard owns.
+type CommandSink struct {
+	command  string
+	minLevel log.LogLevel
+	match    func(log.LogEntry) bool // Replaces the level check when set
+	stdin    io.WriteCloser
+	cancel   context.CancelFunc
+
+	mutex   sync.Mutex
+	closed  bool
+	queue   chan log.LogEntry
+	dropped atomic.Uint64
+
+	done chan struct{} // Closed once the command has exited
+	err  error         // Why the command exited, set before done closes
+}
+
+// NewCommandSink starts command through the shell and returns a sink
+// feeding it the entries at minLevel or above
+func NewCommandSink(command string, minLevel log.LogLevel) (*CommandSink, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := shellCommand(ctx, command)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to open command stdin: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+
+	c := &CommandSink{
+		command:  command,
+		minLevel: minLevel,
+		stdin:    stdin,
+		cancel:   cancel,
+		queue:    make(chan log.LogEntry, commandQueueSize),
+		done:     make(chan struct{}),
+	}
+	go c.feed()
+	go func() {
+		c.err = cmd.Wait()
+		cancel()
+		close(c.done)
+	}()
+	return c, nil
+}
+
+// Command returns the command entries are piped to
+func (c *CommandSink) Command() string {
+	return c.command
+}
+
+// SetFilter makes the sink pass on only the entries match accepts, in
+// place of its level check. It may be called again while entries flow, as
+// the filter it follows changes.
+func (c *CommandSink) SetFilter(match func(log.LogEntry) bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.match = match
+}
+
+// Write queues the entry for the command if it passes the sink's filter,
+// dropping it when the command has fallen too far behind
+func (c *CommandSink) Write(entry log.LogEntry) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return nil
+	}
+	if c.match != nil {
+		if !c.match(entry) {
+			return nil
+		}
+	} else if !entry.Level.AtLeast(c.minLevel) {
+		return nil
+	}
+	select {
+	case c.queue <- entry:
+	default:
+		c.dropped.Add(1)
+	}
+	return nil
+}
+
+// feed writes queued entries to the command until the queue is closed,
+// then closes its stdin so it sees the end of the stream. Once a write
+// fails, as when the command has exited, the rest are discarded.
+func (c *CommandSink) feed() {
+	failed := false
+	for entry := range c.queue {
+		if failed {
+			continue
+		}
+		if _, err := io.WriteString(c.stdin, textLine(entry)); err != nil {
+			failed = true
+		}
+	}
+	c.stdin.Close()
+}
+
+// Dropped returns how many entries were dropped because the command fell
+// behind
+func (c *CommandSink) Dropped() uint64 {
+	return c.dropped.Load()
+}
+
+// Done is closed once the command has exited
+func (c *CommandSink) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the command exited, once Done is closed
+func (c *CommandSink) Err() error {
+	return c.err
+}
+
+// Close stops taking entries and ends the command's input, killing it if
+// it has not exited commandStopTimeout later. It does not wait for that.
+func (c *CommandSink) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.queue)
+
+	go func() {
+		select {
+		case <-c.done:
+		case <-time.After(commandStopTimeout):
+			c.cancel()
+		}
+	}()
+	return nil
+}
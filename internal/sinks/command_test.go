@@ -0,0 +1,106 @@
+package sinks
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+)
+
+// startCat starts a sink whose command copies its input to a file, and
+// returns the sink and the file's path
+func startCat(t *testing.T, minLevel log.LogLevel) (*CommandSink, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the command is a POSIX shell pipeline")
+	}
+
+	path := filepath.Join(t.TempDir(), "out.log")
+	sink, err := NewCommandSink("cat > "+path, minLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sink.Close() })
+	return sink, path
+}
+
+// stop closes the sink and returns what its command wrote once it exits
+func stop(t *testing.T, sink *CommandSink, path string) []string {
+	t.Helper()
+	sink.Close()
+	select {
+	case <-sink.Done():
+	case <-time.After(commandStopTimeout + time.Second):
+		t.Fatal("the command did not exit")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contents []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line != "" {
+			contents = append(contents, line[strings.LastIndex(line, "] ")+2:])
+		}
+	}
+	return contents
+}
+
+func TestCommandSinkLevel(t *testing.T) {
+	sink, path := startCat(t, log.LogLevelWarn)
+	for _, level := range []log.LogLevel{log.LogLevelDebug, log.LogLevelWarn, log.LogLevelInfo, log.LogLevelError} {
+		sink.Write(log.LogEntry{Level: level, Source: "api", Content: string(level)})
+	}
+
+	if got := strings.Join(stop(t, sink, path), ","); got != "WARN,ERROR" {
+		t.Errorf("the command got %s, want WARN,ERROR", got)
+	}
+}
+
+func TestCommandSinkFilterChanges(t *testing.T) {
+	sink, path := startCat(t, log.LogLevelError)
+
+	// The filter replaces the level the sink started with
+	sink.SetFilter(func(entry log.LogEntry) bool { return strings.Contains(entry.Content, "timeout") })
+	sink.Write(log.LogEntry{Level: log.LogLevelInfo, Content: "timeout 1"})
+	sink.Write(log.LogEntry{Level: log.LogLevelError, Content: "refused"})
+
+	sink.SetFilter(func(entry log.LogEntry) bool { return entry.Level == log.LogLevelError })
+	sink.Write(log.LogEntry{Level: log.LogLevelInfo, Content: "timeout 2"})
+	sink.Write(log.LogEntry{Level: log.LogLevelError, Content: "refused again"})
+
+	if got := strings.Join(stop(t, sink, path), ","); got != "timeout 1,refused again" {
+		t.Errorf("the command got %s", got)
+	}
+
+	// Entries after Close go nowhere
+	if err := sink.Write(log.LogEntry{Level: log.LogLevelError, Content: "late"}); err != nil {
+		t.Errorf("Write after Close = %v", err)
+	}
+}
+
+func TestCommandSinkKillsAStuckCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the command is a POSIX shell pipeline")
+	}
+	sink, err := NewCommandSink("exec sleep 60", log.LogLevelDebug)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	sink.Close()
+	select {
+	case <-sink.Done():
+	case <-time.After(commandStopTimeout + 2*time.Second):
+		t.Fatal("the command was not killed")
+	}
+	if waited := time.Since(start); waited < commandStopTimeout {
+		t.Errorf("killed after %v, before the %v stop timeout", waited, commandStopTimeout)
+	}
+}
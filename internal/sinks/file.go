@@ -0,0 +1,71 @@
+package sinks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+)
+
+// FileSink appends entries to a file, one per line
+type FileSink struct {
+	file   *os.File
+	format string
+	mutex  sync.Mutex
+}
+
+// NewFileSink opens path for appending. Format is "json" for one JSON
+// entry per line or "text" for a plain, greppable form.
+func NewFileSink(path, format string) (*FileSink, error) {
+	switch format {
+	case "json", "text":
+	default:
+		return nil, fmt.Errorf("unknown sink format %q", format)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sink file: %w", err)
+	}
+
+	return &FileSink{
+		file:   file,
+		format: format,
+	}, nil
+}
+
+// Write appends the entry to the file
+func (f *FileSink) Write(entry log.LogEntry) error {
+	var line []byte
+	if f.format == "json" {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal entry: %w", err)
+		}
+		line = append(data, '\n')
+	} else {
+		line = []byte(textLine(entry))
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	_, err := f.file.Write(line)
+	return err
+}
+
+// textLine renders an entry in the plain, greppable text form, ending in
+// a newline
+func textLine(entry log.LogEntry) string {
+	return fmt.Sprintf("%s %s [%s] %s\n",
+		entry.Timestamp.Format("2006-01-02 15:04:05.000"), entry.Level, entry.Source, entry.Content)
+}
+
+// Close closes the file
+func (f *FileSink) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.file.Close()
+}
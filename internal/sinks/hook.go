@@ -0,0 +1,130 @@
+package sinks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+)
+
+// HookInterval is the least time between two runs of a hook's command;
+// matches in between are skipped
+const HookInterval = time.Second
+
+// hookTimeout bounds how long a hook's command may run before it is killed
+const hookTimeout = 10 * time.Second
+
+// HookSink runs a command for entries whose content or raw line matches a
+// pattern, handing it the entry as JSON on stdin. Commands run one at a
+// time in the background and at most once per HookInterval, so a slow
+// hook never holds up ingest and a flood of matches can't fork a process
+// per line. The command's output is discarded.
+type HookSink struct {
+	pattern *regexp.Regexp
+	command string
+
+	mutex   sync.Mutex
+	lastRun time.Time
+	closed  bool
+
+	pending chan log.LogEntry
+	done    chan struct{}
+}
+
+// NewHookSink parses a "regex:command" spec and starts its runner. The
+// first colon not escaped as \: ends the pattern.
+func NewHookSink(spec string) (*HookSink, error) {
+	pattern, command, ok := splitHookSpec(spec)
+	if !ok || pattern == "" || strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("hook %q is not of the form regex:command", spec)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hook pattern: %w", err)
+	}
+
+	h := &HookSink{
+		pattern: re,
+		command: command,
+		pending: make(chan log.LogEntry, 1),
+		done:    make(chan struct{}),
+	}
+	go h.run()
+	return h, nil
+}
+
+// splitHookSpec splits a spec at the first colon not preceded by a
+// backslash. The backslash is left in, \: being a valid regexp escape.
+func splitHookSpec(spec string) (pattern, command string, ok bool) {
+	for i := 0; i < len(spec); i++ {
+		if spec[i] == ':' && (i == 0 || spec[i-1] != '\\') {
+			return spec[:i], spec[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// Write queues the hook for a matching entry, unless it ran too recently
+// or is still busy with an earlier match
+func (h *HookSink) Write(entry log.LogEntry) error {
+	if !h.pattern.MatchString(entry.Content) && !h.pattern.MatchString(entry.Raw) {
+		return nil
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.closed || time.Since(h.lastRun) < HookInterval {
+		return nil
+	}
+	select {
+	case h.pending <- entry:
+		h.lastRun = time.Now()
+	default:
+		// Still running the last match, skip this one
+	}
+	return nil
+}
+
+// run executes queued hooks one at a time until Close
+func (h *HookSink) run() {
+	defer close(h.done)
+	for entry := range h.pending {
+		h.exec(entry)
+	}
+}
+
+// exec runs the command for one entry through the shell
+func (h *HookSink) exec(entry log.LogEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, h.command)
+	cmd.Stdin = bytes.NewReader(append(data, '\n'))
+	cmd.Run()
+}
+
+// Close stops taking matches and waits for a running hook to finish
+func (h *HookSink) Close() error {
+	h.mutex.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.pending)
+	}
+	h.mutex.Unlock()
+
+	<-h.done
+	return nil
+}
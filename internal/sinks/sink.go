@@ -0,0 +1,11 @@
+package sinks
+
+import (
+	"github.com/Yriskit-ai/logflow/internal/log"
+)
+
+// Sink receives every log entry the server accepts
+type Sink interface {
+	Write(entry log.LogEntry) error
+	Close() error
+}
@@ -0,0 +1,103 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// composeProjectLabel and composeServiceLabel are set by Docker Compose on
+// every container it starts
+const (
+	composeProjectLabel = "com.docker.compose.project"
+	composeServiceLabel = "com.docker.compose.service"
+)
+
+// ComposeService is a running container of a Docker Compose service. A
+// service scaled to several replicas yields one per container.
+type ComposeService struct {
+	Service   string
+	Container Container
+}
+
+// ListComposeServices returns the running containers of the named Docker
+// Compose project, on sshTarget when it is not empty, sorted by service
+func ListComposeServices(project, sshTarget string) ([]ComposeService, error) {
+	if err := checkComposeProject(project, sshTarget); err != nil {
+		return nil, err
+	}
+
+	// Compose labels its containers, which docker ps reads the same way on
+	// every Compose version
+	args := []string{"ps",
+		"--filter", "label=" + composeProjectLabel + "=" + project,
+		"--format", "{{.ID}}\t{{.Names}}\t{{.Label \"" + composeServiceLabel + "\"}}",
+	}
+	out, err := containerCommand(context.Background(), sshTarget, "docker", args...).Output()
+	if err != nil {
+		err = describeExit(sshTarget, "docker ps", err)
+		return nil, fmt.Errorf("failed to list containers of compose project %q: %w", project, err)
+	}
+
+	services := parseComposeServices(string(out))
+	if len(services) == 0 {
+		return nil, fmt.Errorf("compose project %q has no running containers", project)
+	}
+	return services, nil
+}
+
+// checkComposeProject reports an unknown project with the projects that do
+// exist. A docker without the compose plugin skips the check and lets the
+// container listing speak for itself.
+func checkComposeProject(project, sshTarget string) error {
+	out, err := containerCommand(context.Background(), sshTarget, "docker", "compose", "ls", "--all", "--format", "json").Output()
+	if err != nil {
+		return nil
+	}
+
+	var projects []struct {
+		Name string `json:"Name"`
+	}
+	if err := json.Unmarshal(out, &projects); err != nil {
+		return nil
+	}
+
+	names := make([]string, len(projects))
+	for i, p := range projects {
+		if p.Name == project {
+			return nil
+		}
+		names[i] = p.Name
+	}
+
+	if len(names) == 0 {
+		return fmt.Errorf("no compose project %q, and no compose projects are running", project)
+	}
+	return fmt.Errorf("no compose project %q (found: %s)", project, strings.Join(names, ", "))
+}
+
+// parseComposeServices parses "ID\tNAME\tSERVICE" lines from docker ps,
+// sorted by service and then container name
+func parseComposeServices(output string) []ComposeService {
+	var services []ComposeService
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "\t", 3)
+		if len(parts) != 3 || parts[2] == "" {
+			continue
+		}
+		services = append(services, ComposeService{
+			Service:   parts[2],
+			Container: Container{ID: parts[0], Name: parts[1]},
+		})
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Service != services[j].Service {
+			return services[i].Service < services[j].Service
+		}
+		return services[i].Container.Name < services[j].Container.Name
+	})
+	return services
+}
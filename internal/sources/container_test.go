@@ -0,0 +1,128 @@
+// internal/sources/container_test.go
+package sources
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/ipc"
+)
+
+// fakeRuntime puts a docker and a podman on PATH whose logs command prints
+// a line and then hangs, leaving a background child holding the output
+// pipes open the way a CLI's helper processes can. inspect fails, so no
+// exit entry is sent.
+func fakeRuntime(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake container runtime is a shell script")
+	}
+
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$1" in
+ps)
+	printf '3f9a2c1b7d4e\tapi\n3f9a77e01c2b\tapi-worker\n'
+	;;
+logs)
+	echo "2024-05-01T12:00:00.000000000Z attached"
+	sleep 2 &
+	exec sleep 30
+	;;
+*)
+	exit 1
+	;;
+esac
+`
+	for _, name := range []string{"docker", "podman"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// closableSource is a container source that can be detached from
+type closableSource interface {
+	Source
+	Close() error
+}
+
+// attachAndClose streams source until its first entry arrives, then closes
+// it and checks that Stream returns promptly
+func attachAndClose(t *testing.T, server *ipc.Server, source closableSource) {
+	t.Helper()
+	client, err := ipc.NewClientAt(server.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err := client.InitSource(source.Name(), source.Type()); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		source.Stream(client)
+		close(done)
+	}()
+
+	select {
+	case entry := <-server.LogChannel():
+		if entry.Content != "attached" {
+			t.Fatalf("first entry = %q", entry.Content)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no entry from the fake runtime")
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		source.Close()
+		close(closed)
+	}()
+	for _, ch := range []chan struct{}{closed, done} {
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Close or Stream blocked on a pipe a child process held open")
+		}
+	}
+}
+
+// checkNoLeak fails the test unless the goroutine count drops back to
+// baseline, allowing a moment for exiting goroutines to finish
+func checkNoLeak(t *testing.T, baseline int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			n := runtime.Stack(buf, true)
+			t.Fatalf("%d goroutines after closing, %d before:\n%s", runtime.NumGoroutine(), baseline, buf[:n])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestContainerSourcesCloseWithoutLeaking(t *testing.T) {
+	fakeRuntime(t)
+	server := startServer(t)
+
+	sources := map[string]func() closableSource{
+		"docker": func() closableSource { return NewDockerSource("app", "abc123") },
+		"podman": func() closableSource { return NewPodmanSource("app", "abc123") },
+	}
+	for name, newSource := range sources {
+		t.Run(name, func(t *testing.T) {
+			baseline := runtime.NumGoroutine()
+			for i := 0; i < 5; i++ {
+				attachAndClose(t, server, newSource())
+			}
+			checkNoLeak(t, baseline)
+		})
+	}
+}
@@ -2,12 +2,13 @@
 package sources
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Yriskit-ai/logflow/internal/ipc"
@@ -18,9 +19,21 @@ import (
 type DockerSource struct {
 	name        string
 	containerID string
+	container   string // Container name tagged on entries, empty to omit
+	sshTarget   string
+	parseOpts   log.ParseOptions
+	orderWindow time.Duration
+	useAPI      bool
+	image       string // Image tagged on entries, known when using the API
 	cmd         *exec.Cmd
 	ctx         context.Context
 	cancel      context.CancelFunc
+
+	// pipes and wg track the output readers so Close can unblock them and
+	// wait for the streaming goroutines to exit
+	pipes []io.Closer
+	wg    sync.WaitGroup
+	mutex sync.Mutex
 }
 
 // NewDockerSource creates a new Docker source
@@ -30,11 +43,44 @@ func NewDockerSource(name, containerID string) *DockerSource {
 	return &DockerSource{
 		name:        name,
 		containerID: containerID,
+		orderWindow: DefaultOrderWindow,
 		ctx:         ctx,
 		cancel:      cancel,
 	}
 }
 
+// SetSSHTarget makes the source run `docker logs` on a remote host over SSH,
+// e.g. "user@host"
+func (d *DockerSource) SetSSHTarget(target string) {
+	d.sshTarget = target
+}
+
+// SetUseAPI makes the source read logs from the Docker Engine API over the
+// daemon's socket instead of running `docker logs`, falling back to the
+// CLI when the daemon can't be reached that way. The API is only used for
+// the local daemon, not with an SSH target.
+func (d *DockerSource) SetUseAPI(enabled bool) {
+	d.useAPI = enabled
+}
+
+// SetContainerName tags every entry with the container's name in the
+// "container" metadata field, telling apart containers that share a source
+func (d *DockerSource) SetContainerName(name string) {
+	d.container = name
+}
+
+// SetParseOptions controls how much of each line is interpreted
+func (d *DockerSource) SetParseOptions(opts log.ParseOptions) {
+	d.parseOpts = opts
+}
+
+// SetOrderWindow sets how long entries are held back to interleave stdout
+// and stderr in the order the container wrote them. Zero or less sends
+// each line as soon as it is read, in whatever order the pipes deliver.
+func (d *DockerSource) SetOrderWindow(window time.Duration) {
+	d.orderWindow = window
+}
+
 // Name returns the source name
 func (d *DockerSource) Name() string {
 	return d.name
@@ -47,8 +93,100 @@ func (d *DockerSource) Type() string {
 
 // Stream starts following Docker container logs
 func (d *DockerSource) Stream(client *ipc.Client) error {
+	if d.useAPI && d.sshTarget == "" {
+		err := d.streamAPI(client)
+		if !errors.Is(err, errDockerAPIUnavailable) {
+			return err
+		}
+	}
+	return d.streamCLI(client)
+}
+
+// streamAPI follows the container's logs over the Engine API. It returns
+// errDockerAPIUnavailable, before sending anything, if the daemon can't be
+// reached.
+func (d *DockerSource) streamAPI(client *ipc.Client) error {
+	api, err := newDockerAPI()
+	if err != nil {
+		return err
+	}
+	container, err := api.inspect(d.ctx, d.containerID)
+	if err != nil {
+		return err
+	}
+	body, err := api.logs(d.ctx, d.containerID)
+	if err != nil {
+		return err
+	}
+	d.image = container.Config.Image
+
+	sender := newOrderedSender(client, d.orderWindow)
+	var demuxErr error
+
+	if container.Config.Tty {
+		// A TTY merges both streams, the daemon sends them as they are
+		d.mutex.Lock()
+		d.pipes = []io.Closer{body}
+		d.wg.Add(1)
+		d.mutex.Unlock()
+
+		go func() {
+			defer d.wg.Done()
+			d.streamPipe(sender, body, "stdout")
+		}()
+	} else {
+		stdoutReader, stdoutWriter := io.Pipe()
+		stderrReader, stderrWriter := io.Pipe()
+
+		d.mutex.Lock()
+		d.pipes = []io.Closer{body, stdoutReader, stderrReader}
+		d.wg.Add(3)
+		d.mutex.Unlock()
+
+		go func() {
+			defer d.wg.Done()
+			demuxErr = demuxLogs(body, stdoutWriter, stderrWriter)
+			stdoutWriter.Close()
+			stderrWriter.Close()
+		}()
+
+		// A reader that stops early closes its end, so the demuxer fails
+		// its next write instead of blocking
+		go func() {
+			defer d.wg.Done()
+			defer stdoutReader.Close()
+			d.streamPipe(sender, stdoutReader, "stdout")
+		}()
+		go func() {
+			defer d.wg.Done()
+			defer stderrReader.Close()
+			d.streamPipe(sender, stderrReader, "stderr")
+		}()
+	}
+
+	d.wg.Wait()
+	body.Close()
+
+	// The logs end when the container stops, say why unless we hung up
+	if d.ctx.Err() != nil {
+		sender.Close()
+		return nil
+	}
+	if container, err := api.inspect(d.ctx, d.containerID); err == nil && !container.State.Running {
+		sender.Send(exitEntry(d.name, d.containerID, d.container, container.State))
+	}
+	sender.Close()
+
+	if demuxErr != nil && !errors.Is(demuxErr, io.ErrClosedPipe) {
+		return fmt.Errorf("failed to read docker logs: %w", demuxErr)
+	}
+	return nil
+}
+
+// streamCLI follows the container's logs by running `docker logs`
+func (d *DockerSource) streamCLI(client *ipc.Client) error {
 	// Start docker logs command
-	d.cmd = exec.CommandContext(d.ctx, "docker", "logs", "-f", "--timestamps", d.containerID)
+	d.cmd = containerCommand(d.ctx, d.sshTarget, "docker", "logs", "-f", "--timestamps", d.containerID)
 
 	stdout, err := d.cmd.StdoutPipe()
 	if err != nil {
@@ -64,26 +202,43 @@ func (d *DockerSource) Stream(client *ipc.Client) error {
 		return fmt.Errorf("failed to start docker logs command: %w", err)
 	}
 
+	d.mutex.Lock()
+	d.pipes = []io.Closer{stdout, stderr}
+	d.wg.Add(2)
+	d.mutex.Unlock()
+
+	// The pipes are read independently, the sender puts their lines back
+	// in timestamp order
+	sender := newOrderedSender(client, d.orderWindow)
+
 	// Stream stdout
-	go d.streamPipe(client, stdout, "stdout")
+	go func() {
+		defer d.wg.Done()
+		d.streamPipe(sender, stdout, "stdout")
+	}()
 
 	// Stream stderr
-	go d.streamPipe(client, stderr, "stderr")
+	go func() {
+		defer d.wg.Done()
+		d.streamPipe(sender, stderr, "stderr")
+	}()
 
-	// Wait for command to finish
-	return d.cmd.Wait()
-}
-
-// streamPipe handles streaming from a pipe
-func (d *DockerSource) streamPipe(client *ipc.Client, pipe io.Reader, stream string) {
-	scanner := bufio.NewScanner(pipe)
+	// Drain both pipes before waiting, Wait closes them
+	d.wg.Wait()
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
+	// The logs end when the container stops, say why unless we hung up
+	if d.ctx.Err() == nil {
+		if state, ok := inspectExit(d.ctx, d.sshTarget, "docker", d.containerID); ok {
+			sender.Send(exitEntry(d.name, d.containerID, d.container, state))
 		}
+	}
+	sender.Close()
+	return describeExit(d.sshTarget, "docker logs", d.cmd.Wait())
+}
 
+// streamPipe handles streaming from a pipe
+func (d *DockerSource) streamPipe(sender *orderedSender, pipe io.Reader, stream string) {
+	ReadLines(pipe, func(line string) error {
 		// Parse Docker timestamp format: 2023-01-01T12:00:00.000000000Z message
 		var timestamp time.Time
 		var content string
@@ -94,47 +249,62 @@ func (d *DockerSource) streamPipe(client *ipc.Client, pipe io.Reader, stream str
 				timestamp = ts
 				content = parts[1]
 			} else {
-				timestamp = time.Now()
 				content = line
 			}
 		} else {
-			timestamp = time.Now()
 			content = line
 		}
 
-		// Create log entry
-		entry := log.NewLogEntry(d.name, content)
-		entry.Timestamp = timestamp
+		// Create log entry, the runtime's timestamp wins over one parsed
+		// from the line
+		entry := NewLineEntry(d.name, content, d.parseOpts)
+		if !timestamp.IsZero() {
+			entry.Timestamp = timestamp
+			entry.SyntheticTime = false
+		}
 
 		// Add stream metadata
-		if entry.Metadata == nil {
-			entry.Metadata = make(map[string]interface{})
-		}
 		entry.Metadata["stream"] = stream
 		entry.Metadata["container_id"] = d.containerID
+		if d.container != "" {
+			entry.Metadata["container"] = d.container
+		}
+		if d.image != "" {
+			entry.Metadata["image"] = d.image
+		}
 
 		// Convert to IPC format
 		ipcEntry := &ipc.LogEntry{
-			Timestamp: entry.Timestamp,
-			Source:    entry.Source,
-			Level:     ipc.LogLevel(entry.Level),
-			Content:   entry.Content,
-			Raw:       entry.Raw,
-			Metadata:  entry.Metadata,
+			Timestamp:     entry.Timestamp,
+			IngestTime:    entry.IngestTime,
+			SyntheticTime: entry.SyntheticTime,
+			Source:        entry.Source,
+			Level:         ipc.LogLevel(entry.Level),
+			Content:       entry.Content,
+			Raw:           entry.Raw,
+			Metadata:      entry.Metadata,
 		}
 
 		// Send to server
-		client.SendLog(ipcEntry)
-	}
+		return sender.Send(ipcEntry)
+	})
 }
 
-// Close stops the Docker logs command
+// Close stops the Docker logs command and waits for the streaming goroutines
+// to exit
 func (d *DockerSource) Close() error {
+	// Cancelling the context kills the process if it is still running
 	if d.cancel != nil {
 		d.cancel()
 	}
-	if d.cmd != nil && d.cmd.Process != nil {
-		return d.cmd.Process.Kill()
+
+	// Closing the pipes unblocks readers stuck on a pipe the process left open
+	d.mutex.Lock()
+	for _, pipe := range d.pipes {
+		pipe.Close()
 	}
+	d.mutex.Unlock()
+
+	d.wg.Wait()
 	return nil
 }
@@ -0,0 +1,171 @@
+// internal/sources/dockerapi.go
+package sources
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// dockerAPIVersion is the Engine API version requested, old enough for
+// every daemon still in use and new enough for the endpoints used here
+const dockerAPIVersion = "v1.24"
+
+// defaultDockerHost is where the daemon listens when DOCKER_HOST is unset
+const defaultDockerHost = "unix:///var/run/docker.sock"
+
+// errDockerAPIUnavailable reports that the daemon could not be reached
+// over its API, so the CLI should be used instead
+var errDockerAPIUnavailable = errors.New("docker API unavailable")
+
+// dockerAPI talks to the Docker Engine API over the daemon's socket, the
+// same endpoints the Docker Go SDK wraps, without spawning the CLI
+type dockerAPI struct {
+	client *http.Client
+	base   string
+}
+
+// dockerContainer is the part of a container's inspect output logflow uses
+type dockerContainer struct {
+	Name   string         `json:"Name"`
+	State  containerState `json:"State"`
+	Config struct {
+		Image string `json:"Image"`
+		Tty   bool   `json:"Tty"`
+	} `json:"Config"`
+}
+
+// newDockerAPI connects to the daemon named by DOCKER_HOST, or the default
+// Unix socket. Only unix:// and plain tcp:// hosts are supported; others,
+// like TLS or SSH hosts, report errDockerAPIUnavailable.
+func newDockerAPI() (*dockerAPI, error) {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = defaultDockerHost
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid DOCKER_HOST %q", errDockerAPIUnavailable, host)
+	}
+	if os.Getenv("DOCKER_TLS_VERIFY") != "" {
+		return nil, fmt.Errorf("%w: TLS hosts are not supported", errDockerAPIUnavailable)
+	}
+
+	var dial func(ctx context.Context, network, addr string) (net.Conn, error)
+	var dialer net.Dialer
+	switch u.Scheme {
+	case "unix":
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", u.Path)
+		}
+	case "tcp":
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp", u.Host)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported DOCKER_HOST %q", errDockerAPIUnavailable, host)
+	}
+
+	return &dockerAPI{
+		client: &http.Client{Transport: &http.Transport{DialContext: dial}},
+		// The host is ignored by the dialer, the daemon only reads the path
+		base: "http://docker/" + dockerAPIVersion,
+	}, nil
+}
+
+// get requests path from the daemon, failing on any status but 200
+func (api *dockerAPI) get(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.base+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := api.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errDockerAPIUnavailable, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		return nil, fmt.Errorf("docker API: %s", apiErr.Message)
+	}
+	return resp, nil
+}
+
+// inspect returns a container's name, image, TTY setting and state
+func (api *dockerAPI) inspect(ctx context.Context, id string) (dockerContainer, error) {
+	resp, err := api.get(ctx, "/containers/"+url.PathEscape(id)+"/json")
+	if err != nil {
+		return dockerContainer{}, err
+	}
+	defer resp.Body.Close()
+
+	var container dockerContainer
+	if err := json.NewDecoder(resp.Body).Decode(&container); err != nil {
+		return dockerContainer{}, fmt.Errorf("docker API: invalid inspect response: %w", err)
+	}
+	container.Name = strings.TrimPrefix(container.Name, "/")
+	return container, nil
+}
+
+// logs follows a container's stdout and stderr with timestamps. Unless the
+// container has a TTY, the two are multiplexed on the returned stream.
+func (api *dockerAPI) logs(ctx context.Context, id string) (io.ReadCloser, error) {
+	resp, err := api.get(ctx, "/containers/"+url.PathEscape(id)+"/logs?follow=1&stdout=1&stderr=1&timestamps=1")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// demuxLogs splits the daemon's multiplexed log stream into stdout and
+// stderr. Each frame is an 8 byte header, holding the stream in its first
+// byte and the payload size in the last four, followed by the payload.
+func demuxLogs(r io.Reader, stdout, stderr io.Writer) error {
+	reader := bufio.NewReader(r)
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var w io.Writer
+		switch header[0] {
+		case 1:
+			w = stdout
+		case 2:
+			w = stderr
+		default:
+			// Stdin is never sent, skip anything unknown
+			w = io.Discard
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		if _, err := io.CopyN(w, reader, size); err != nil {
+			if err == io.EOF {
+				// The stream ended inside a frame
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+}
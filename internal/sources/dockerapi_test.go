@@ -0,0 +1,116 @@
+// internal/sources/dockerapi_test.go
+package sources
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+// frame builds one frame of the daemon's multiplexed log stream
+func frame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+// frames concatenates frames into one stream
+func frames(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+func TestDemuxLogs(t *testing.T) {
+	tests := []struct {
+		name       string
+		stream     []byte
+		wantStdout string
+		wantStderr string
+		wantErr    error
+	}{
+		{name: "empty stream"},
+		{
+			name:       "stdout and stderr",
+			stream:     frames(frame(1, "out 1\n"), frame(2, "err 1\n"), frame(1, "out 2\n")),
+			wantStdout: "out 1\nout 2\n",
+			wantStderr: "err 1\n",
+		},
+		{
+			name:       "line split over frames",
+			stream:     frames(frame(1, "first ha"), frame(1, "lf\n")),
+			wantStdout: "first half\n",
+		},
+		{
+			name:       "zero-length frames",
+			stream:     frames(frame(1, ""), frame(2, ""), frame(1, "out\n"), frame(1, "")),
+			wantStdout: "out\n",
+		},
+		{
+			name:       "stdin frame discarded",
+			stream:     frames(frame(0, "in\n"), frame(1, "out\n")),
+			wantStdout: "out\n",
+		},
+		{
+			name:       "truncated header",
+			stream:     append(frame(1, "out\n"), 1, 0, 0),
+			wantStdout: "out\n",
+			wantErr:    io.ErrUnexpectedEOF,
+		},
+		{
+			name:       "truncated payload",
+			stream:     frames(frame(1, "out\n"), frame(2, "cut off\n")[:12]),
+			wantStdout: "out\n",
+			wantStderr: "cut ",
+			wantErr:    io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "header without payload",
+			stream:  frame(1, "missing")[:8],
+			wantErr: io.ErrUnexpectedEOF,
+		},
+	}
+
+	readers := []struct {
+		name string
+		wrap func(io.Reader) io.Reader
+	}{
+		{name: "whole", wrap: func(r io.Reader) io.Reader { return r }},
+		// Headers and payloads arrive split across reads, as they do from
+		// a chunked HTTP body
+		{name: "one byte at a time", wrap: iotest.OneByteReader},
+		{name: "half reads", wrap: iotest.HalfReader},
+	}
+
+	for _, tt := range tests {
+		for _, reader := range readers {
+			t.Run(tt.name+"/"+reader.name, func(t *testing.T) {
+				var stdout, stderr bytes.Buffer
+				err := demuxLogs(reader.wrap(bytes.NewReader(tt.stream)), &stdout, &stderr)
+				if err != tt.wantErr {
+					t.Errorf("demuxLogs returned %v, want %v", err, tt.wantErr)
+				}
+				if stdout.String() != tt.wantStdout {
+					t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantStdout)
+				}
+				if stderr.String() != tt.wantStderr {
+					t.Errorf("stderr = %q, want %q", stderr.String(), tt.wantStderr)
+				}
+			})
+		}
+	}
+}
+
+func TestDemuxLogsReadError(t *testing.T) {
+	boom := io.ErrClosedPipe
+	r := io.MultiReader(bytes.NewReader(frame(1, "out\n")), iotest.ErrReader(boom))
+
+	var stdout, stderr bytes.Buffer
+	if err := demuxLogs(r, &stdout, &stderr); err != boom {
+		t.Errorf("demuxLogs returned %v, want the reader's error", err)
+	}
+	if stdout.String() != "out\n" {
+		t.Errorf("stdout = %q, want the frame before the error", stdout.String())
+	}
+}
@@ -0,0 +1,83 @@
+// internal/sources/exitstatus.go
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/ipc"
+	"github.com/Yriskit-ai/logflow/internal/log"
+)
+
+// containerState is the part of a container's inspect output describing
+// how it stopped, the same for Docker and Podman
+type containerState struct {
+	Status     string    `json:"Status"`
+	Running    bool      `json:"Running"`
+	ExitCode   int       `json:"ExitCode"`
+	OOMKilled  bool      `json:"OOMKilled"`
+	Error      string    `json:"Error"`
+	FinishedAt time.Time `json:"FinishedAt"`
+}
+
+// inspectExit asks the runtime how a container stopped. It reports false
+// if the container is still running or can't be inspected, e.g. because
+// it was removed.
+func inspectExit(ctx context.Context, sshTarget, runtime, containerID string) (containerState, bool) {
+	cmd := containerCommand(ctx, sshTarget, runtime, "inspect", "--format", "{{json .State}}", containerID)
+	output, err := cmd.Output()
+	if err != nil {
+		return containerState{}, false
+	}
+
+	var state containerState
+	if err := json.Unmarshal(output, &state); err != nil || state.Running {
+		return containerState{}, false
+	}
+	return state, true
+}
+
+// exitEntry builds the final entry of a container's pane saying why it
+// stopped, at error level unless it exited cleanly
+func exitEntry(source, containerID, container string, state containerState) *ipc.LogEntry {
+	content := fmt.Sprintf("container exited, code=%d", state.ExitCode)
+	if state.OOMKilled {
+		content += " OOMKilled"
+	}
+	if state.Error != "" {
+		content += ": " + state.Error
+	}
+
+	level := log.LogLevelInfo
+	if state.ExitCode != 0 || state.OOMKilled || state.Error != "" {
+		level = log.ResolveLevel(log.LogLevelError, 0)
+	}
+
+	now := time.Now()
+	timestamp, synthetic := state.FinishedAt, false
+	if timestamp.IsZero() {
+		timestamp, synthetic = now, true
+	}
+
+	metadata := map[string]interface{}{
+		"exit_code":    state.ExitCode,
+		"oom_killed":   state.OOMKilled,
+		"container_id": containerID,
+	}
+	if container != "" {
+		metadata["container"] = container
+	}
+
+	return &ipc.LogEntry{
+		Timestamp:     timestamp,
+		IngestTime:    now,
+		SyntheticTime: synthetic,
+		Source:        source,
+		Level:         ipc.LogLevel(level),
+		Content:       content,
+		Raw:           content,
+		Metadata:      metadata,
+	}
+}
@@ -0,0 +1,61 @@
+// internal/sources/fifo.go
+package sources
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Yriskit-ai/logflow/internal/ipc"
+)
+
+// FifoSource reads logs from a named pipe like a PipeSource. Reading a
+// FIFO ends once its last writer closes it, so the FIFO is reopened to
+// wait for the next writer instead of ending the source.
+type FifoSource struct {
+	*PipeSource
+	path string
+}
+
+// NewFifoSource creates a source reading the named pipe at path, failing
+// if path is not one
+func NewFifoSource(name, path string) (*FifoSource, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode()&os.ModeNamedPipe == 0 {
+		return nil, fmt.Errorf("%s is not a named pipe, create one with mkfifo", path)
+	}
+
+	return &FifoSource{
+		PipeSource: NewPipeSource(name, nil),
+		path:       path,
+	}, nil
+}
+
+// Type returns the source type
+func (f *FifoSource) Type() string {
+	return "fifo"
+}
+
+// Stream sends what every writer of the FIFO writes, one writer session
+// after another, until the head limit is reached or the FIFO can no longer
+// be opened
+func (f *FifoSource) Stream(client *ipc.Client) error {
+	for {
+		// Blocks until a writer opens the other end
+		file, err := os.Open(f.path)
+		if err != nil {
+			return fmt.Errorf("failed to open FIFO: %w", err)
+		}
+
+		err = f.streamFrom(file, client)
+		file.Close()
+		if err == errHeadReached {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// internal/sources/fifo_test.go
+//go:build !windows
+
+package sources
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFifoSourceHeadStopsBeforeReopening(t *testing.T) {
+	server := startServer(t)
+
+	path := filepath.Join(t.TempDir(), "app.fifo")
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	source, err := NewFifoSource("app", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.SetHead(2)
+
+	// The writer sends exactly the head and hangs up. Before the fix the
+	// source went back to waiting for the next writer.
+	go func() {
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer f.Close()
+		fmt.Fprint(f, "line 1\nline 2\n")
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		feed(t, server, source)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		// Unblock the reopen so the goroutine can finish
+		if f, err := os.OpenFile(path, os.O_WRONLY, 0); err == nil {
+			f.Close()
+		}
+		t.Fatal("the source reopened the FIFO after the head limit")
+	}
+
+	if got := received(t, server, 2); strings.Join(got, ",") != "line 1,line 2" {
+		t.Errorf("received %q", got)
+	}
+}
@@ -2,17 +2,33 @@
 package sources
 
 import (
-	"bufio"
+	"encoding/json"
+	"errors"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/Yriskit-ai/logflow/internal/ipc"
 	"github.com/Yriskit-ai/logflow/internal/log"
 )
 
+// errHeadReached stops reading once --head lines have been sent
+var errHeadReached = errors.New("head reached")
+
 // PipeSource reads logs from stdin/pipe
 type PipeSource struct {
-	name   string
-	reader io.Reader
+	name      string
+	reader    io.Reader
+	jsonIn    bool
+	parseOpts log.ParseOptions
+	parser    *log.Parser // Detects the level of decoded entries lacking a known one
+	skip      int         // Lines dropped before any is sent
+	head      int         // Lines sent before the source stops, zero for no limit
+
+	// skipped and sent count lines toward skip and head over the whole
+	// stream, across every reopen of a FIFO
+	skipped int
+	sent    int
 }
 
 // NewPipeSource creates a new pipe source
@@ -20,9 +36,33 @@ func NewPipeSource(name string, reader io.Reader) *PipeSource {
 	return &PipeSource{
 		name:   name,
 		reader: reader,
+		parser: log.NewParser(),
 	}
 }
 
+// SetJSONInput enables passing through lines that are already logflow
+// entries in JSON form instead of running them through the parser
+func (p *PipeSource) SetJSONInput(enabled bool) {
+	p.jsonIn = enabled
+}
+
+// SetParseOptions controls how much of each line is interpreted
+func (p *PipeSource) SetParseOptions(opts log.ParseOptions) {
+	p.parseOpts = opts
+}
+
+// SetSkip drops the first n lines, e.g. the history a 'cat' replays
+// before the lines worth watching
+func (p *PipeSource) SetSkip(n int) {
+	p.skip = n
+}
+
+// SetHead stops the source after sending n lines, counted after any
+// skipped ones; zero sends everything
+func (p *PipeSource) SetHead(n int) {
+	p.head = n
+}
+
 // Name returns the source name
 func (p *PipeSource) Name() string {
 	return p.name
@@ -33,34 +73,122 @@ func (p *PipeSource) Type() string {
 	return "pipe"
 }
 
-// Stream reads from the pipe and sends log entries to the client
+// Stream reads from the pipe until EOF, or until the head limit is reached,
+// and sends log entries to the client. Blank lines are not counted.
 func (p *PipeSource) Stream(client *ipc.Client) error {
-	scanner := bufio.NewScanner(p.reader)
+	err := p.streamFrom(p.reader, client)
+	if err == errHeadReached {
+		return nil
+	}
+	return err
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
+// streamFrom sends the lines of r until EOF, returning errHeadReached as
+// soon as the last line the head limit allows is sent, without waiting for
+// another line that may never come
+func (p *PipeSource) streamFrom(r io.Reader, client *ipc.Client) error {
+	return ReadLines(r, func(line string) error {
+		if p.skipped < p.skip {
+			p.skipped++
+			return nil
 		}
 
-		// Create log entry
-		entry := log.NewLogEntry(p.name, line)
-
-		// Convert to IPC format
-		ipcEntry := &ipc.LogEntry{
-			Timestamp: entry.Timestamp,
-			Source:    entry.Source,
-			Level:     ipc.LogLevel(entry.Level),
-			Content:   entry.Content,
-			Raw:       entry.Raw,
-			Metadata:  entry.Metadata,
+		p.sent++
+		if err := p.send(line, client); err != nil {
+			return err
 		}
+		if p.head > 0 && p.sent == p.head {
+			return errHeadReached
+		}
+		return nil
+	})
+}
 
-		// Send to server
-		if err := client.SendLog(ipcEntry); err != nil {
-			return err
+// send sends one line as a log entry
+func (p *PipeSource) send(line string, client *ipc.Client) error {
+	if p.jsonIn {
+		if ipcEntry, ok := p.decodeEntry(line); ok {
+			return client.SendLog(ipcEntry)
 		}
 	}
 
-	return scanner.Err()
+	// Create log entry
+	entry := NewLineEntry(p.name, line, p.parseOpts)
+
+	// Convert to IPC format
+	ipcEntry := &ipc.LogEntry{
+		Timestamp:     entry.Timestamp,
+		IngestTime:    entry.IngestTime,
+		SyntheticTime: entry.SyntheticTime,
+		Source:        entry.Source,
+		Level:         ipc.LogLevel(entry.Level),
+		Content:       entry.Content,
+		Raw:           entry.Raw,
+		Metadata:      entry.Metadata,
+	}
+
+	// Send to server
+	return client.SendLog(ipcEntry)
+}
+
+// decodeEntry returns the line as a log entry if it is a JSON object
+// following the entry schema, i.e. carrying at least level and content
+func (p *PipeSource) decodeEntry(line string) (*ipc.LogEntry, bool) {
+	if !strings.HasPrefix(strings.TrimSpace(line), "{") {
+		return nil, false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return nil, false
+	}
+	if _, ok := fields["level"]; !ok {
+		return nil, false
+	}
+	if _, ok := fields["content"]; !ok {
+		return nil, false
+	}
+
+	var entry ipc.LogEntry
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return nil, false
+	}
+
+	// Fill in what the producer left out
+	if entry.Source == "" {
+		entry.Source = p.name
+	}
+	if entry.IngestTime.IsZero() {
+		entry.IngestTime = time.Now()
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = entry.IngestTime
+		entry.SyntheticTime = true
+	}
+	if entry.Raw == "" {
+		entry.Raw = line
+	}
+
+	// A producer on Windows may have encoded its line ending along with
+	// the message, as ReadLines does for raw lines, drop it
+	entry.Content = strings.TrimSuffix(entry.Content, "\r")
+
+	// The line is only cut once decoded, truncating it first would break
+	// the JSON
+	var original int
+	entry.Content, original = truncateLine(entry.Content)
+	entry.Raw, _ = truncateLine(entry.Raw)
+	entry.Metadata = log.CapMetadata(entry.Metadata, maxMetadataBytes)
+	if original > 0 {
+		if entry.Metadata == nil {
+			entry.Metadata = make(map[string]interface{})
+		}
+		entry.Metadata["original_length"] = original
+	}
+	entry.Level = ipc.LogLevel(strings.ToUpper(string(entry.Level)))
+	if !log.IsKnownLevel(log.LogLevel(entry.Level)) {
+		entry.Level = ipc.LogLevel(p.parser.ParseLevel(entry.Content))
+	}
+
+	return &entry, true
 }
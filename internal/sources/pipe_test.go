@@ -0,0 +1,176 @@
+// internal/sources/pipe_test.go
+package sources
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/ipc"
+)
+
+func TestDecodeEntryCRLF(t *testing.T) {
+	p := NewPipeSource("win", nil)
+
+	// The line ending left encoded in the content by a Windows producer
+	var lines []string
+	ReadLines(strings.NewReader(`{"level":"error","content":"disk full\r"}`+"\r\n"), func(line string) error {
+		lines = append(lines, line)
+		return nil
+	})
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1", len(lines))
+	}
+
+	entry, ok := p.decodeEntry(lines[0])
+	if !ok {
+		t.Fatalf("decodeEntry rejected %q", lines[0])
+	}
+	if entry.Content != "disk full" {
+		t.Errorf("content = %q, want the carriage return dropped", entry.Content)
+	}
+	if entry.Level != "ERROR" || entry.Source != "win" {
+		t.Errorf("decoded entry = %+v", entry)
+	}
+}
+
+func TestDecodeEntryRejectsOtherJSON(t *testing.T) {
+	p := NewPipeSource("api", nil)
+	for _, line := range []string{
+		`{"msg":"no level or content"}`,
+		`{"level":"info"}`,
+		`{"level":"info","content":`,
+		`plain text`,
+	} {
+		if _, ok := p.decodeEntry(line); ok {
+			t.Errorf("decodeEntry accepted %q", line)
+		}
+	}
+}
+
+// startServer starts an IPC server on a socket in a temporary directory,
+// closed when the test ends
+func startServer(t *testing.T) *ipc.Server {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the feeder tests use a Unix socket")
+	}
+
+	server, err := ipc.NewServerAt(filepath.Join(t.TempDir(), "logflow.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+// feed streams source to server the way a feeder does, from source_init
+// to source_exit, then hangs up
+func feed(t *testing.T, server *ipc.Server, source Source) {
+	t.Helper()
+	client, err := ipc.NewClientAt(server.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if err := client.InitSource(source.Name(), source.Type()); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.Stream(client); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SendExit(source.Name()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// received collects the contents of the entries the server has dispatched
+// once it has counted want of them, or fails the test
+func received(t *testing.T, server *ipc.Server, want int) []string {
+	t.Helper()
+	var contents []string
+	timeout := time.After(5 * time.Second)
+	for len(contents) < want {
+		select {
+		case entry := <-server.LogChannel():
+			contents = append(contents, entry.Content)
+		case <-timeout:
+			t.Fatalf("received %d of %d entries", len(contents), want)
+		}
+	}
+
+	// Nothing beyond want may follow
+	select {
+	case entry := <-server.LogChannel():
+		t.Fatalf("unexpected entry %q after %d", entry.Content, want)
+	case <-time.After(50 * time.Millisecond):
+	}
+	return contents
+}
+
+func TestPipeSourceDeliversEveryLineOfAFile(t *testing.T) {
+	server := startServer(t)
+
+	// More lines than the dashboard's channel holds would be dropped if
+	// nobody read, so keep within it; the last line has no newline
+	const count = 900
+	var file strings.Builder
+	for i := 1; i <= count; i++ {
+		fmt.Fprintf(&file, "2024-05-01 12:00:00 INFO line %d", i)
+		if i < count {
+			file.WriteString("\n")
+		}
+	}
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte(file.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	feed(t, server, NewPipeSource("app", f))
+
+	contents := received(t, server, count)
+	for i, content := range contents {
+		if want := fmt.Sprintf("2024-05-01 12:00:00 INFO line %d", i+1); content != want {
+			t.Fatalf("entry %d = %q, want %q", i, content, want)
+		}
+	}
+}
+
+func TestPipeSourceHeadStopsWithoutWaiting(t *testing.T) {
+	server := startServer(t)
+
+	// The writer keeps the pipe open after its lines, like a tail -f
+	r, w := io.Pipe()
+	defer w.Close()
+	go fmt.Fprint(w, "skipped 1\nskipped 2\nline 1\nline 2\nline 3\n")
+
+	source := NewPipeSource("app", r)
+	source.SetSkip(2)
+	source.SetHead(3)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		feed(t, server, source)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the source waited for a line past the head limit")
+	}
+
+	if got := received(t, server, 3); strings.Join(got, ",") != "line 1,line 2,line 3" {
+		t.Errorf("received %q", got)
+	}
+}
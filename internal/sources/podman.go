@@ -2,12 +2,12 @@
 package sources
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"io"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Yriskit-ai/logflow/internal/ipc"
@@ -18,9 +18,19 @@ import (
 type PodmanSource struct {
 	name        string
 	containerID string
+	container   string // Container name tagged on entries, empty to omit
+	sshTarget   string
+	parseOpts   log.ParseOptions
+	orderWindow time.Duration
 	cmd         *exec.Cmd
 	ctx         context.Context
 	cancel      context.CancelFunc
+
+	// pipes and wg track the output readers so Close can unblock them and
+	// wait for the streaming goroutines to exit
+	pipes []io.Closer
+	wg    sync.WaitGroup
+	mutex sync.Mutex
 }
 
 // NewPodmanSource creates a new Podman source
@@ -30,11 +40,36 @@ func NewPodmanSource(name, containerID string) *PodmanSource {
 	return &PodmanSource{
 		name:        name,
 		containerID: containerID,
+		orderWindow: DefaultOrderWindow,
 		ctx:         ctx,
 		cancel:      cancel,
 	}
 }
 
+// SetSSHTarget makes the source run `podman logs` on a remote host over SSH,
+// e.g. "user@host"
+func (p *PodmanSource) SetSSHTarget(target string) {
+	p.sshTarget = target
+}
+
+// SetContainerName tags every entry with the container's name in the
+// "container" metadata field, telling apart containers that share a source
+func (p *PodmanSource) SetContainerName(name string) {
+	p.container = name
+}
+
+// SetParseOptions controls how much of each line is interpreted
+func (p *PodmanSource) SetParseOptions(opts log.ParseOptions) {
+	p.parseOpts = opts
+}
+
+// SetOrderWindow sets how long entries are held back to interleave stdout
+// and stderr in the order the container wrote them. Zero or less sends
+// each line as soon as it is read, in whatever order the pipes deliver.
+func (p *PodmanSource) SetOrderWindow(window time.Duration) {
+	p.orderWindow = window
+}
+
 // Name returns the source name
 func (p *PodmanSource) Name() string {
 	return p.name
@@ -48,7 +83,7 @@ func (p *PodmanSource) Type() string {
 // Stream starts following Podman container logs
 func (p *PodmanSource) Stream(client *ipc.Client) error {
 	// Start podman logs command
-	p.cmd = exec.CommandContext(p.ctx, "podman", "logs", "-f", "--timestamps", p.containerID)
+	p.cmd = containerCommand(p.ctx, p.sshTarget, "podman", "logs", "-f", "--timestamps", p.containerID)
 
 	stdout, err := p.cmd.StdoutPipe()
 	if err != nil {
@@ -64,26 +99,43 @@ func (p *PodmanSource) Stream(client *ipc.Client) error {
 		return fmt.Errorf("failed to start podman logs command: %w", err)
 	}
 
+	p.mutex.Lock()
+	p.pipes = []io.Closer{stdout, stderr}
+	p.wg.Add(2)
+	p.mutex.Unlock()
+
+	// The pipes are read independently, the sender puts their lines back
+	// in timestamp order
+	sender := newOrderedSender(client, p.orderWindow)
+
 	// Stream stdout
-	go p.streamPipe(client, stdout, "stdout")
+	go func() {
+		defer p.wg.Done()
+		p.streamPipe(sender, stdout, "stdout")
+	}()
 
 	// Stream stderr
-	go p.streamPipe(client, stderr, "stderr")
-
-	// Wait for command to finish
-	return p.cmd.Wait()
+	go func() {
+		defer p.wg.Done()
+		p.streamPipe(sender, stderr, "stderr")
+	}()
+
+	// Drain both pipes before waiting, Wait closes them
+	p.wg.Wait()
+
+	// The logs end when the container stops, say why unless we hung up
+	if p.ctx.Err() == nil {
+		if state, ok := inspectExit(p.ctx, p.sshTarget, "podman", p.containerID); ok {
+			sender.Send(exitEntry(p.name, p.containerID, p.container, state))
+		}
+	}
+	sender.Close()
+	return describeExit(p.sshTarget, "podman logs", p.cmd.Wait())
 }
 
 // streamPipe handles streaming from a pipe
-func (p *PodmanSource) streamPipe(client *ipc.Client, pipe io.Reader, stream string) {
-	scanner := bufio.NewScanner(pipe)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
-		}
-
+func (p *PodmanSource) streamPipe(sender *orderedSender, pipe io.Reader, stream string) {
+	ReadLines(pipe, func(line string) error {
 		// Parse Podman timestamp format (similar to Docker)
 		var timestamp time.Time
 		var content string
@@ -94,47 +146,59 @@ func (p *PodmanSource) streamPipe(client *ipc.Client, pipe io.Reader, stream str
 				timestamp = ts
 				content = parts[1]
 			} else {
-				timestamp = time.Now()
 				content = line
 			}
 		} else {
-			timestamp = time.Now()
 			content = line
 		}
 
-		// Create log entry
-		entry := log.NewLogEntry(p.name, content)
-		entry.Timestamp = timestamp
+		// Create log entry, the runtime's timestamp wins over one parsed
+		// from the line
+		entry := NewLineEntry(p.name, content, p.parseOpts)
+		if !timestamp.IsZero() {
+			entry.Timestamp = timestamp
+			entry.SyntheticTime = false
+		}
 
 		// Add stream metadata
-		if entry.Metadata == nil {
-			entry.Metadata = make(map[string]interface{})
-		}
 		entry.Metadata["stream"] = stream
 		entry.Metadata["container_id"] = p.containerID
+		if p.container != "" {
+			entry.Metadata["container"] = p.container
+		}
 
 		// Convert to IPC format
 		ipcEntry := &ipc.LogEntry{
-			Timestamp: entry.Timestamp,
-			Source:    entry.Source,
-			Level:     ipc.LogLevel(entry.Level),
-			Content:   entry.Content,
-			Raw:       entry.Raw,
-			Metadata:  entry.Metadata,
+			Timestamp:     entry.Timestamp,
+			IngestTime:    entry.IngestTime,
+			SyntheticTime: entry.SyntheticTime,
+			Source:        entry.Source,
+			Level:         ipc.LogLevel(entry.Level),
+			Content:       entry.Content,
+			Raw:           entry.Raw,
+			Metadata:      entry.Metadata,
 		}
 
 		// Send to server
-		client.SendLog(ipcEntry)
-	}
+		return sender.Send(ipcEntry)
+	})
 }
 
-// Close stops the Podman logs command
+// Close stops the Podman logs command and waits for the streaming goroutines
+// to exit
 func (p *PodmanSource) Close() error {
+	// Cancelling the context kills the process if it is still running
 	if p.cancel != nil {
 		p.cancel()
 	}
-	if p.cmd != nil && p.cmd.Process != nil {
-		return p.cmd.Process.Kill()
+
+	// Closing the pipes unblocks readers stuck on a pipe the process left open
+	p.mutex.Lock()
+	for _, pipe := range p.pipes {
+		pipe.Close()
 	}
+	p.mutex.Unlock()
+
+	p.wg.Wait()
 	return nil
 }
@@ -0,0 +1,173 @@
+// internal/sources/reorder.go
+package sources
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/ipc"
+)
+
+// DefaultOrderWindow is how long container entries are held back so
+// stdout and stderr lines can be put in emission order
+const DefaultOrderWindow = 100 * time.Millisecond
+
+// reorderQueue orders entries by timestamp, holding each until it has
+// waited window since it arrived. Entries with equal timestamps keep their
+// arrival order. It does no locking and reads no clock, time is passed in.
+type reorderQueue struct {
+	window  time.Duration
+	pending pendingHeap
+	arrived uint64
+}
+
+// pendingEntry is an entry waiting in a reorderQueue
+type pendingEntry struct {
+	entry   *ipc.LogEntry
+	arrival time.Time
+	order   uint64
+}
+
+// push adds an entry that arrived at now
+func (q *reorderQueue) push(entry *ipc.LogEntry, now time.Time) {
+	q.arrived++
+	heap.Push(&q.pending, pendingEntry{entry: entry, arrival: now, order: q.arrived})
+}
+
+// popDue removes and returns, oldest timestamp first, the entries that
+// have waited out the window by now. An entry still waiting holds back due
+// entries with later timestamps, keeping the output in order.
+func (q *reorderQueue) popDue(now time.Time) []*ipc.LogEntry {
+	var due []*ipc.LogEntry
+	for q.pending.Len() > 0 && now.Sub(q.pending[0].arrival) >= q.window {
+		due = append(due, heap.Pop(&q.pending).(pendingEntry).entry)
+	}
+	return due
+}
+
+// popAll removes and returns every entry, oldest timestamp first
+func (q *reorderQueue) popAll() []*ipc.LogEntry {
+	var all []*ipc.LogEntry
+	for q.pending.Len() > 0 {
+		all = append(all, heap.Pop(&q.pending).(pendingEntry).entry)
+	}
+	return all
+}
+
+// pendingHeap is a min-heap of entries by timestamp, then arrival
+type pendingHeap []pendingEntry
+
+func (h pendingHeap) Len() int { return len(h) }
+
+func (h pendingHeap) Less(i, j int) bool {
+	if !h[i].entry.Timestamp.Equal(h[j].entry.Timestamp) {
+		return h[i].entry.Timestamp.Before(h[j].entry.Timestamp)
+	}
+	return h[i].order < h[j].order
+}
+
+func (h pendingHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *pendingHeap) Push(x interface{}) { *h = append(*h, x.(pendingEntry)) }
+
+func (h *pendingHeap) Pop() interface{} {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// orderedSender sends the entries of a container's stdout and stderr to
+// the dashboard in timestamp order rather than in whichever order the two
+// pipes happened to be read. The runtime stamps each line as it is
+// written, so holding entries for a short window and sorting them
+// restores the container's emission order at the cost of that much
+// latency. A window of zero or less sends entries straight through.
+type orderedSender struct {
+	client *ipc.Client
+	queue  reorderQueue
+	mutex  sync.Mutex
+	err    error // First send failure, returned from later sends
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// newOrderedSender starts a sender flushing due entries to client
+func newOrderedSender(client *ipc.Client, window time.Duration) *orderedSender {
+	s := &orderedSender{
+		client: client,
+		queue:  reorderQueue{window: window},
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	if window <= 0 {
+		close(s.done)
+		return s
+	}
+
+	go s.flushLoop(window / 4)
+	return s
+}
+
+// Send queues an entry, or sends it right away without a window. It
+// returns the error of an earlier failed send so streaming stops once the
+// dashboard is gone.
+func (s *orderedSender) Send(entry *ipc.LogEntry) error {
+	if s.queue.window <= 0 {
+		return s.client.SendLog(entry)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.queue.push(entry, time.Now())
+	return nil
+}
+
+// flushLoop sends due entries every interval until Close
+func (s *orderedSender) flushLoop(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(max(interval, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.mutex.Lock()
+			s.send(s.queue.popDue(now))
+			s.mutex.Unlock()
+		}
+	}
+}
+
+// send sends entries in order, stopping at the first failure. Must be
+// called with the lock held.
+func (s *orderedSender) send(entries []*ipc.LogEntry) {
+	for _, entry := range entries {
+		if s.err != nil {
+			return
+		}
+		s.err = s.client.SendLog(entry)
+	}
+}
+
+// Close stops flushing and sends whatever is still held back
+func (s *orderedSender) Close() error {
+	if s.queue.window > 0 {
+		close(s.stop)
+	}
+	<-s.done
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.send(s.queue.popAll())
+	return s.err
+}
@@ -0,0 +1,121 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Container identifies a running container
+type Container struct {
+	ID   string
+	Name string
+}
+
+// ResolveContainers finds the running containers matching selector using
+// the given runtime CLI ("docker" or "podman"), on sshTarget when it is not
+// empty. The selector may be a
+// container ID, an exact or partial name, or a "label=key[=value]" filter.
+// Unless all is set, a selector matching more than one container is an error.
+func ResolveContainers(runtime, sshTarget, selector string, all bool) ([]Container, error) {
+	var filters []string
+	if strings.HasPrefix(selector, "label=") {
+		filters = append(filters, selector)
+	}
+
+	containers, err := ListContainers(runtime, sshTarget, filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Container
+	if strings.HasPrefix(selector, "label=") {
+		matches = containers
+	} else {
+		matches = matchContainers(containers, selector)
+	}
+
+	switch {
+	case len(matches) == 0:
+		return nil, fmt.Errorf("no running %s container matches %q", runtime, selector)
+	case len(matches) > 1 && !all:
+		names := make([]string, len(matches))
+		for i, c := range matches {
+			names[i] = fmt.Sprintf("%s %s", c.Name, c.ID)
+		}
+		return nil, fmt.Errorf("%q matches %d %s containers (%s); use --all to attach to all of them",
+			selector, len(matches), runtime, strings.Join(names, ", "))
+	}
+
+	return matches, nil
+}
+
+// minIDPrefix is the shortest selector taken as a container ID prefix, so
+// a short name such as "db" is not also matched against every ID
+const minIDPrefix = 4
+
+// matchContainers returns the containers selector names. An exact name or
+// ID picks one container; otherwise every container whose name contains
+// selector or, for a hex selector of minIDPrefix or more characters, whose
+// ID starts with it, matches, in listing order.
+func matchContainers(containers []Container, selector string) []Container {
+	for _, c := range containers {
+		// ps shows IDs shortened, so a full ID starts with the listed one
+		if c.Name == selector || (c.ID != "" && strings.HasPrefix(selector, c.ID)) {
+			return []Container{c}
+		}
+	}
+
+	idPrefix := isIDPrefix(selector)
+	var matches []Container
+	for _, c := range containers {
+		if strings.Contains(c.Name, selector) || (idPrefix && strings.HasPrefix(c.ID, selector)) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+// isIDPrefix reports whether selector could be the start of a container ID
+func isIDPrefix(selector string) bool {
+	if len(selector) < minIDPrefix {
+		return false
+	}
+	for _, r := range selector {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListContainers returns the running containers known to the given
+// runtime CLI ("docker" or "podman"), on sshTarget when it is not empty,
+// narrowed by optional ps filters
+func ListContainers(runtime, sshTarget string, filters ...string) ([]Container, error) {
+	args := []string{"ps", "--format", "{{.ID}}\t{{.Names}}"}
+	for _, filter := range filters {
+		args = append(args, "--filter", filter)
+	}
+
+	out, err := containerCommand(context.Background(), sshTarget, runtime, args...).Output()
+	if err != nil {
+		err = describeExit(sshTarget, runtime+" ps", err)
+		return nil, fmt.Errorf("failed to list %s containers: %w", runtime, err)
+	}
+
+	return parseContainerList(string(out)), nil
+}
+
+// parseContainerList parses "ID\tNAME" lines from the ps command output
+func parseContainerList(output string) []Container {
+	var containers []Container
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "\t", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		containers = append(containers, Container{ID: parts[0], Name: parts[1]})
+	}
+	return containers
+}
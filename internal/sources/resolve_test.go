@@ -0,0 +1,77 @@
+// internal/sources/resolve_test.go
+package sources
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseContainerList(t *testing.T) {
+	output := "3f9a2c1b7d4e\tapi\n\nbad line\n  cafe01234567\tcafe-web  \n"
+	want := []Container{
+		{ID: "3f9a2c1b7d4e", Name: "api"},
+		{ID: "cafe01234567", Name: "cafe-web"},
+	}
+	if got := parseContainerList(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseContainerList = %+v, want %+v", got, want)
+	}
+}
+
+func TestMatchContainers(t *testing.T) {
+	containers := []Container{
+		{ID: "3f9a2c1b7d4e", Name: "api"},
+		{ID: "3f9a77e01c2b", Name: "api-worker"},
+		{ID: "db0412ee93a1", Name: "postgres"},
+		{ID: "0c4fe8a2b6d1", Name: "db"},
+		{ID: "cafe01234567", Name: "web"},
+		{ID: "9e1d00b2c3f4", Name: "cafe-menu"},
+	}
+
+	tests := []struct {
+		name     string
+		selector string
+		want     []string
+	}{
+		{name: "exact name beats partial names", selector: "api", want: []string{"api"}},
+		{name: "partial name", selector: "work", want: []string{"api-worker"}},
+		{name: "several partial names", selector: "e", want: []string{"api-worker", "postgres", "web", "cafe-menu"}},
+		{name: "unique ID prefix", selector: "3f9a2c", want: []string{"api"}},
+		{name: "ambiguous ID prefix", selector: "3f9a", want: []string{"api", "api-worker"}},
+		{name: "short ID", selector: "3f9a2c1b7d4e", want: []string{"api"}},
+		{name: "full ID", selector: "3f9a2c1b7d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8", want: []string{"api"}},
+		// Too short to be an ID prefix, so postgres's ID does not match
+		{name: "short hex name", selector: "db", want: []string{"db"}},
+		{name: "short hex selector", selector: "3f9", want: nil},
+		{name: "hex name and ID prefix", selector: "cafe", want: []string{"web", "cafe-menu"}},
+		{name: "not hex", selector: "3f9g", want: nil},
+		{name: "upper case is not an ID", selector: "3F9A2C", want: nil},
+		{name: "no match", selector: "redis", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range matchContainers(containers, tt.selector) {
+				got = append(got, c.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matchContainers(%q) = %q, want %q", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveContainersAmbiguousIDPrefix(t *testing.T) {
+	fakeRuntime(t)
+
+	_, err := ResolveContainers("docker", "", "3f9a", false)
+	want := `"3f9a" matches 2 docker containers (api 3f9a2c1b7d4e, api-worker 3f9a77e01c2b); use --all to attach to all of them`
+	if err == nil || err.Error() != want {
+		t.Errorf("ResolveContainers error = %v, want %s", err, want)
+	}
+
+	containers, err := ResolveContainers("docker", "", "3f9a7", false)
+	if err != nil || len(containers) != 1 || containers[0].Name != "api-worker" {
+		t.Errorf("ResolveContainers(3f9a7) = %+v, %v, want api-worker", containers, err)
+	}
+}
@@ -2,12 +2,103 @@
 package sources
 
 import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/Yriskit-ai/logflow/internal/ipc"
+	"github.com/Yriskit-ai/logflow/internal/log"
 )
 
+// DefaultMaxLineBytes is the length lines are cut to at ingest unless
+// configured otherwise
+const DefaultMaxLineBytes = 64 * 1024
+
+// DefaultMaxMetadataBytes is the JSON size metadata is capped to at ingest
+// unless configured otherwise
+const DefaultMaxMetadataBytes = 8 * 1024
+
+// truncatedMarker is appended to lines cut at ingest
+const truncatedMarker = "…(truncated)"
+
+// maxLineBytes caps a single ingested line so one runaway line cannot bloat
+// the buffer or the renderer, zero disables the cap
+var maxLineBytes = DefaultMaxLineBytes
+
+// maxMetadataBytes caps the metadata of a single entry, zero disables the cap
+var maxMetadataBytes = DefaultMaxMetadataBytes
+
+// SetMaxMetadataBytes sets the JSON size metadata is capped to at ingest.
+// Zero or less disables the cap.
+func SetMaxMetadataBytes(n int) {
+	maxMetadataBytes = n
+}
+
+// SetMaxLineBytes sets the length lines are truncated to at ingest. Zero or
+// less disables truncation.
+func SetMaxLineBytes(n int) {
+	maxLineBytes = n
+}
+
 // Source represents a log source that can stream log entries
 type Source interface {
 	Stream(client *ipc.Client) error
 	Name() string
 	Type() string
 }
+
+// ReadLines calls fn for every non-empty line of r until EOF. Unlike
+// bufio.Scanner it has no line length limit and delivers a final line that
+// lacks a trailing newline, so the reader is always fully drained.
+func ReadLines(r io.Reader, fn func(line string) error) error {
+	reader := bufio.NewReader(r)
+
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line != "" {
+			if sendErr := fn(line); sendErr != nil {
+				return sendErr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// NewLineEntry parses a raw line into a log entry as far as opts allow,
+// truncating overly long lines first. The original length of a truncated
+// line is recorded in the "original_length" metadata field, and oversized
+// metadata from structured lines is capped.
+func NewLineEntry(source, line string, opts log.ParseOptions) *log.LogEntry {
+	line, original := truncateLine(line)
+
+	entry := log.NewLogEntryWithOptions(source, line, opts)
+	entry.Metadata = log.CapMetadata(entry.Metadata, maxMetadataBytes)
+	if original > 0 {
+		entry.Metadata["original_length"] = original
+	}
+	return entry
+}
+
+// truncateLine cuts line to maxLineBytes without splitting a UTF-8
+// sequence and appends the truncation marker. It returns the original
+// length in bytes if the line was cut, zero otherwise.
+func truncateLine(line string) (string, int) {
+	if maxLineBytes <= 0 || len(line) <= maxLineBytes {
+		return line, 0
+	}
+
+	cut := maxLineBytes
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + truncatedMarker, len(line)
+}
@@ -0,0 +1,83 @@
+// internal/sources/sources_test.go
+package sources
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+)
+
+// readAll collects the lines ReadLines delivers from input
+func readAll(t *testing.T, input string) []string {
+	t.Helper()
+	var lines []string
+	err := ReadLines(strings.NewReader(input), func(line string) error {
+		lines = append(lines, line)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
+
+func TestReadLinesLineEndings(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"LF", "one\ntwo\n", []string{"one", "two"}},
+		{"CRLF", "one\r\ntwo\r\n", []string{"one", "two"}},
+		{"mixed", "one\r\ntwo\nthree\r\n", []string{"one", "two", "three"}},
+		{"CRLF without a final newline", "one\r\ntwo\r", []string{"one", "two"}},
+		{"no final newline", "one\ntwo", []string{"one", "two"}},
+		{"blank CRLF lines skipped", "one\r\n\r\n\ntwo\r\n", []string{"one", "two"}},
+		{"inner CR kept", "progress 10%\rprogress 20%\r\n", []string{"progress 10%\rprogress 20%"}},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readAll(t, tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadLines(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadLinesLongLine(t *testing.T) {
+	long := strings.Repeat("x", 1<<20)
+	got := readAll(t, long+"\r\nnext\r\n")
+	if len(got) != 2 || got[0] != long || got[1] != "next" {
+		t.Errorf("ReadLines split or cut a long line: %d lines", len(got))
+	}
+}
+
+func TestReadLinesStopsOnError(t *testing.T) {
+	stop := errors.New("stop")
+	n := 0
+	err := ReadLines(strings.NewReader("a\nb\nc\n"), func(string) error {
+		n++
+		return stop
+	})
+	if err != stop || n != 1 {
+		t.Errorf("ReadLines returned %v after %d lines, want the callback's error after 1", err, n)
+	}
+}
+
+func TestNewLineEntryCRLF(t *testing.T) {
+	var lines []string
+	ReadLines(strings.NewReader("2024-05-01 12:00:00 ERROR disk full\r\n"), func(line string) error {
+		lines = append(lines, line)
+		return nil
+	})
+
+	entry := NewLineEntry("win", lines[0], log.ParseOptions{})
+	if strings.ContainsRune(entry.Content, '\r') || strings.ContainsRune(entry.Raw, '\r') {
+		t.Errorf("entry kept a carriage return: %q / %q", entry.Content, entry.Raw)
+	}
+}
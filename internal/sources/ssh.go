@@ -0,0 +1,63 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// sshConnectionFailed is the exit status ssh uses for its own errors, as
+// opposed to a failure of the remote command
+const sshConnectionFailed = 255
+
+// containerCommand builds the command for a container runtime invocation,
+// running it on sshTarget over SSH when one is given
+func containerCommand(ctx context.Context, sshTarget, name string, args ...string) *exec.Cmd {
+	if sshTarget == "" {
+		return exec.CommandContext(ctx, name, args...)
+	}
+
+	// ssh hands the remote side a single command line, so quote each word
+	remote := make([]string, 0, len(args)+1)
+	remote = append(remote, shellQuote(name))
+	for _, arg := range args {
+		remote = append(remote, shellQuote(arg))
+	}
+
+	sshArgs := []string{
+		"-T",                           // No TTY, keep the output stream clean
+		"-o", "ServerAliveInterval=15", // Notice a dead connection
+		"-o", "ServerAliveCountMax=3",
+		sshTarget,
+		"--",
+		strings.Join(remote, " "),
+	}
+	return exec.CommandContext(ctx, "ssh", sshArgs...)
+}
+
+// describeExit wraps a command error, telling SSH connection failures
+// apart from the remote command exiting
+func describeExit(sshTarget, what string, err error) error {
+	if err == nil || sshTarget == "" {
+		return err
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == sshConnectionFailed {
+		return fmt.Errorf("ssh connection to %s failed: %w", sshTarget, err)
+	}
+	return fmt.Errorf("%s on %s exited: %w", what, sshTarget, err)
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			strings.ContainsRune("-_./=:@,+%{}", r))
+	}) == -1 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
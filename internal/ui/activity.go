@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"strings"
+	"time"
+)
+
+// activitySeconds is how much history the activity sparkline covers
+const activitySeconds = 60
+
+// sparklineWidth is the number of columns the sparkline is drawn in, each
+// summing activitySeconds/sparklineWidth seconds
+const sparklineWidth = 20
+
+// sparkBlocks draw a column from lowest to highest, a quiet column is blank
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// activity counts entries per second over the last minute in a ring of
+// one-second buckets
+type activity struct {
+	counts [activitySeconds]int
+	newest int64 // Unix second of the newest bucket
+}
+
+// record counts an entry received at now
+func (r *activity) record(now time.Time) {
+	r.advance(now.Unix())
+	r.counts[r.newest%activitySeconds]++
+}
+
+// advance moves the ring forward to second, zeroing the buckets skipped
+func (r *activity) advance(second int64) {
+	if second <= r.newest {
+		return
+	}
+	if second-r.newest >= activitySeconds {
+		r.counts = [activitySeconds]int{}
+	} else {
+		for s := r.newest + 1; s <= second; s++ {
+			r.counts[s%activitySeconds] = 0
+		}
+	}
+	r.newest = second
+}
+
+// buckets returns the per-second counts of the last minute up to now,
+// oldest first
+func (r *activity) buckets(now time.Time) []int {
+	r.advance(now.Unix())
+
+	counts := make([]int, activitySeconds)
+	for i := range counts {
+		second := r.newest - activitySeconds + 1 + int64(i)
+		counts[i] = r.counts[(second%activitySeconds+activitySeconds)%activitySeconds]
+	}
+	return counts
+}
+
+// sparkline draws counts in width columns of block characters, scaled to
+// the busiest column
+func sparkline(counts []int, width int) string {
+	per := max(1, len(counts)/width)
+	columns := make([]int, 0, width)
+	peak := 0
+	for i := 0; i+per <= len(counts); i += per {
+		sum := 0
+		for _, n := range counts[i : i+per] {
+			sum += n
+		}
+		columns = append(columns, sum)
+		peak = max(peak, sum)
+	}
+
+	var b strings.Builder
+	for _, n := range columns {
+		if n == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(sparkBlocks[(n*len(sparkBlocks)-1)/peak])
+	}
+	return b.String()
+}
+
+// total returns how many entries were counted in the last minute up to now
+func (r *activity) total(now time.Time) int {
+	sum := 0
+	for _, n := range r.buckets(now) {
+		sum += n
+	}
+	return sum
+}
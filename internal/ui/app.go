@@ -1,8 +1,14 @@
 package ui
 
 import (
+	"context"
 	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Yriskit-ai/logflow/internal/ipc"
@@ -18,6 +24,7 @@ const (
 	LayoutHorizontal LayoutMode = iota
 	LayoutVertical
 	LayoutAutoGrid
+	LayoutProportional // Stacked, sized by each pane's activity
 )
 
 // ViewMode defines the current view state
@@ -26,6 +33,8 @@ type ViewMode int
 const (
 	ViewMultiPane ViewMode = iota
 	ViewZoomed
+	ViewDigest // One line per source with its latest entry
+	ViewTrace  // Entries of every source sharing one trace ID
 )
 
 // SearchMode defines search scope
@@ -37,23 +46,114 @@ const (
 	SearchGlobal            // Search all panes
 )
 
+// DefaultTickRate is how often the view refreshes without new log entries;
+// arriving entries re-render immediately so this only keeps time-based
+// output fresh
+const DefaultTickRate = time.Second
+
+// DefaultBufferSize is how many entries each pane keeps in memory
+const DefaultBufferSize = 1000
+
+// defaultContextLines is how many lines precede a filtered match when
+// context is toggled on
+const defaultContextLines = 3
+
 // App represents the main TUI application
 type App struct {
 	server        *ipc.Server
 	panes         map[string]*Pane
-	paneOrder     []string
+	paneOrder     []string            // Panes visible in the active group
+	allPanes      []string            // Every shown pane, in arrival order unless moved
+	hiddenPanes   map[string]struct{} // Sources hidden over maxPanes
+	maxPanes      int                 // Cap on shown panes, zero for none
+	dropHidden    bool                // Discard hidden sources' buffers
+	groups        []Group
+	activeGroup   int // 0 shows all panes, otherwise groups[activeGroup-1]
 	layout        LayoutMode
 	viewMode      ViewMode
+	digestSort    DigestSort
 	focusedPane   int
-	zoomedPane    int
+	lastFocused   string // Pane focused last frame, marked viewed once focus leaves
+	zoomedSource  string // Source zoomed into, followed by name as panes move
+	single        bool   // Show the focused source alone even with several
 	searchMode    SearchMode
 	searchQuery   string
+	searchHistory *SearchHistory
 	searchResults []SearchResult
+	searchScope   SearchMode // Mode of the last search started
+	searchGen     uint64     // Bumped per search so stale results are dropped
+	searchCancel  context.CancelFunc
+	searching     bool
+	gotoMode      bool // Typing a source name to jump to
+	gotoQuery     string
+	commands      []command // Every action, run by key or from the palette
+	paletteMode   bool      // Choosing a command in the palette
+	paletteQuery  string
+	paletteCursor int  // Selected match in the palette
+	helpMode      bool // Showing the key binding help
+	helpQuery     string
+	helpScroll    int  // Help lines scrolled past
+	confirmClear  bool // Waiting for y/n before clearing every pane
+	presetMode    bool // Typing the name to save the current filters under
+	presetQuery   string
+	presets       []Preset // Saved presets, built-in ones aside
+	presetsFile   string   // Where presets persist, empty to keep them in memory
+	presetName    string   // Preset applied or saved last
+	startPreset   *Preset  // From --preset, applied with the first entry
+	pipeMode      bool     // Typing the command to pipe the focused pane to
+	pipeQuery     string
+	fuzzyMode     bool // Typing a fuzzy filter for the focused pane
+	fuzzyQuery    string
 	filterLevel   log.LogLevel
+	contextLines  int
 	followMode    bool
+	truncateMode  TruncateMode
+	levelAlign    LevelAlign
+	levelStyle    LevelStyle
+	timeColumn    TimeColumn
 	paused        bool
+	tickRate      time.Duration
+	ticking       bool
+	sortIdle      bool
+	holdLevel     log.LogLevel // Level that pauses follow mode, empty to disable
+	holdDuration  time.Duration
+	errorRates    []ErrorRate // Error rate alert thresholds, the default one has no source
 	width         int
 	height        int
+	picker        *Picker
+	sourceError   string
+	sourceErrors  []string // Every source error of the session, for --strict
+	notice        string   // Outcome of the last dump
+	errorJumped   string   // Pane the last jump to error landed on
+	traceKeys     []string
+	highlights    []HighlightRule // Applied to every line, first rule wins
+	lastWindow    time.Duration   // Only entries this recent are shown, zero for all
+	bufferSize    int             // Entries kept in memory per pane
+	traceID       string          // Trace followed in the trace view
+	trace         []log.LogEntry  // Entries of the followed trace, oldest first
+	traceStreams  map[string]int  // Position of each source in the trace's merge
+	traceScroll   int             // Lines the trace view is scrolled back from the newest
+	spillDir      string          // Where evicted entries go on disk, empty to drop them
+	showMetrics   bool
+	showMetadata  bool     // Append each entry's flattened metadata
+	fields        []string // Metadata keys appended inline, from --show-fields
+	showFields    bool
+	showSparkline bool // Activity sparklines in pane headers
+	showCounts    bool // Per-level entry counts in pane headers
+	inputTTY      bool // Keys come from the terminal, stdin is a source
+	metrics       metrics
+	throughput    throughput
+	showRate      bool // Entry count and ingest rate in the status bar
+
+	// exitOnIdle quits the dashboard once every source has gone and nothing
+	// has arrived for that long
+	exitOnIdle    time.Duration
+	lastActivity  time.Time // When the last entry arrived
+	sourcesGoneAt time.Time // When the last source was seen disconnected
+
+	// quit is closed by Quit to end Run from outside the program
+	quit     chan struct{}
+	quitOnce sync.Once
 
 	// Styles
 	styles Styles
@@ -64,37 +164,82 @@ type SearchResult struct {
 	PaneName string
 	Entry    log.LogEntry
 	Index    int
+	Seq      uint64 // Stable reference to the entry across evictions
 }
 
 // NewApp creates a new TUI application
 func NewApp(server *ipc.Server) *App {
-	return &App{
-		server:      server,
-		panes:       make(map[string]*Pane),
-		paneOrder:   make([]string, 0),
-		layout:      LayoutVertical,
-		viewMode:    ViewMultiPane,
-		focusedPane: 0,
-		filterLevel: log.LogLevelDebug, // Show all levels by default
-		followMode:  true,
-		styles:      NewStyles(),
+	applyColorMode()
+
+	a := &App{
+		server:        server,
+		panes:         make(map[string]*Pane),
+		paneOrder:     make([]string, 0),
+		hiddenPanes:   make(map[string]struct{}),
+		layout:        LayoutVertical,
+		viewMode:      ViewMultiPane,
+		focusedPane:   0,
+		filterLevel:   log.LowestLevel(), // Show all levels by default
+		followMode:    true,
+		tickRate:      DefaultTickRate,
+		bufferSize:    DefaultBufferSize,
+		showSparkline: true,
+		showCounts:    true,
+		showRate:      true,
+		traceKeys:     log.DefaultTraceKeys,
+		picker:        NewPicker(server.Path()),
+		searchHistory: NewSearchHistory(""),
+		styles:        NewStyles(),
+		quit:          make(chan struct{}),
 	}
+	a.commands = a.buildCommands(DefaultKeyMap())
+	return a
 }
 
 // Run starts the TUI application
 func (a *App) Run() error {
-	p := tea.NewProgram(a, tea.WithAltScreen())
+	options := []tea.ProgramOption{tea.WithAltScreen()}
+	if a.inputTTY {
+		options = append(options, tea.WithInputTTY())
+	}
+	p := tea.NewProgram(a, options...)
 
 	// Start listening for log entries
 	go a.listenForLogs(p)
 
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-a.quit:
+			p.Quit()
+		case <-done:
+		}
+	}()
+
 	_, err := p.Run()
+	a.stopPipes()
+	a.closePanes()
 	return err
 }
 
-// Quit sends a quit message to the application
+// closePanes closes the spill file of every pane
+func (a *App) closePanes() {
+	for _, pane := range a.panes {
+		pane.buffer.Close()
+	}
+}
+
+// SourceErrors returns the errors sources ran into during the session, in
+// the order they happened. Only safe to call once Run has returned.
+func (a *App) SourceErrors() []string {
+	return a.sourceErrors
+}
+
+// Quit makes Run return as if the quit key was pressed, also when called
+// before Run starts. Safe to call from any goroutine, more than once.
 func (a *App) Quit() {
-	// Implementation depends on how you want to handle shutdown
+	a.quitOnce.Do(func() { close(a.quit) })
 }
 
 // listenForLogs processes incoming log entries from the IPC server
@@ -106,7 +251,7 @@ func (a *App) listenForLogs(p *tea.Program) {
 
 // LogEntryMsg represents a new log entry message
 type LogEntryMsg struct {
-	Entry *ipc.LogEntry
+	Entry log.LogEntry
 }
 
 // TickMsg for periodic updates
@@ -116,13 +261,113 @@ type TickMsg time.Time
 func (a *App) Init() tea.Cmd {
 	return tea.Batch(
 		tea.EnterAltScreen,
-		tick(),
+		a.scheduleTick(),
+		a.scheduleIdleCheck(),
+		a.scheduleRateCheck(),
+		loadContainers(),
 	)
 }
 
-// tick returns a command that sends periodic tick messages
-func tick() tea.Cmd {
-	return tea.Tick(time.Millisecond*100, func(t time.Time) tea.Msg {
+// SetInputTTY makes the app read keys from the controlling terminal
+// rather than stdin, which is left to be piped into a source
+func (a *App) SetInputTTY(enabled bool) {
+	a.inputTTY = enabled
+}
+
+// SetFields appends the named metadata fields, dotted for nested ones, to
+// every line
+func (a *App) SetFields(fields []string) {
+	a.fields = fields
+	a.showFields = len(fields) > 0
+}
+
+// SetHighlights styles the matches of rules in every line shown. Where
+// matches overlap, the earlier rule wins.
+func (a *App) SetHighlights(rules []HighlightRule) {
+	a.highlights = rules
+}
+
+// SetLastWindow shows only entries from the last window, a range that
+// slides forward as time passes; zero shows everything
+func (a *App) SetLastWindow(window time.Duration) {
+	a.lastWindow = window
+}
+
+// SetSpillDir keeps entries evicted from each pane's buffer in a file per
+// source under dir, where search and dumps still reach them
+func (a *App) SetSpillDir(dir string) {
+	a.spillDir = dir
+}
+
+// SetSearchHistoryFile persists search history to path across sessions
+func (a *App) SetSearchHistoryFile(path string) {
+	a.searchHistory = NewSearchHistory(path)
+}
+
+// SetHoldOnLevel makes entries at level or above pause follow mode in
+// their pane for duration; an empty level disables it
+func (a *App) SetHoldOnLevel(level log.LogLevel, duration time.Duration) {
+	a.holdLevel = level
+	a.holdDuration = duration
+}
+
+// SetShowMetrics shows the debug overlay with the dashboard's own
+// performance counters
+func (a *App) SetShowMetrics(show bool) {
+	a.showMetrics = show
+}
+
+// HoldExpiredMsg signals that a pause on an error may have run out
+type HoldExpiredMsg struct{}
+
+// SetLevelAlign sets how level badges are padded to line up content
+func (a *App) SetLevelAlign(align LevelAlign) {
+	a.levelAlign = align
+}
+
+// SetLevelStyle sets whether levels show as names, letters or icons
+func (a *App) SetLevelStyle(style LevelStyle) {
+	a.levelStyle = style
+}
+
+// SetWatched creates empty panes for sources expected to connect, in the
+// order given, so the layout is in place before any of them sends a line.
+// Their headers show how long they have been waiting until they do. Call
+// it after the buffer size and spill directory are set.
+func (a *App) SetWatched(names []string) {
+	for _, name := range names {
+		if _, exists := a.panes[name]; exists {
+			continue
+		}
+		a.addPane(name).waiting = true
+	}
+}
+
+// SetBufferSize sets how many entries each new pane keeps in memory
+func (a *App) SetBufferSize(size int) {
+	a.bufferSize = size
+}
+
+// SetSingle shows the focused source alone, like tail -f, however many
+// sources there are. A lone source is shown that way regardless.
+func (a *App) SetSingle(enabled bool) {
+	a.single = enabled
+}
+
+// SetTickRate sets the idle refresh interval; zero or less disables it
+func (a *App) SetTickRate(rate time.Duration) {
+	a.tickRate = rate
+}
+
+// scheduleTick returns a command that sends the next tick message, or nil
+// when ticking is disabled, paused or already scheduled
+func (a *App) scheduleTick() tea.Cmd {
+	if a.tickRate <= 0 || a.paused || a.ticking {
+		return nil
+	}
+
+	a.ticking = true
+	return tea.Tick(a.tickRate, func(t time.Time) tea.Msg {
 		return TickMsg(t)
 	})
 }
@@ -138,143 +383,198 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.updateLayout()
 
 	case tea.KeyMsg:
-		return a.handleKeyPress(msg)
+		_, cmd := a.handleKeyPress(msg)
+		cmds = append(cmds, cmd)
 
 	case LogEntryMsg:
-		a.handleLogEntry(msg.Entry)
+		a.lastActivity = time.Now()
+		cmds = append(cmds, a.handleLogEntry(msg.Entry))
+		if a.startPreset != nil {
+			// Its search needs a pane to run in
+			cmds = append(cmds, a.applyPreset(*a.startPreset))
+			a.startPreset = nil
+		}
+		a.sortIdlePanes()
+
+	case HoldExpiredMsg:
+		// Nothing to do, the re-render picks up the expired hold
+
+	case IdleCheckMsg:
+		cmds = append(cmds, a.checkIdle())
+
+	case RateCheckMsg:
+		cmds = append(cmds, a.checkErrorRates())
+
+	case ContainersMsg:
+		a.picker.SetItems(msg.Items, msg.Err)
+
+	case SearchResultsMsg:
+		a.handleSearchResults(msg)
+
+	case DumpDoneMsg:
+		if msg.Err != nil {
+			a.notice = fmt.Sprintf("Dump failed: %v", msg.Err)
+		} else {
+			a.notice = fmt.Sprintf("Dumped to %s", msg.Path)
+		}
+
+	case PipeExitedMsg:
+		a.handlePipeExited(msg)
+
+	case SourceErrorMsg:
+		a.sourceError = fmt.Sprintf("%s: %v", msg.Name, msg.Err)
+		a.sourceErrors = append(a.sourceErrors, a.sourceError)
 
 	case TickMsg:
-		cmds = append(cmds, tick())
+		a.throughput.sample(a.server.Stats(), time.Time(msg))
+		a.sortIdlePanes()
+		a.forgetExited()
+		a.ticking = false
+		cmds = append(cmds, a.scheduleTick())
 	}
 
+	// Keys, presets and focus changes can all change what a pipe is sent
+	a.syncPipeFilters()
 	return a, tea.Batch(cmds...)
 }
 
 // handleKeyPress processes keyboard input
 func (a *App) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Global quit
-	if msg.String() == "q" || msg.String() == "ctrl+c" {
+	// ctrl+c quits from anywhere, q only once no prompt takes it as text
+	if msg.String() == "ctrl+c" {
 		return a, tea.Quit
 	}
 
+	// Any key dismisses a pause on an error
+	a.releaseHolds()
+
+	// Offer the container picker until the first source shows up
+	if len(a.panes) == 0 {
+		return a.handlePickerInput(msg)
+	}
+
+	// Handle the command palette
+	if a.paletteMode {
+		return a.handlePaletteInput(msg)
+	}
+
+	// Handle the key binding help
+	if a.helpMode {
+		return a.handleHelpInput(msg)
+	}
+
 	// Handle search mode
 	if a.searchMode != SearchNone {
 		return a.handleSearchInput(msg)
 	}
 
-	switch msg.String() {
-	// Layout controls
-	case "L": // Use capital L for layout to avoid conflict
-		a.cycleLayout()
-
-	// Zoom controls
-	case "z":
-		if a.viewMode == ViewMultiPane && len(a.paneOrder) > 0 {
-			a.viewMode = ViewZoomed
-			a.zoomedPane = a.focusedPane
-		} else if a.viewMode == ViewZoomed {
-			a.viewMode = ViewMultiPane
-		}
-		a.updateLayout()
+	// Handle the go to source prompt
+	if a.gotoMode {
+		return a.handleGotoInput(msg)
+	}
 
-	// Navigation
-	case "tab":
-		a.nextPane()
-	case "shift+tab":
-		a.prevPane()
-	case "h":
-		if a.layout == LayoutVertical {
-			a.prevPane()
-		}
-	case "l": // Use lowercase l for vim navigation
-		if a.layout == LayoutVertical {
-			a.nextPane()
-		}
-	case "j":
-		if a.layout == LayoutHorizontal {
-			a.nextPane()
-		} else {
-			a.scrollDown()
-		}
-	case "k":
-		if a.layout == LayoutHorizontal {
-			a.prevPane()
-		} else {
-			a.scrollUp()
-		}
+	// Handle naming a preset
+	if a.presetMode {
+		return a.handlePresetNameInput(msg)
+	}
 
-	// Number keys for direct pane access
-	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
-		paneNum := int(msg.String()[0] - '1')
-		if paneNum < len(a.paneOrder) {
-			if a.viewMode == ViewZoomed {
-				a.zoomedPane = paneNum
-			}
-			a.focusedPane = paneNum
-			a.updateLayout()
+	// Handle the pipe command prompt
+	if a.pipeMode {
+		return a.handlePipeInput(msg)
+	}
+
+	// Handle fuzzy filter typing
+	if a.fuzzyMode {
+		return a.handleFuzzyInput(msg)
+	}
+
+	// Clearing every pane needs confirming, any other key cancels
+	if a.confirmClear {
+		a.confirmClear = false
+		if msg.String() == "y" || msg.String() == "Y" {
+			a.clearAllPanes()
 		}
+		return a, nil
+	}
 
-	// Search
-	case "/":
-		a.searchMode = SearchLocal
-		a.searchQuery = ""
-	case "ctrl+/", "?":
-		a.searchMode = SearchGlobal
-		a.searchQuery = ""
+	return a, a.runKey(msg.String())
+}
 
-	// Filter controls
-	case "e":
-		a.filterLevel = log.LogLevelError
-	case "w":
-		a.filterLevel = log.LogLevelWarn
-	case "i":
-		a.filterLevel = log.LogLevelInfo
-	case "a":
-		a.filterLevel = log.LogLevelDebug
-
-	// Control
-	case " ":
-		a.paused = !a.paused
-	case "f":
-		a.followMode = !a.followMode
-	case "c":
-		a.clearFocusedPane()
+// addPane creates and shows the pane of a new source, spilling to disk
+// when --spill-dir is set
+func (a *App) addPane(name string) *Pane {
+	pane := NewPane(name, a.bufferSize)
+	if a.spillDir != "" {
+		path := filepath.Join(a.spillDir, spillFileName(name))
+		if err := pane.buffer.EnableSpill(path); err != nil {
+			a.notice = fmt.Sprintf("%s: %v", name, err)
+			a.sourceErrors = append(a.sourceErrors, a.notice)
+		}
 	}
+	a.panes[name] = pane
+	a.showPane(name)
+	return pane
+}
 
-	return a, nil
+// spillFileName returns the spill file name of a source. Sanitizing alone
+// would give "a/b" and "a_b" the same file, so a hash of the name follows.
+func spillFileName(name string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	return fmt.Sprintf("%s-%08x.jsonl", dumpFileName(name), hash.Sum32())
 }
 
 // handleLogEntry processes a new log entry
-func (a *App) handleLogEntry(entry *ipc.LogEntry) {
+func (a *App) handleLogEntry(entry log.LogEntry) tea.Cmd {
 	// Get or create pane for this source
 	pane, exists := a.panes[entry.Source]
 	if !exists {
-		pane = NewPane(entry.Source, 1000) // Buffer size
-		a.panes[entry.Source] = pane
-		a.paneOrder = append(a.paneOrder, entry.Source)
-		a.updateLayout()
+		pane = a.addPane(entry.Source)
+	} else if a.isHidden(entry.Source) {
+		a.showPane(entry.Source)
 	}
 
-	// Convert IPC entry to internal log entry
-	logEntry := log.LogEntry{
-		Timestamp: entry.Timestamp,
-		Source:    entry.Source,
-		Level:     log.LogLevel(entry.Level),
-		Content:   entry.Content,
-		Raw:       entry.Raw,
-		Metadata:  entry.Metadata,
+	// Add to pane if not paused
+	if a.paused {
+		return nil
 	}
+	pane.AddEntry(entry)
+	a.addToTrace(entry)
+	if entry.Level.AtLeast(a.errorJumpLevel()) {
+		pane.lastErrorTime = pane.lastEntryTime
+	}
+	a.recordError(pane, entry)
+
+	// Hold follow mode on severe entries so they can be read, unless the
+	// pane is muted
+	if a.holdLevel != "" && a.followMode && entry.Level.AtLeast(a.holdLevel) && !pane.IsHolding() && !pane.muted {
+		pane.HoldOn(pane.LastSeq(), a.holdDuration)
+		return tea.Tick(a.holdDuration, func(time.Time) tea.Msg {
+			return HoldExpiredMsg{}
+		})
+	}
+	return nil
+}
 
-	// Add to pane if not paused
-	if !a.paused {
-		pane.AddEntry(logEntry)
+// releaseHolds resumes follow mode in every pane held on an error
+func (a *App) releaseHolds() {
+	for _, pane := range a.panes {
+		pane.ReleaseHold()
 	}
 }
 
 // View implements tea.Model
 func (a *App) View() string {
-	if len(a.paneOrder) == 0 {
-		return a.styles.EmptyState.Render("Waiting for log sources...\n\nStart sending logs with:\npython app.py | logflow --source backend")
+	if len(a.panes) == 0 {
+		return a.styles.EmptyState.Render(a.picker.View())
+	}
+
+	start := time.Now()
+	defer func() { a.metrics.renderTime = time.Since(start) }()
+	a.trackViewed()
+
+	if a.singleView() && !a.paletteMode && !a.helpMode {
+		return a.renderSingleView()
 	}
 
 	// Render header
@@ -282,8 +582,22 @@ func (a *App) View() string {
 
 	// Render main content based on view mode
 	var content string
-	if a.viewMode == ViewZoomed {
+	if a.paletteMode {
+		content = a.renderPalette()
+	} else if a.helpMode {
+		content = a.renderHelp()
+	} else if len(a.paneOrder) == 0 {
+		empty := "No sources in this group yet"
+		if a.mutedCount() > 0 {
+			empty = "Every source here is muted, press U to unmute"
+		}
+		content = a.styles.EmptyState.Width(a.width).Height(a.contentHeight()).Render(empty)
+	} else if a.viewMode == ViewZoomed {
 		content = a.renderZoomedView()
+	} else if a.viewMode == ViewDigest {
+		content = a.renderDigestView()
+	} else if a.viewMode == ViewTrace {
+		content = a.renderTraceView()
 	} else {
 		content = a.renderMultiPaneView()
 	}
@@ -292,12 +606,22 @@ func (a *App) View() string {
 	status := a.renderStatusBar()
 
 	// Combine all parts
-	return lipgloss.JoinVertical(
-		lipgloss.Left,
-		header,
-		content,
-		status,
-	)
+	parts := []string{header, content}
+	if a.showMetrics {
+		parts = append(parts, a.renderMetrics())
+	}
+	parts = append(parts, status)
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// contentHeight returns the rows left for panes once the header, status
+// bar and debug overlay are drawn
+func (a *App) contentHeight() int {
+	height := a.height - 4
+	if a.showMetrics {
+		height--
+	}
+	return height
 }
 
 // renderHeader creates the application header
@@ -313,16 +637,31 @@ func (a *App) renderHeader() string {
 		layoutStr = "Vertical"
 	case LayoutAutoGrid:
 		layoutStr = "Grid"
+	case LayoutProportional:
+		layoutStr = "Proportional"
 	}
 
-	if a.viewMode == ViewZoomed && len(a.paneOrder) > 0 {
-		zoomedSource := a.paneOrder[a.zoomedPane]
-		layoutStr = fmt.Sprintf("ZOOMED: [%d] %s", a.zoomedPane+1, zoomedSource)
+	if i, ok := a.zoomedIndex(); ok && a.viewMode == ViewZoomed {
+		zoomedSource := truncate(a.zoomedSource, maxSourceNameWidth)
+		layoutStr = fmt.Sprintf("ZOOMED: [%d] %s", i+1, zoomedSource)
+	}
+	if a.viewMode == ViewDigest {
+		layoutStr = fmt.Sprintf("DIGEST (by %s)", a.digestSort)
+	}
+	if a.viewMode == ViewTrace {
+		layoutStr = fmt.Sprintf("TRACE %s", truncate(a.traceID, maxSourceNameWidth))
 	}
 
-	controls := "[q]uit [L]ayout [z]oom [/]search [?]help"
+	controls := "[q]uit [L]ayout [z]oom [/]search [^P]commands"
 
-	headerContent := fmt.Sprintf("%s │ %s │ %s │ %s", title, sourceCount, layoutStr, controls)
+	// Hide the controls hint first, then the layout name, then the tabs
+	fields := []string{title, sourceCount, layoutStr, controls}
+	dropOrder := []int{3, 2}
+	if len(a.groups) > 0 {
+		fields = append(fields, a.renderTabs())
+		dropOrder = append(dropOrder, 4)
+	}
+	headerContent := fitFields(fields, dropOrder, a.width-a.styles.Header.GetHorizontalFrameSize())
 
 	return a.styles.Header.Width(a.width).Render(headerContent)
 }
@@ -333,7 +672,7 @@ func (a *App) renderMultiPaneView() string {
 		return ""
 	}
 
-	contentHeight := a.height - 4 // Account for header and status bar
+	contentHeight := a.contentHeight()
 
 	switch a.layout {
 	case LayoutHorizontal:
@@ -342,6 +681,8 @@ func (a *App) renderMultiPaneView() string {
 		return a.renderVerticalLayout(contentHeight)
 	case LayoutAutoGrid:
 		return a.renderGridLayout(contentHeight)
+	case LayoutProportional:
+		return a.renderProportionalLayout(contentHeight)
 	}
 
 	return ""
@@ -349,39 +690,189 @@ func (a *App) renderMultiPaneView() string {
 
 // renderZoomedView renders a single pane in full screen
 func (a *App) renderZoomedView() string {
-	if a.zoomedPane >= len(a.paneOrder) {
-		return "Invalid pane"
+	if _, ok := a.zoomedIndex(); !ok {
+		return a.renderMultiPaneView()
 	}
 
-	paneName := a.paneOrder[a.zoomedPane]
-	pane := a.panes[paneName]
+	pane := a.panes[a.zoomedSource]
+
+	return pane.Render(a.width, a.contentHeight(), true, a.viewOptions())
+}
+
+// singleView reports whether the focused source takes the whole terminal,
+// as it does when it is the only source or with --single. The digest and
+// trace views keep the dashboard.
+func (a *App) singleView() bool {
+	if len(a.paneOrder) == 0 || (a.viewMode != ViewMultiPane && a.viewMode != ViewZoomed) {
+		return false
+	}
+	return a.single || len(a.panes) == 1
+}
+
+// renderSingleView shows the focused source like tail -f: its lines fill
+// the terminal, without header or borders, above a one-line status
+func (a *App) renderSingleView() string {
+	pane := a.panes[a.paneOrder[a.focusedPane]]
+
+	height := a.height - 1
+	if a.showMetrics {
+		height--
+	}
+	parts := []string{pane.RenderPlain(a.width, max(1, height), a.viewOptions())}
+	if a.showMetrics {
+		parts = append(parts, a.renderMetrics())
+	}
+	parts = append(parts, a.renderSingleStatus(pane))
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// renderSingleStatus creates the status line of the single source view:
+// the source, where the view is and whatever deviates from the defaults.
+// Prompts take it over as they do the status bar.
+func (a *App) renderSingleStatus(pane *Pane) string {
+	if a.gotoMode || a.presetMode || a.pipeMode || a.confirmClear {
+		return a.renderStatusBar()
+	}
+
+	name := pane.name
+	if len(a.paneOrder) > 1 {
+		name = fmt.Sprintf("[%d/%d] %s", a.focusedPane+1, len(a.paneOrder), name)
+	}
+	position := pane.scrollIndicator(pane.cache.total, pane.height-2)
+	if pane.IsHolding() {
+		position = "PAUSED ON ERROR (any key resumes)"
+	} else if a.paused {
+		position = "PAUSED"
+	}
+	status := []string{name, position}
+
+	if a.filterLevel != log.LowestLevel() || a.contextLines > 0 {
+		filter := fmt.Sprintf("Filter: %s", a.filterLevel)
+		if a.contextLines > 0 {
+			filter += fmt.Sprintf(" (+%d context)", a.contextLines)
+		}
+		status = append(status, filter)
+	}
+	if a.lastWindow > 0 {
+		status = append(status, "Last: "+formatWindow(a.lastWindow))
+	}
+	if a.fuzzyMode {
+		status = append(status, fmt.Sprintf("Fuzzy: %s▏", a.fuzzyQuery))
+	} else if a.fuzzyQuery != "" {
+		status = append(status, fmt.Sprintf("Fuzzy: %s", a.fuzzyQuery))
+	}
+	if a.searchMode != SearchNone || a.searchQuery != "" {
+		status = append(status, fmt.Sprintf("/%s (%d matches)", a.searchQuery, len(a.searchResults)))
+	}
+	if pane.rateAlert {
+		status = append(status, "errors >"+pane.errorRate.String())
+	}
+	if pane.pipe != nil {
+		status = append(status, "| "+truncate(pane.pipe.Command(), maxSourceNameWidth))
+	}
+	if a.sourceError != "" {
+		status = append(status, a.sourceError)
+	}
+	if a.notice != "" {
+		status = append(status, a.notice)
+	}
 
-	contentHeight := a.height - 4
-	return pane.Render(a.width, contentHeight, true, a.filterLevel, a.followMode)
+	// The name and position stay, the rest is cut from the end
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("243")).Width(a.width).
+		Render(fitFields(status, nil, a.width))
 }
 
 // renderStatusBar creates the bottom status bar
 func (a *App) renderStatusBar() string {
+	width := a.width - a.styles.StatusBar.GetHorizontalFrameSize()
+
+	// The go to prompt takes over the whole bar while typing
+	if a.gotoMode {
+		prompt := fmt.Sprintf("Go to: %s", a.gotoQuery)
+		if matches := a.gotoMatches(); len(matches) > 0 {
+			prompt += " → " + strings.Join(matches, ", ")
+		} else {
+			prompt += " (no match)"
+		}
+		return a.styles.StatusBar.Width(a.width).Render(truncate(prompt, width))
+	}
+	if a.paletteMode {
+		prompt := fmt.Sprintf("Command: %s▏ (↑/↓ select, enter runs, esc closes)", a.paletteQuery)
+		return a.styles.StatusBar.Width(a.width).Render(truncate(prompt, width))
+	}
+	if a.helpMode {
+		prompt := fmt.Sprintf("Help: %s▏ (type to search, ↑/↓ scroll, esc closes)", a.helpQuery)
+		return a.styles.StatusBar.Width(a.width).Render(truncate(prompt, width))
+	}
+	if a.presetMode {
+		prompt := fmt.Sprintf("Save filters as preset: %s▏ (enter saves, esc cancels)", a.presetQuery)
+		return a.styles.StatusBar.Width(a.width).Render(truncate(prompt, width))
+	}
+	if a.pipeMode {
+		prompt := fmt.Sprintf("Pipe %s to: %s▏ (enter starts, esc cancels)", a.paneOrder[a.focusedPane], a.pipeQuery)
+		return a.styles.StatusBar.Width(a.width).Render(truncate(prompt, width))
+	}
+	if a.confirmClear {
+		prompt := fmt.Sprintf("Clear all %d panes? (y/n)", len(a.panes))
+		return a.styles.StatusBar.Width(a.width).Render(truncate(prompt, width))
+	}
+
 	var status []string
 
 	// Active sources
 	status = append(status, fmt.Sprintf("%d active sources", len(a.paneOrder)))
+	if len(a.hiddenPanes) > 0 {
+		status = append(status, fmt.Sprintf("%d sources hidden", len(a.hiddenPanes)))
+	}
+	if muted := a.mutedCount(); muted > 0 {
+		status = append(status, fmt.Sprintf("%d muted", muted))
+	}
 
 	// Filter level
-	status = append(status, fmt.Sprintf("Filter: %s", a.filterLevel))
+	if a.contextLines > 0 {
+		status = append(status, fmt.Sprintf("Filter: %s (+%d context)", a.filterLevel, a.contextLines))
+	} else {
+		status = append(status, fmt.Sprintf("Filter: %s", a.filterLevel))
+	}
+
+	// Relative time window
+	if a.lastWindow > 0 {
+		status = append(status, "Last: "+formatWindow(a.lastWindow))
+	}
+
+	// Time column
+	if a.timeColumn == TimeIngest {
+		status = append(status, "Time: received")
+	}
+
+	// Fuzzy filter
+	if a.fuzzyMode {
+		status = append(status, fmt.Sprintf("Fuzzy: %s▏", a.fuzzyQuery))
+	} else if a.fuzzyQuery != "" {
+		status = append(status, fmt.Sprintf("Fuzzy: %s", a.fuzzyQuery))
+	}
 
 	// Search info
 	if a.searchQuery != "" {
-		if a.searchMode == SearchLocal {
-			status = append(status, fmt.Sprintf("Search: /%s", a.searchQuery))
+		matches := fmt.Sprintf("%d matches", len(a.searchResults))
+		if a.searching {
+			matches = "searching…"
+		}
+		if a.searchScope == SearchLocal {
+			status = append(status, fmt.Sprintf("Search: /%s (%s)", a.searchQuery, matches))
 		} else {
-			status = append(status, fmt.Sprintf("Global: /%s", a.searchQuery))
+			status = append(status, fmt.Sprintf("Global: /%s (%s)", a.searchQuery, matches))
 		}
 	}
 
+	// Aggregate load
+	if a.showRate {
+		status = append(status, a.renderThroughput())
+	}
+
 	// Current pane
 	if len(a.paneOrder) > 0 {
-		currentPane := a.paneOrder[a.focusedPane]
+		currentPane := truncate(a.paneOrder[a.focusedPane], maxSourceNameWidth)
 		status = append(status, fmt.Sprintf("Pane: %d (%s)", a.focusedPane+1, currentPane))
 	}
 
@@ -389,11 +880,65 @@ func (a *App) renderStatusBar() string {
 	if a.paused {
 		status = append(status, "PAUSED")
 	}
+	for _, pane := range a.panes {
+		if pane.IsHolding() {
+			status = append(status, "PAUSED ON ERROR (any key resumes)")
+			break
+		}
+	}
 
-	statusText := strings.Join(status, " │ ")
+	// Last failure of a source attached from the picker
+	if a.sourceError != "" {
+		status = append(status, a.sourceError)
+	}
+	if a.notice != "" {
+		status = append(status, a.notice)
+	}
+
+	// Drop the source count first, then the filter level
+	statusText := fitFields(status, []int{0, 1}, width)
 	return a.styles.StatusBar.Width(a.width).Render(statusText)
 }
 
+// viewOptions collects the display settings passed to every pane
+func (a *App) viewOptions() ViewOptions {
+	return ViewOptions{
+		FilterLevel:  a.filterLevel,
+		ContextLines: a.contextLines,
+		FollowMode:   a.followMode,
+		Truncation:   a.truncateMode,
+		LevelAlign:   a.levelAlign,
+		LevelStyle:   a.levelStyle,
+		FuzzyQuery:   a.fuzzyQuery,
+		TimeColumn:   a.timeColumn,
+		ShowMetadata: a.showMetadata,
+		Fields:       a.inlineFields(),
+		Sparkline:    a.showSparkline,
+		LevelCounts:  a.showCounts,
+		Highlights:   a.highlights,
+		Since:        a.windowStart(),
+	}
+}
+
+// windowStart returns the oldest time --last lets through, zero without a
+// window. It moves in whole seconds, so panes re-render for it once a
+// tick rather than every frame.
+func (a *App) windowStart() time.Time {
+	if a.lastWindow <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-a.lastWindow).Truncate(time.Second)
+}
+
+// inlineFields returns the metadata keys to append to each line, none
+// while they are toggled off
+func (a *App) inlineFields() []string {
+	if !a.showFields {
+		return nil
+	}
+	return a.fields
+}
+
 // Layout helper methods
 func (a *App) cycleLayout() {
 	switch a.layout {
@@ -402,6 +947,8 @@ func (a *App) cycleLayout() {
 	case LayoutVertical:
 		a.layout = LayoutAutoGrid
 	case LayoutAutoGrid:
+		a.layout = LayoutProportional
+	case LayoutProportional:
 		a.layout = LayoutHorizontal
 	}
 	a.updateLayout()
@@ -444,6 +991,106 @@ func (a *App) clearFocusedPane() {
 			pane.Clear()
 		}
 	}
+	a.refreshTrace()
+}
+
+// clearAllPanes empties every pane, including those outside the active
+// group, for a clean slate
+func (a *App) clearAllPanes() {
+	for _, pane := range a.panes {
+		pane.Clear()
+	}
+	a.searchResults = nil
+	a.refreshTrace()
+}
+
+func (a *App) toggleCollapseFocusedPane() {
+	if len(a.paneOrder) > 0 {
+		paneName := a.paneOrder[a.focusedPane]
+		if pane := a.panes[paneName]; pane != nil {
+			pane.ToggleCollapsed()
+			a.updateLayout()
+		}
+	}
+}
+
+// trackViewed marks the pane focus has just left as viewed, so the lines
+// arriving in it from now on follow a "new" divider
+func (a *App) trackViewed() {
+	current := ""
+	if len(a.paneOrder) > 0 {
+		current = a.paneOrder[a.focusedPane]
+	}
+	if current == a.lastFocused {
+		return
+	}
+	if pane := a.panes[a.lastFocused]; pane != nil {
+		pane.MarkViewed()
+	}
+	a.lastFocused = current
+}
+
+// moveFocusedPane swaps the focused pane with its neighbour delta places
+// along, keeping focus on it. The swap is made in allPanes too, so the
+// order survives group switches and muting; new sources still go last.
+func (a *App) moveFocusedPane(delta int) {
+	target := a.focusedPane + delta
+	if len(a.paneOrder) < 2 || target < 0 || target >= len(a.paneOrder) {
+		return
+	}
+
+	moved, other := a.paneOrder[a.focusedPane], a.paneOrder[target]
+	a.paneOrder[a.focusedPane], a.paneOrder[target] = other, moved
+
+	i, j := slices.Index(a.allPanes, moved), slices.Index(a.allPanes, other)
+	if i >= 0 && j >= 0 {
+		a.allPanes[i], a.allPanes[j] = other, moved
+	}
+
+	a.focusedPane = target
+	a.updateLayout()
+}
+
+// sortIdlePanes moves idle panes after active ones when enabled, keeping
+// focus on the same pane
+func (a *App) sortIdlePanes() {
+	if !a.sortIdle || len(a.paneOrder) < 2 {
+		return
+	}
+
+	focused := a.paneOrder[a.focusedPane]
+
+	sort.SliceStable(a.paneOrder, func(i, j int) bool {
+		return !a.panes[a.paneOrder[i]].IsIdle() && a.panes[a.paneOrder[j]].IsIdle()
+	})
+
+	for i, name := range a.paneOrder {
+		if name == focused {
+			a.focusedPane = i
+		}
+	}
+}
+
+// zoomIn zooms into the pane at index i of paneOrder
+func (a *App) zoomIn(i int) {
+	a.viewMode = ViewZoomed
+	a.zoomedSource = a.paneOrder[i]
+}
+
+// zoomedIndex returns where the zoomed source sits in paneOrder, and false
+// when it is not shown
+func (a *App) zoomedIndex() (int, bool) {
+	i := slices.Index(a.paneOrder, a.zoomedSource)
+	return i, i >= 0
+}
+
+// dropStaleZoom falls back to the multi-pane view once the zoomed source
+// is no longer shown, after it was hidden, muted or left the group tab
+func (a *App) dropStaleZoom() {
+	if _, ok := a.zoomedIndex(); a.viewMode == ViewZoomed && !ok {
+		a.viewMode = ViewMultiPane
+		a.zoomedSource = ""
+	}
 }
 
 func (a *App) updateLayout() {
@@ -451,14 +1098,51 @@ func (a *App) updateLayout() {
 	// Implementation depends on the specific layout algorithms
 }
 
+func (a *App) handlePickerInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		a.picker.MoveUp()
+	case "down", "j":
+		a.picker.MoveDown()
+	case " ":
+		a.picker.Toggle()
+	case "r":
+		a.picker.loading = true
+		return a, loadContainers()
+	case "enter":
+		var cmds []tea.Cmd
+		for _, item := range a.picker.Chosen() {
+			cmds = append(cmds, attachContainer(item, a.server))
+		}
+		return a, tea.Batch(cmds...)
+	default:
+		// The pane commands need a pane, but quitting works from here too
+		if cmd, ok := a.commandFor(msg.String()); ok && cmd.name == "Quit" {
+			return a, cmd.run()
+		}
+	}
+	return a, nil
+}
+
 func (a *App) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter":
-		a.performSearch()
+		// Results arrive as the query is typed, a search still running
+		// keeps going
+		a.searchHistory.Add(a.searchQuery)
 		a.searchMode = SearchNone
+		return a, nil
 	case "esc":
 		a.searchMode = SearchNone
 		a.searchQuery = ""
+		a.searchHistory.Reset()
+		a.cancelSearch()
+		a.searchResults = nil
+		return a, nil
+	case "up":
+		a.searchQuery = a.searchHistory.Prev(a.searchQuery)
+	case "down":
+		a.searchQuery = a.searchHistory.Next()
 	case "backspace":
 		if len(a.searchQuery) > 0 {
 			a.searchQuery = a.searchQuery[:len(a.searchQuery)-1]
@@ -466,27 +1150,82 @@ func (a *App) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	default:
 		if len(msg.String()) == 1 {
 			a.searchQuery += msg.String()
+		} else {
+			return a, nil
 		}
 	}
-	return a, nil
+
+	// Search as the query changes, replacing any search still running
+	return a, a.performSearch()
 }
 
-func (a *App) performSearch() {
-	// Implementation for search functionality
-	a.searchResults = []SearchResult{}
+// handleGotoInput edits the go to source prompt. Tab completes the best
+// match, enter focuses it.
+func (a *App) handleGotoInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		if matches := a.gotoMatches(); len(matches) > 0 {
+			a.focusPaneByName(matches[0])
+		}
+		a.gotoMode = false
+	case "esc":
+		a.gotoMode = false
+	case "tab":
+		if matches := a.gotoMatches(); len(matches) > 0 {
+			a.gotoQuery = matches[0]
+		}
+	case "backspace":
+		if len(a.gotoQuery) > 0 {
+			runes := []rune(a.gotoQuery)
+			a.gotoQuery = string(runes[:len(runes)-1])
+		}
+	default:
+		if msg.Type == tea.KeyRunes {
+			a.gotoQuery += string(msg.Runes)
+		}
+	}
+	return a, nil
+}
 
-	if a.searchMode == SearchLocal && len(a.paneOrder) > 0 {
-		// Search current pane only
-		paneName := a.paneOrder[a.focusedPane]
-		if pane := a.panes[paneName]; pane != nil {
-			// Implement search in pane
+// handleFuzzyInput edits the fuzzy filter, which applies as it is typed.
+// Enter keeps the filter, esc clears it.
+func (a *App) handleFuzzyInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		a.fuzzyMode = false
+	case "esc":
+		a.fuzzyMode = false
+		a.fuzzyQuery = ""
+	case "backspace":
+		if len(a.fuzzyQuery) > 0 {
+			runes := []rune(a.fuzzyQuery)
+			a.fuzzyQuery = string(runes[:len(runes)-1])
+		}
+	default:
+		if msg.Type == tea.KeyRunes {
+			a.fuzzyQuery += string(msg.Runes)
 		}
-	} else if a.searchMode == SearchGlobal {
-		// Search all panes
-		for _, paneName := range a.paneOrder {
-			if pane := a.panes[paneName]; pane != nil {
-				// Implement search across all panes
+	}
+	return a, nil
+}
+
+// gotoMatches returns the visible source names fuzzily matching the go to
+// query, best first
+func (a *App) gotoMatches() []string {
+	return fuzzyRank(a.gotoQuery, a.paneOrder)
+}
+
+// focusPaneByName focuses the visible pane of the named source, zooming
+// into it instead when a pane is zoomed
+func (a *App) focusPaneByName(name string) {
+	for i, paneName := range a.paneOrder {
+		if paneName == name {
+			if a.viewMode == ViewZoomed {
+				a.zoomedSource = name
 			}
+			a.focusedPane = i
+			a.updateLayout()
+			return
 		}
 	}
 }
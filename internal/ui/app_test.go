@@ -0,0 +1,409 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/ipc"
+	"github.com/Yriskit-ai/logflow/internal/log"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestApp returns an app on a server in a temporary directory, with a
+// pane for each of sources
+func newTestApp(t *testing.T, sources ...string) *App {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the server listens on a Unix socket")
+	}
+
+	server, err := ipc.NewServerAt(filepath.Join(t.TempDir(), "logflow.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	a := NewApp(server)
+	for _, source := range sources {
+		a.handleLogEntry(log.LogEntry{Source: source, Level: log.LogLevelInfo, Content: "started"})
+	}
+	return a
+}
+
+// press sends key to the app and reports whether it quit
+func press(a *App, key tea.KeyMsg) bool {
+	_, cmd := a.handleKeyPress(key)
+	return quits(cmd)
+}
+
+var (
+	keyQ     = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}
+	keyCtrlC = tea.KeyMsg{Type: tea.KeyCtrlC}
+)
+
+func TestQuitKey(t *testing.T) {
+	if !press(newTestApp(t, "api"), keyQ) {
+		t.Error("q did not quit over the panes")
+	}
+	if !press(newTestApp(t), keyQ) {
+		t.Error("q did not quit from the container picker")
+	}
+}
+
+func TestQuitKeyTypedIntoPrompts(t *testing.T) {
+	tests := []struct {
+		name  string
+		open  func(a *App)
+		typed func(a *App) string
+	}{
+		{"go to source", func(a *App) { a.gotoMode = true }, func(a *App) string { return a.gotoQuery }},
+		{"fuzzy filter", func(a *App) { a.fuzzyMode = true }, func(a *App) string { return a.fuzzyQuery }},
+		{"search", func(a *App) { a.searchMode = SearchLocal }, func(a *App) string { return a.searchQuery }},
+		{"command palette", func(a *App) { a.openPalette() }, func(a *App) string { return a.paletteQuery }},
+		{"key binding help", func(a *App) { a.openHelp() }, func(a *App) string { return a.helpQuery }},
+		{"preset name", func(a *App) { a.presetMode = true }, func(a *App) string { return a.presetQuery }},
+		{"pipe command", func(a *App) { a.pipeMode = true }, func(a *App) string { return a.pipeQuery }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestApp(t, "api", "db")
+			tt.open(a)
+			if press(a, keyQ) {
+				t.Fatal("q quit instead of being typed")
+			}
+			if got := tt.typed(a); got != "q" {
+				t.Errorf("prompt holds %q after typing q", got)
+			}
+			if !press(a, keyCtrlC) {
+				t.Error("ctrl+c did not quit from the prompt")
+			}
+		})
+	}
+}
+
+func TestQuitKeyCancelsClearAll(t *testing.T) {
+	a := newTestApp(t, "api")
+	a.confirmClear = true
+	if press(a, keyQ) {
+		t.Fatal("q quit while confirming clearing every pane")
+	}
+	if a.confirmClear {
+		t.Error("q did not cancel clearing every pane")
+	}
+	if a.panes["api"].buffer.Count() != 1 {
+		t.Error("q confirmed clearing every pane")
+	}
+}
+
+func TestSpillFilesPerSource(t *testing.T) {
+	// Each pair sanitizes to the same dump name
+	sources := []string{"a/b", "a_b", "api#2", "api_2", "api"}
+	a := newTestApp(t)
+	a.SetSpillDir(t.TempDir())
+	a.SetBufferSize(1)
+	for _, source := range sources {
+		for i := 0; i < 2; i++ {
+			a.handleLogEntry(log.LogEntry{Source: source, Level: log.LogLevelInfo, Content: source})
+		}
+	}
+
+	names := make(map[string]string)
+	for _, source := range sources {
+		name := spillFileName(source)
+		if other, ok := names[name]; ok {
+			t.Errorf("%q and %q share the spill file %s", source, other, name)
+		}
+		names[name] = source
+
+		var spilled []string
+		a.panes[source].buffer.EachSpilled(func(entry log.LogEntry) error {
+			spilled = append(spilled, entry.Content)
+			return nil
+		})
+		if len(spilled) != 1 || spilled[0] != source {
+			t.Errorf("%s spilled %q, want its own entry", source, spilled)
+		}
+	}
+}
+
+// deliver runs cmd and the commands it batches, passing what they return
+// back to the app
+func deliver(a *App, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case nil:
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			deliver(a, cmd)
+		}
+	default:
+		_, next := a.Update(msg)
+		deliver(a, next)
+	}
+}
+
+func TestStartPresetAppliedWithFirstEntry(t *testing.T) {
+	a := newTestApp(t)
+	path := filepath.Join(t.TempDir(), "presets.json")
+	saved := `[{"name":"timeouts","level":"warn","search":"timeout"}]`
+	if err := os.WriteFile(path, []byte(saved), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SetPresetsFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.ApplyPreset("missing"); err == nil {
+		t.Error("ApplyPreset accepted an unknown preset")
+	}
+	if err := a.ApplyPreset("timeouts"); err != nil {
+		t.Fatal(err)
+	}
+	if a.filterLevel != log.LowestLevel() {
+		t.Errorf("the preset was applied before any entry arrived")
+	}
+
+	_, cmd := a.Update(LogEntryMsg{Entry: log.LogEntry{Source: "api", Level: log.LogLevelWarn, Content: "upstream timeout"}})
+	deliver(a, cmd)
+
+	if a.filterLevel != log.LogLevelWarn || a.presetName != "timeouts" {
+		t.Errorf("after the first entry level = %s, preset = %q", a.filterLevel, a.presetName)
+	}
+	if a.searching {
+		t.Error("the preset's search never finished")
+	}
+	if len(a.searchResults) != 1 || a.searchResults[0].Entry.Content != "upstream timeout" {
+		t.Errorf("search results = %+v, want the first entry", a.searchResults)
+	}
+
+	// Applied once only
+	a.filterLevel = log.LogLevelError
+	deliver(a, func() tea.Msg { return LogEntryMsg{Entry: log.LogEntry{Source: "api", Level: log.LogLevelInfo}} })
+	if a.filterLevel != log.LogLevelError {
+		t.Error("the preset was applied again on a later entry")
+	}
+}
+
+// quits reports whether cmd quits the app
+func quits(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	_, quit := cmd().(tea.QuitMsg)
+	return quit
+}
+
+func TestExitOnIdleWaitsForWatchedSources(t *testing.T) {
+	a := newTestApp(t)
+	a.SetWatched([]string{"api", "worker"})
+	a.SetExitOnIdle(time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		if quits(a.checkIdle()) {
+			t.Fatal("quit before any watched source connected")
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	a.lastActivity = time.Now()
+	a.handleLogEntry(log.LogEntry{Source: "api", Level: log.LogLevelInfo, Content: "started"})
+	a.checkIdle()
+	time.Sleep(2 * time.Millisecond)
+	if !quits(a.checkIdle()) {
+		t.Error("did not quit once the source that came had gone idle")
+	}
+}
+
+// addEntries sends one entry from each source, a millisecond apart so
+// their activity is ordered
+func addEntries(a *App, sources ...string) {
+	for _, source := range sources {
+		a.handleLogEntry(log.LogEntry{Source: source, Level: log.LogLevelInfo, Content: source})
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHiddenPanes(t *testing.T) {
+	a := newTestApp(t)
+	a.SetMaxPanes(2, false)
+	addEntries(a, "a", "b", "c", "d")
+
+	if !a.isHidden("a") || !a.isHidden("b") || a.isHidden("c") || a.isHidden("d") {
+		t.Fatalf("hidden = %v, want a and b", a.hiddenPanes)
+	}
+	if got := strings.Join(a.retainedPanes(), ","); got != "c,d,a,b" {
+		t.Errorf("retainedPanes = %s, want the shown ones then the hidden ones by age", got)
+	}
+
+	// An entry brings a hidden source back, hiding the least active one
+	addEntries(a, "a")
+	if a.isHidden("a") || !a.isHidden("c") || len(a.hiddenPanes) != 2 {
+		t.Errorf("hidden after a came back = %v, want b and c", a.hiddenPanes)
+	}
+
+	// Kept buffers stay reachable after their feeders are gone
+	a.forgetExited()
+	if len(a.hiddenPanes) != 2 {
+		t.Errorf("forgetExited dropped hidden sources with kept buffers: %v", a.hiddenPanes)
+	}
+}
+
+func TestHiddenPanesDroppedAreForgottenOnExit(t *testing.T) {
+	a := newTestApp(t)
+	a.SetMaxPanes(1, true)
+	addEntries(a, "a", "b", "c")
+	if len(a.hiddenPanes) != 2 || len(a.panes) != 1 {
+		t.Fatalf("hidden = %v with %d panes, want 2 hidden and 1 pane", a.hiddenPanes, len(a.panes))
+	}
+
+	// None of the sources has a feeder connected
+	a.forgetExited()
+	if len(a.hiddenPanes) != 0 {
+		t.Errorf("hidden after the feeders left = %v, want none", a.hiddenPanes)
+	}
+
+	// A source that comes back gets a pane again
+	addEntries(a, "a")
+	if _, ok := a.panes["a"]; !ok || a.isHidden("a") || !a.isHidden("c") {
+		t.Errorf("after a came back: hidden = %v", a.hiddenPanes)
+	}
+}
+
+func TestFilterKeysFollowLevelRegistry(t *testing.T) {
+	builtin := log.Levels()
+	custom := []log.LevelDef{{Name: "TRACE"}, {Name: "NOTICE"}, {Name: "ALERT"}, {Name: "FATAL"}}
+	if err := log.SetLevels(custom, "NOTICE"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { log.SetLevels(builtin, log.LogLevelInfo) })
+
+	a := newTestApp(t, "api")
+	for key, want := range map[string]log.LogLevel{"e": "FATAL", "w": "ALERT", "i": "NOTICE", "a": "TRACE"} {
+		press(a, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		if a.filterLevel != want {
+			t.Errorf("%s set the filter to %s, want %s", key, a.filterLevel, want)
+		}
+	}
+}
+
+func TestPipeFollowsPaneFilter(t *testing.T) {
+	a := newTestApp(t, "api", "db")
+	a.focusedPane = 0
+	path := filepath.Join(t.TempDir(), "piped.log")
+	// The command it returns waits for the pipe to exit
+	a.startPipe("cat > " + path)
+	pipe := a.panes["api"].pipe
+	if pipe == nil {
+		t.Fatal("no pipe started")
+	}
+
+	write := func(level log.LogLevel, content string) {
+		pipe.Write(log.LogEntry{Source: "api", Level: level, Content: content})
+	}
+	write(log.LogLevelDebug, "starting up")
+
+	// Later filter changes reach the running pipe
+	press(a, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	a.Update(HoldExpiredMsg{})
+	write(log.LogLevelInfo, "request timeout")
+	write(log.LogLevelWarn, "slow request")
+
+	a.fuzzyQuery = "tmout"
+	a.Update(HoldExpiredMsg{})
+	write(log.LogLevelWarn, "retry after timeout")
+	write(log.LogLevelWarn, "disk at 91%")
+
+	// The fuzzy filter narrows the focused pane only
+	a.focusedPane = 1
+	a.searchScope, a.searchQuery = SearchGlobal, "disk"
+	a.Update(HoldExpiredMsg{})
+	write(log.LogLevelWarn, "timeout again")
+	write(log.LogLevelError, "disk full")
+
+	// Stopping waits for the command to write everything out
+	a.stopPipes()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		got = append(got, line[strings.Index(line, "] ")+2:])
+	}
+	want := []string{"starting up", "slow request", "retry after timeout", "disk full"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("piped %q, want %q", got, want)
+	}
+}
+
+// traceContents lists the contents of a trace's entries in order
+func traceContents(entries []log.LogEntry) string {
+	var contents []string
+	for _, entry := range entries {
+		contents = append(contents, entry.Content)
+	}
+	return strings.Join(contents, ",")
+}
+
+func TestTraceViewFollowsArrivingEntries(t *testing.T) {
+	a := newTestApp(t)
+	a.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	traced := func(source string, second int, id string) log.LogEntry {
+		return log.LogEntry{
+			Timestamp: start.Add(time.Duration(second) * time.Second),
+			Source:    source,
+			Level:     log.LogLevelInfo,
+			Content:   fmt.Sprintf("%s at %d", source, second),
+			Metadata:  map[string]interface{}{"trace_id": id},
+		}
+	}
+	a.handleLogEntry(traced("api", 1, "t1"))
+	a.handleLogEntry(traced("db", 3, "t1"))
+	a.handleLogEntry(traced("db", 4, "t2"))
+	a.focusedPane = 0
+	a.View() // Panes collect the entries they show when rendered
+
+	a.toggleTrace()
+	if a.viewMode != ViewTrace || a.traceID != "t1" || len(a.trace) != 2 {
+		t.Fatalf("trace view on %q with %d entries, want t1 with 2", a.traceID, len(a.trace))
+	}
+
+	// Entries of the trace join in merge order, others stay out. db runs
+	// backwards, so its second entry stays after its first.
+	a.handleLogEntry(traced("db", 2, "t1"))
+	a.handleLogEntry(traced("api", 5, "t2"))
+	a.handleLogEntry(traced("api", 2, "t1"))
+	a.handleLogEntry(traced("api", 3, "t1"))
+	if got, want := traceContents(a.trace), "api at 1,api at 2,api at 3,db at 3,db at 2"; got != want {
+		t.Errorf("trace = %s, want %s", got, want)
+	}
+
+	// Arrivals land where collecting the trace afresh puts them
+	arrived := traceContents(a.trace)
+	a.refreshTrace()
+	if collected := traceContents(a.trace); collected != arrived {
+		t.Errorf("trace collected afresh = %s, built on arrival = %s", collected, arrived)
+	}
+
+	// A dropped pane takes its entries out of both the count and the list
+	a.dropHidden = true
+	a.hidePane("db")
+	view := a.renderTraceView()
+	if !strings.Contains(view, "3 entries across 1 sources") || strings.Contains(view, "db at") {
+		t.Errorf("trace view after dropping db:\n%s", view)
+	}
+
+	a.toggleTrace()
+	if a.viewMode != ViewMultiPane || a.trace != nil {
+		t.Error("closing the trace view kept the trace")
+	}
+}
@@ -0,0 +1,52 @@
+package ui
+
+import "fmt"
+
+// boostFactor is how much each boost multiplies a pane's buffer capacity
+const boostFactor = 10
+
+// maxBoost caps how far a pane's capacity can be boosted past its own
+const maxBoost = 100
+
+// boostFocused multiplies the focused pane's buffer capacity, so a
+// reproduction about to start keeps far more scrollback. Boosting again
+// multiplies further, up to maxBoost times the original capacity.
+func (a *App) boostFocused() {
+	if len(a.paneOrder) == 0 {
+		return
+	}
+
+	pane := a.panes[a.paneOrder[a.focusedPane]]
+	original := pane.boostedFrom
+	if original == 0 {
+		original = pane.buffer.Capacity()
+	}
+	size := pane.buffer.Capacity() * boostFactor
+	if size > original*maxBoost {
+		a.notice = fmt.Sprintf("%s is already boosted to %s entries", pane.name, formatCount(pane.buffer.Capacity()))
+		return
+	}
+
+	pane.buffer.Resize(size)
+	pane.boostedFrom = original
+	pane.dirty = true
+	a.notice = fmt.Sprintf("%s boosted to %s entries", pane.name, formatCount(size))
+}
+
+// resetBoostFocused returns the focused pane's buffer to the capacity it
+// had before being boosted, dropping the oldest entries past it
+func (a *App) resetBoostFocused() {
+	if len(a.paneOrder) == 0 {
+		return
+	}
+
+	pane := a.panes[a.paneOrder[a.focusedPane]]
+	if pane.boostedFrom == 0 {
+		return
+	}
+
+	pane.buffer.Resize(pane.boostedFrom)
+	pane.boostedFrom = 0
+	pane.dirty = true
+	a.notice = fmt.Sprintf("%s back to %s entries", pane.name, formatCount(pane.buffer.Capacity()))
+}
@@ -0,0 +1,369 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// action is something run by its keys or from the command palette
+type action struct {
+	name string
+	keys []string
+	run  func() tea.Cmd
+}
+
+// command is an action with the category the help overlay lists it under
+type command struct {
+	action
+	category string
+}
+
+// Help categories, in the order the help overlay lists them
+const (
+	categoryNavigation = "Navigation"
+	categoryLayout     = "Layout & View"
+	categorySearch     = "Search & Filter"
+	categoryControl    = "Control"
+)
+
+// helpCategories is the order categories appear in the help overlay
+var helpCategories = []string{categoryNavigation, categoryLayout, categorySearch, categoryControl}
+
+// inCategory files every action in actions under category
+func inCategory(category string, actions []action) []command {
+	cmds := make([]command, len(actions))
+	for i, a := range actions {
+		cmds[i] = command{a, category}
+	}
+	return cmds
+}
+
+// buildCommands returns every action the dashboard offers, bound to the
+// keys in keyMap, in the order the palette lists them
+func (a *App) buildCommands(k KeyMap) []command {
+	do := func(fn func()) func() tea.Cmd {
+		return func() tea.Cmd {
+			fn()
+			return nil
+		}
+	}
+
+	commands := inCategory(categoryNavigation, []action{
+		{"Next pane", k.NextPane, do(a.nextPane)},
+		{"Previous pane", k.PrevPane, do(a.prevPane)},
+		{"Pane to the left", k.NavLeft, do(func() {
+			if a.layout == LayoutVertical {
+				a.prevPane()
+			}
+		})},
+		{"Pane to the right", k.NavRight, do(func() {
+			if a.layout == LayoutVertical {
+				a.nextPane()
+			}
+		})},
+		{"Scroll down or pane below", k.NavDown, do(func() {
+			if a.viewMode == ViewDigest {
+				a.moveDigestCursor(1)
+			} else if a.viewMode == ViewTrace {
+				a.scrollTrace(-1)
+			} else if (a.layout == LayoutHorizontal || a.layout == LayoutProportional) && !a.singleView() {
+				a.nextPane()
+			} else {
+				a.scrollDown()
+			}
+		})},
+		{"Scroll up or pane above", k.NavUp, do(func() {
+			if a.viewMode == ViewDigest {
+				a.moveDigestCursor(-1)
+			} else if a.viewMode == ViewTrace {
+				a.scrollTrace(1)
+			} else if (a.layout == LayoutHorizontal || a.layout == LayoutProportional) && !a.singleView() {
+				a.prevPane()
+			} else {
+				a.scrollUp()
+			}
+		})},
+	})
+
+	for i, key := range k.DirectAccess {
+		paneNum := i
+		commands = append(commands, command{action{fmt.Sprintf("Focus pane %d", i+1), []string{key}, do(func() {
+			if paneNum < len(a.paneOrder) {
+				if a.viewMode == ViewZoomed {
+					a.zoomIn(paneNum)
+				}
+				a.focusedPane = paneNum
+				a.updateLayout()
+			}
+		})}, categoryNavigation})
+	}
+
+	commands = append(commands, inCategory(categoryNavigation, []action{
+		{"Next group tab", k.NextGroup, do(func() { a.switchGroup(1) })},
+		{"Previous group tab", k.PrevGroup, do(func() { a.switchGroup(-1) })},
+		{"Go to source", k.GotoSource, do(func() {
+			a.gotoMode = true
+			a.gotoQuery = ""
+		})},
+		{"Jump to newest error", k.JumpToError, do(a.jumpToError)},
+		{"Follow trace", k.Trace, do(a.toggleTrace)},
+	})...)
+
+	commands = append(commands, inCategory(categoryLayout, []action{
+		{"Cycle layout", k.CycleLayout, do(a.cycleLayout)},
+		{"Zoom into pane", k.Zoom, do(func() {
+			if a.viewMode != ViewZoomed && len(a.paneOrder) > 0 {
+				a.zoomIn(a.focusedPane)
+			} else if a.viewMode == ViewZoomed {
+				a.viewMode = ViewMultiPane
+			}
+			a.updateLayout()
+		})},
+		{"Zoom out", k.ZoomOut, do(func() {
+			if a.viewMode == ViewZoomed {
+				a.viewMode = ViewMultiPane
+				a.updateLayout()
+			}
+		})},
+		{"Open digest selection", k.Open, do(func() {
+			if a.viewMode == ViewDigest && len(a.paneOrder) > 0 {
+				a.zoomIn(a.focusedPane)
+				a.updateLayout()
+			}
+		})},
+		{"Toggle digest view", k.Digest, do(a.toggleDigest)},
+		{"Cycle digest sort", k.DigestSort, do(func() {
+			if a.viewMode == ViewDigest {
+				a.cycleDigestSort()
+			}
+		})},
+		{"Collapse or expand pane", k.Collapse, do(a.toggleCollapseFocusedPane)},
+		{"Move pane earlier", k.MoveLeft, do(func() { a.moveFocusedPane(-1) })},
+		{"Move pane later", k.MoveRight, do(func() { a.moveFocusedPane(1) })},
+		{"Boost pane buffer", k.Boost, do(a.boostFocused)},
+		{"Reset pane buffer", k.ResetBoost, do(a.resetBoostFocused)},
+		{"Mute pane", k.Mute, do(a.muteFocused)},
+		{"Unmute all panes", k.UnmuteAll, do(a.unmuteAll)},
+		{"Sort idle panes last", k.SortIdle, do(func() {
+			a.sortIdle = !a.sortIdle
+			a.sortIdlePanes()
+		})},
+		{"Toggle end or middle truncation", k.Truncation, do(func() {
+			if a.truncateMode == TruncateTail {
+				a.truncateMode = TruncateMiddle
+			} else {
+				a.truncateMode = TruncateTail
+			}
+		})},
+		{"Toggle performance overlay", k.Metrics, do(func() { a.showMetrics = !a.showMetrics })},
+		{"Toggle entry count and rate", k.Throughput, do(func() { a.showRate = !a.showRate })},
+		{"Toggle log or received time", k.TimeColumn, do(func() {
+			if a.timeColumn == TimeEvent {
+				a.timeColumn = TimeIngest
+			} else {
+				a.timeColumn = TimeEvent
+			}
+		})},
+		{"Toggle metadata", k.Metadata, do(func() { a.showMetadata = !a.showMetadata })},
+		{"Toggle inline fields", k.Fields, do(func() { a.showFields = !a.showFields })},
+		{"Toggle sparklines", k.Sparkline, do(func() { a.showSparkline = !a.showSparkline })},
+		{"Toggle level counts", k.LevelCounts, do(func() { a.showCounts = !a.showCounts })},
+	})...)
+
+	commands = append(commands, inCategory(categorySearch, []action{
+		{"Search pane", k.SearchLocal, func() tea.Cmd {
+			a.searchMode = SearchLocal
+			a.searchQuery = ""
+			return a.performSearch()
+		}},
+		{"Search all panes", k.SearchGlobal, func() tea.Cmd {
+			a.searchMode = SearchGlobal
+			a.searchQuery = ""
+			return a.performSearch()
+		}},
+		// Edit the current filter rather than starting over
+		{"Fuzzy filter pane", k.FuzzyFilter, do(func() { a.fuzzyMode = true })},
+		// A custom level scheme lacking these names gets the most severe
+		// levels instead
+		{"Show errors only", k.FilterError, do(func() { a.filterLevel = log.ResolveLevel(log.LogLevelError, 0) })},
+		{"Show warnings and above", k.FilterWarn, do(func() { a.filterLevel = log.ResolveLevel(log.LogLevelWarn, 1) })},
+		{"Show info and above", k.FilterInfo, do(func() { a.filterLevel = log.ResolveLevel(log.LogLevelInfo, 2) })},
+		{"Show all levels", k.FilterAll, do(func() { a.filterLevel = log.LowestLevel() })},
+		{"Toggle context lines", k.Context, do(func() {
+			if a.contextLines == 0 {
+				a.contextLines = defaultContextLines
+			} else {
+				a.contextLines = 0
+			}
+		})},
+		{"Apply next preset", k.NextPreset, a.nextPreset},
+		{"Save filters as preset", k.SavePreset, do(func() {
+			a.presetMode = true
+			a.presetQuery = ""
+		})},
+	})...)
+
+	commands = append(commands, inCategory(categoryControl, []action{
+		{"Pause or resume", k.Pause, func() tea.Cmd {
+			a.paused = !a.paused
+			return a.scheduleTick()
+		}},
+		{"Toggle follow mode", k.Follow, do(func() { a.followMode = !a.followMode })},
+		{"Clear pane", k.Clear, do(a.clearFocusedPane)},
+		{"Clear all panes", k.ClearAll, do(func() { a.confirmClear = true })},
+		{"Dump panes to zip", k.Export, func() tea.Cmd { return a.dumpAll(defaultDumpPath()) }},
+		{"Pipe pane to command", k.Pipe, do(a.togglePipe)},
+		{"Command palette", k.Palette, do(a.openPalette)},
+		{"Key binding help", k.Help, do(a.openHelp)},
+		{"Quit", k.Quit, func() tea.Cmd { return tea.Quit }},
+	})...)
+
+	// Every preset can be applied from the palette by name
+	for _, preset := range a.allPresets() {
+		preset := preset
+		commands = append(commands, command{action{"Preset: " + preset.Name, nil, func() tea.Cmd {
+			return a.applyPreset(preset)
+		}}, categorySearch})
+	}
+	return commands
+}
+
+// runKey runs the command bound to key, if any
+func (a *App) runKey(key string) tea.Cmd {
+	if cmd, ok := a.commandFor(key); ok {
+		return cmd.run()
+	}
+	return nil
+}
+
+// commandFor returns the command bound to key
+func (a *App) commandFor(key string) (command, bool) {
+	for _, cmd := range a.commands {
+		if slices.Contains(cmd.keys, key) {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// openPalette starts the command palette with an empty query
+func (a *App) openPalette() {
+	a.paletteMode = true
+	a.paletteQuery = ""
+	a.paletteCursor = 0
+}
+
+// paletteMatches returns the commands fuzzily matching the palette query,
+// best first, or every command in order for an empty query
+func (a *App) paletteMatches() []command {
+	if a.paletteQuery == "" {
+		return a.commands
+	}
+
+	names := make([]string, len(a.commands))
+	byName := make(map[string]command, len(a.commands))
+	for i, cmd := range a.commands {
+		names[i] = cmd.name
+		byName[cmd.name] = cmd
+	}
+
+	var matches []command
+	for _, name := range fuzzyRank(a.paletteQuery, names) {
+		matches = append(matches, byName[name])
+	}
+	return matches
+}
+
+// handlePaletteInput edits the palette query. Up and down move through the
+// matches, enter runs the selected command, esc closes the palette.
+func (a *App) handlePaletteInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	matches := a.paletteMatches()
+
+	switch msg.String() {
+	case "enter":
+		a.paletteMode = false
+		if a.paletteCursor < len(matches) {
+			return a, matches[a.paletteCursor].run()
+		}
+	case "esc", "ctrl+p":
+		a.paletteMode = false
+	case "up", "ctrl+k":
+		a.paletteCursor = max(0, a.paletteCursor-1)
+	case "down", "ctrl+j":
+		a.paletteCursor = min(max(0, len(matches)-1), a.paletteCursor+1)
+	case "backspace":
+		if len(a.paletteQuery) > 0 {
+			runes := []rune(a.paletteQuery)
+			a.paletteQuery = string(runes[:len(runes)-1])
+			a.paletteCursor = 0
+		}
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			a.paletteQuery += string(msg.Runes)
+			a.paletteCursor = 0
+		}
+	}
+	return a, nil
+}
+
+// formatKeys renders a command's keys for the palette
+func formatKeys(keys []string) string {
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		if key == " " {
+			key = "space"
+		}
+		names[i] = key
+	}
+	return strings.Join(names, " / ")
+}
+
+// renderPalette lists the commands matching the palette query with their
+// keys, keeping the selected one in view
+func (a *App) renderPalette() string {
+	height := a.contentHeight()
+	matches := a.paletteMatches()
+
+	nameWidth := 0
+	for _, cmd := range matches {
+		nameWidth = max(nameWidth, runewidth.StringWidth(cmd.name))
+	}
+
+	// Scroll so the cursor stays on screen
+	start := max(0, a.paletteCursor-height+1)
+	end := min(len(matches), start+height)
+
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	selected := lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Bold(true)
+
+	var lines []string
+	for i := start; i < end; i++ {
+		cmd := matches[i]
+		prefix := "  "
+		if i == a.paletteCursor {
+			prefix = "▸ "
+		}
+		name := truncate(prefix+runewidth.FillRight(cmd.name, nameWidth), a.width)
+		keys := formatKeys(cmd.keys)
+
+		line := name
+		if i == a.paletteCursor {
+			line = selected.Render(name)
+		}
+		if runewidth.StringWidth(name)+2+runewidth.StringWidth(keys) <= a.width {
+			line += "  " + keyStyle.Render(keys)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "  No matching command")
+	}
+
+	return lipgloss.NewStyle().Width(a.width).Height(height).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// DigestSort orders the rows of the digest view
+type DigestSort int
+
+const (
+	DigestByActivity DigestSort = iota // Most recently active source first
+	DigestBySource                     // Alphabetical by source name
+	DigestByLevel                      // Most severe latest entry first
+)
+
+// String names the sort order for the digest header
+func (d DigestSort) String() string {
+	switch d {
+	case DigestBySource:
+		return "source"
+	case DigestByLevel:
+		return "level"
+	default:
+		return "activity"
+	}
+}
+
+// toggleDigest switches between the digest and the pane view
+func (a *App) toggleDigest() {
+	if a.viewMode == ViewDigest {
+		a.viewMode = ViewMultiPane
+	} else {
+		a.viewMode = ViewDigest
+	}
+	a.updateLayout()
+}
+
+// cycleDigestSort moves to the next digest sort order
+func (a *App) cycleDigestSort() {
+	a.digestSort = (a.digestSort + 1) % 3
+}
+
+// digestRows returns the visible sources in digest order
+func (a *App) digestRows() []string {
+	rows := append([]string(nil), a.paneOrder...)
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		pi, pj := a.panes[rows[i]], a.panes[rows[j]]
+		switch a.digestSort {
+		case DigestBySource:
+			return rows[i] < rows[j]
+		case DigestByLevel:
+			li, _ := pi.Latest()
+			lj, _ := pj.Latest()
+			if li.Level != lj.Level {
+				return log.LevelRank(li.Level) > log.LevelRank(lj.Level)
+			}
+		}
+		return pi.lastEntryTime.After(pj.lastEntryTime)
+	})
+	return rows
+}
+
+// moveDigestCursor focuses the source delta rows away in digest order
+func (a *App) moveDigestCursor(delta int) {
+	if len(a.paneOrder) == 0 {
+		return
+	}
+
+	rows := a.digestRows()
+	current := 0
+	for i, name := range rows {
+		if name == a.paneOrder[a.focusedPane] {
+			current = i
+		}
+	}
+
+	next := max(0, min(len(rows)-1, current+delta))
+	for i, name := range a.paneOrder {
+		if name == rows[next] {
+			a.focusedPane = i
+		}
+	}
+}
+
+// renderDigestView renders one line per source with its latest entry,
+// like top for logs
+func (a *App) renderDigestView() string {
+	height := a.contentHeight()
+	rows := a.digestRows()
+
+	nameWidth := 0
+	for _, name := range rows {
+		nameWidth = max(nameWidth, runewidth.StringWidth(name))
+	}
+	nameWidth = min(nameWidth, maxSourceNameWidth)
+
+	focused := ""
+	if len(a.paneOrder) > 0 {
+		focused = a.paneOrder[a.focusedPane]
+	}
+
+	// Keep the focused row in view when there are more sources than rows
+	lines := []string{a.styles.PaneHeader.Render(fmt.Sprintf("Digest - %d sources, by %s", len(rows), a.digestSort))}
+	visible := max(1, height-1)
+	start := 0
+	for i, name := range rows {
+		if name == focused && i >= visible {
+			start = i - visible + 1
+		}
+	}
+
+	for _, name := range rows[start:min(len(rows), start+visible)] {
+		lines = append(lines, a.renderDigestRow(name, nameWidth, name == focused))
+	}
+
+	return lipgloss.NewStyle().Width(a.width).Height(height).Render(strings.Join(lines, "\n"))
+}
+
+// renderDigestRow renders a source's name, activity and latest entry
+func (a *App) renderDigestRow(name string, nameWidth int, focused bool) string {
+	pane := a.panes[name]
+
+	marker := "  "
+	if focused {
+		marker = "> "
+	}
+	status := "●"
+	if pane.IsIdle() {
+		status = "○"
+	}
+
+	label := runewidth.FillRight(truncate(name, nameWidth), nameWidth)
+	age := formatIdle(pane.IdleFor())
+	prefix := fmt.Sprintf("%s%s %s %4s ", marker, status, label, age)
+
+	entry, ok := pane.Latest()
+	if !ok {
+		return truncate(prefix, a.width)
+	}
+
+	line := prefix + formatLogEntry(entry, a.width-lipgloss.Width(prefix), a.viewOptions())
+	if focused {
+		return lipgloss.NewStyle().Bold(true).Render(line)
+	}
+	return line
+}
+
+// Latest returns the newest entry in the pane
+func (p *Pane) Latest() (log.LogEntry, bool) {
+	recent := p.buffer.GetRecent(1)
+	if len(recent) == 0 {
+		return log.LogEntry{}, false
+	}
+	return recent[0], true
+}
@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dumpManifestName is the archive member describing the dump
+const dumpManifestName = "manifest.json"
+
+// DumpDoneMsg reports the outcome of writing a dump archive
+type DumpDoneMsg struct {
+	Path string
+	Err  error
+}
+
+// dumpManifest describes the sources in a dump and the view they were
+// seen through
+type dumpManifest struct {
+	Created time.Time    `json:"created"`
+	Sources []dumpSource `json:"sources"`
+	View    dumpView     `json:"view"`
+}
+
+// dumpSource lists one source's file in the archive
+type dumpSource struct {
+	Name     string `json:"name"`
+	File     string `json:"file"`
+	Entries  int    `json:"entries"`
+	Capacity int    `json:"capacity"`
+	Visible  bool   `json:"visible"` // Shown under the active group tab
+
+	buffer *log.Buffer
+}
+
+// dumpView is the session's filter and display state
+type dumpView struct {
+	FilterLevel  log.LogLevel `json:"filter_level"`
+	ContextLines int          `json:"context_lines,omitempty"`
+	FuzzyQuery   string       `json:"fuzzy_query,omitempty"`
+	SearchQuery  string       `json:"search_query,omitempty"`
+	Group        string       `json:"group,omitempty"`
+	Focused      string       `json:"focused,omitempty"`
+	Paused       bool         `json:"paused"`
+	Follow       bool         `json:"follow"`
+}
+
+// defaultDumpPath names a dump archive after the current time
+func defaultDumpPath() string {
+	return fmt.Sprintf("logflow-dump-%s.zip", time.Now().Format("20060102-150405"))
+}
+
+// dumpAll snapshots the sources and view state and returns a command that
+// writes every pane's buffer to a zip archive at path, one JSON lines file
+// per source plus a manifest
+func (a *App) dumpAll(path string) tea.Cmd {
+	manifest := dumpManifest{
+		Created: time.Now(),
+		View: dumpView{
+			FilterLevel:  a.filterLevel,
+			ContextLines: a.contextLines,
+			FuzzyQuery:   a.fuzzyQuery,
+			SearchQuery:  a.searchQuery,
+			Paused:       a.paused,
+			Follow:       a.followMode,
+		},
+	}
+	if a.activeGroup > 0 {
+		manifest.View.Group = a.groups[a.activeGroup-1].Name
+	}
+	if len(a.paneOrder) > 0 {
+		manifest.View.Focused = a.paneOrder[a.focusedPane]
+	}
+
+	for i, name := range a.retainedPanes() {
+		buffer := a.panes[name].buffer
+		manifest.Sources = append(manifest.Sources, dumpSource{
+			Name:     name,
+			File:     fmt.Sprintf("sources/%02d-%s.jsonl", i+1, dumpFileName(name)),
+			Capacity: buffer.Capacity(),
+			Visible:  a.isShown(name) && !a.isHidden(name),
+			buffer:   buffer,
+		})
+	}
+
+	return func() tea.Msg {
+		return DumpDoneMsg{Path: path, Err: writeDump(path, manifest)}
+	}
+}
+
+// writeDump writes the archive, encoding entries straight into it so the
+// output is never held in memory. The manifest goes last, once the entry
+// counts are known.
+func writeDump(path string, manifest dumpManifest) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create dump: %w", err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	for i := range manifest.Sources {
+		source := &manifest.Sources[i]
+
+		w, err := createDumpFile(archive, source.File, manifest.Created)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to dump: %w", source.Name, err)
+		}
+
+		// Entries spilled to disk are older than those in memory
+		encoder := json.NewEncoder(w)
+		write := func(entry log.LogEntry) error {
+			if err := encoder.Encode(entry); err != nil {
+				return fmt.Errorf("failed to write %s to dump: %w", source.Name, err)
+			}
+			source.Entries++
+			return nil
+		}
+		if err := source.buffer.EachEntry(write); err != nil {
+			return err
+		}
+	}
+
+	w, err := createDumpFile(archive, dumpManifestName, manifest.Created)
+	if err != nil {
+		return fmt.Errorf("failed to add manifest to dump: %w", err)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish dump: %w", err)
+	}
+	return file.Close()
+}
+
+// createDumpFile adds a compressed file stamped with the dump time
+func createDumpFile(archive *zip.Writer, name string, modified time.Time) (io.Writer, error) {
+	return archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+}
+
+// dumpFileName makes a source name safe to use as an archive file name
+func dumpFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"sort"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+)
+
+// errorJumpLevel is the level at or above which an entry marks its pane for
+// the jump to error key: the --pause-on level if set, error otherwise
+func (a *App) errorJumpLevel() log.LogLevel {
+	if a.holdLevel != "" {
+		return a.holdLevel
+	}
+	return log.ResolveLevel(log.LogLevelError, 0)
+}
+
+// errorPanes returns the visible panes that have received an entry at the
+// jump level, newest such entry first
+func (a *App) errorPanes() []string {
+	var names []string
+	for _, name := range a.paneOrder {
+		if !a.panes[name].lastErrorTime.IsZero() {
+			names = append(names, name)
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return a.panes[names[i]].lastErrorTime.After(a.panes[names[j]].lastErrorTime)
+	})
+	return names
+}
+
+// jumpToError focuses the pane with the newest error. Pressed again while
+// still on the pane it jumped to, it moves on to the pane with the next
+// newest error, wrapping around.
+func (a *App) jumpToError() {
+	names := a.errorPanes()
+	if len(names) == 0 {
+		return
+	}
+
+	next := 0
+	if len(a.paneOrder) > 0 && a.paneOrder[a.focusedPane] == a.errorJumped {
+		for i, name := range names {
+			if name == a.errorJumped {
+				next = (i + 1) % len(names)
+				break
+			}
+		}
+	}
+
+	a.errorJumped = names[next]
+	a.focusPaneByName(a.errorJumped)
+}
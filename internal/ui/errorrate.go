@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rateCheckInterval is how often error rate alerts are re-evaluated, so an
+// alert clears once a source goes quiet
+const rateCheckInterval = time.Second
+
+// RateCheckMsg asks the dashboard to re-evaluate error rate alerts
+type RateCheckMsg struct{}
+
+// ErrorRate alerts when a source receives more than Count entries at error
+// level or above within Window
+type ErrorRate struct {
+	Source string // Source name or path.Match pattern, empty for every source
+	Count  int
+	Window time.Duration
+	Off    bool // Disables alerting for Source
+}
+
+// ParseErrorRate parses a "count/window" threshold such as "10/30s". A
+// "source=" prefix, where source may be a pattern like "api-*", makes it
+// apply to matching sources only; "source=off" disables alerts for them.
+func ParseErrorRate(spec string) (ErrorRate, error) {
+	var rate ErrorRate
+	threshold := spec
+	if source, rest, ok := strings.Cut(spec, "="); ok {
+		rate.Source = strings.TrimSpace(source)
+		if rate.Source == "" {
+			return ErrorRate{}, fmt.Errorf("invalid error rate %q, expected source=count/window", spec)
+		}
+		if _, err := path.Match(rate.Source, ""); err != nil {
+			return ErrorRate{}, fmt.Errorf("invalid pattern %q in error rate: %w", rate.Source, err)
+		}
+		threshold = rest
+	}
+
+	threshold = strings.TrimSpace(threshold)
+	if rate.Source != "" && strings.EqualFold(threshold, "off") {
+		rate.Off = true
+		return rate, nil
+	}
+
+	countText, windowText, ok := strings.Cut(threshold, "/")
+	if !ok {
+		return ErrorRate{}, fmt.Errorf("invalid error rate %q, expected count/window like 10/30s", spec)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(countText))
+	if err != nil || count < 0 {
+		return ErrorRate{}, fmt.Errorf("invalid error count %q in error rate %q", countText, spec)
+	}
+	window, err := time.ParseDuration(strings.TrimSpace(windowText))
+	if err != nil || window <= 0 {
+		return ErrorRate{}, fmt.Errorf("invalid window %q in error rate %q", windowText, spec)
+	}
+
+	rate.Count = count
+	rate.Window = window
+	return rate, nil
+}
+
+// String renders the threshold as count/window
+func (r ErrorRate) String() string {
+	return fmt.Sprintf("%d/%s", r.Count, formatWindow(r.Window))
+}
+
+// SetErrorRates configures the error rate thresholds. Thresholds for a
+// source override the default one, the first matching source winning.
+func (a *App) SetErrorRates(rates []ErrorRate) {
+	a.errorRates = rates
+}
+
+// errorRateFor returns the threshold applying to a source, false when
+// there is none or it is turned off
+func (a *App) errorRateFor(source string) (ErrorRate, bool) {
+	var fallback *ErrorRate
+	for i, rate := range a.errorRates {
+		if rate.Source == "" {
+			fallback = &a.errorRates[i]
+			continue
+		}
+		if matched, _ := path.Match(rate.Source, source); matched {
+			return rate, !rate.Off
+		}
+	}
+	if fallback == nil {
+		return ErrorRate{}, false
+	}
+	return *fallback, true
+}
+
+// recordError counts an entry toward its pane's error rate, keeping only
+// the newest times the threshold needs: it is exceeded exactly when the
+// oldest of count+1 errors is still inside the window
+func (a *App) recordError(pane *Pane, entry log.LogEntry) {
+	if len(a.errorRates) == 0 || !entry.Level.AtLeast(log.ResolveLevel(log.LogLevelError, 0)) {
+		return
+	}
+	rate, ok := a.errorRateFor(pane.name)
+	if !ok {
+		return
+	}
+
+	pane.errorTimes = append(pane.errorTimes, pane.lastEntryTime)
+	if extra := len(pane.errorTimes) - (rate.Count + 1); extra > 0 {
+		pane.errorTimes = pane.errorTimes[extra:]
+	}
+	a.checkErrorRate(pane, pane.lastEntryTime)
+}
+
+// checkErrorRate forgets a pane's errors older than its window and raises
+// or clears its alert, noting the change unless the pane is muted
+func (a *App) checkErrorRate(pane *Pane, now time.Time) {
+	rate, ok := a.errorRateFor(pane.name)
+	if !ok {
+		pane.errorTimes = nil
+		pane.rateAlert = false
+		return
+	}
+
+	cutoff := now.Add(-rate.Window)
+	expired := 0
+	for expired < len(pane.errorTimes) && !pane.errorTimes[expired].After(cutoff) {
+		expired++
+	}
+	pane.errorTimes = pane.errorTimes[expired:]
+
+	exceeded := len(pane.errorTimes) > rate.Count
+	if exceeded == pane.rateAlert {
+		return
+	}
+	pane.rateAlert = exceeded
+	pane.errorRate = rate
+	if pane.muted {
+		return
+	}
+	if exceeded {
+		a.notice = fmt.Sprintf("%s: more than %d errors in %s", pane.name, rate.Count, formatWindow(rate.Window))
+	} else {
+		a.notice = fmt.Sprintf("%s: error rate back under %s", pane.name, rate)
+	}
+}
+
+// scheduleRateCheck returns a command that sends the next error rate
+// check, or nil when no threshold is set
+func (a *App) scheduleRateCheck() tea.Cmd {
+	if len(a.errorRates) == 0 {
+		return nil
+	}
+	return tea.Tick(rateCheckInterval, func(time.Time) tea.Msg {
+		return RateCheckMsg{}
+	})
+}
+
+// checkErrorRates re-evaluates every pane's alert, clearing those whose
+// errors have aged out of their window
+func (a *App) checkErrorRates() tea.Cmd {
+	now := time.Now()
+	for _, pane := range a.panes {
+		a.checkErrorRate(pane, now)
+	}
+	return a.scheduleRateCheck()
+}
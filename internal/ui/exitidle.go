@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// idleCheckInterval is how often the dashboard checks whether it has gone
+// idle long enough to exit
+const idleCheckInterval = time.Second
+
+// IdleCheckMsg asks the dashboard to check whether it should exit on idle
+type IdleCheckMsg struct{}
+
+// SetExitOnIdle makes the dashboard quit once every source has
+// disconnected and nothing has arrived for idle; zero disables it
+func (a *App) SetExitOnIdle(idle time.Duration) {
+	a.exitOnIdle = idle
+}
+
+// scheduleIdleCheck returns a command that sends the next idle check, or
+// nil when exiting on idle is disabled
+func (a *App) scheduleIdleCheck() tea.Cmd {
+	if a.exitOnIdle <= 0 {
+		return nil
+	}
+	return tea.Tick(min(a.exitOnIdle, idleCheckInterval), func(time.Time) tea.Msg {
+		return IdleCheckMsg{}
+	})
+}
+
+// checkIdle quits once sources have come and all gone, and neither an
+// entry nor a disconnect has happened for the idle period
+func (a *App) checkIdle() tea.Cmd {
+	now := time.Now()
+	if !a.anySourceArrived() || a.server.Stats().Clients > 0 {
+		a.sourcesGoneAt = time.Time{}
+		return a.scheduleIdleCheck()
+	}
+	if a.sourcesGoneAt.IsZero() {
+		a.sourcesGoneAt = now
+	}
+
+	last := a.sourcesGoneAt
+	if a.lastActivity.After(last) {
+		last = a.lastActivity
+	}
+	if now.Sub(last) >= a.exitOnIdle {
+		return tea.Quit
+	}
+	return a.scheduleIdleCheck()
+}
+
+// anySourceArrived reports whether an entry has arrived from any source.
+// Panes set up by --watch wait for their first one, so they do not count.
+func (a *App) anySourceArrived() bool {
+	for _, pane := range a.panes {
+		if !pane.waiting {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Group is a named set of sources shown together as a tab
+type Group struct {
+	Name    string
+	Sources []string // Source names or path.Match patterns such as "api-*"
+}
+
+// ParseGroup parses a "name=source1,source2" group definition
+func ParseGroup(spec string) (Group, error) {
+	name, list, ok := strings.Cut(spec, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return Group{}, fmt.Errorf("invalid group %q, expected name=source1,source2", spec)
+	}
+
+	group := Group{Name: name}
+	for _, source := range strings.Split(list, ",") {
+		source = strings.TrimSpace(source)
+		if source == "" {
+			continue
+		}
+		if _, err := path.Match(source, ""); err != nil {
+			return Group{}, fmt.Errorf("invalid pattern %q in group %s: %w", source, name, err)
+		}
+		group.Sources = append(group.Sources, source)
+	}
+
+	if len(group.Sources) == 0 {
+		return Group{}, fmt.Errorf("group %s has no sources", name)
+	}
+	return group, nil
+}
+
+// Contains reports whether the source belongs to the group
+func (g Group) Contains(source string) bool {
+	for _, pattern := range g.Sources {
+		if matched, _ := path.Match(pattern, source); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// SetGroups configures the source groups offered as tabs
+func (a *App) SetGroups(groups []Group) {
+	a.groups = groups
+	a.activeGroup = 0
+	a.refreshVisiblePanes()
+}
+
+// inActiveGroup reports whether a source is shown under the current tab
+func (a *App) inActiveGroup(source string) bool {
+	if a.activeGroup == 0 {
+		return true
+	}
+	return a.groups[a.activeGroup-1].Contains(source)
+}
+
+// switchGroup moves delta tabs along, wrapping around; tab 0 shows all
+func (a *App) switchGroup(delta int) {
+	if len(a.groups) == 0 {
+		return
+	}
+
+	tabs := len(a.groups) + 1
+	a.activeGroup = (a.activeGroup + delta + tabs) % tabs
+	a.refreshVisiblePanes()
+}
+
+// refreshVisiblePanes rebuilds paneOrder from every known pane, keeping
+// only unmuted ones in the active group. Hidden panes keep buffering. A
+// zoomed pane still shown stays zoomed and focused.
+func (a *App) refreshVisiblePanes() {
+	a.paneOrder = a.paneOrder[:0]
+	for _, name := range a.allPanes {
+		if a.isShown(name) {
+			a.paneOrder = append(a.paneOrder, name)
+		}
+	}
+
+	a.focusedPane = 0
+	a.dropStaleZoom()
+	if i, ok := a.zoomedIndex(); ok && a.viewMode == ViewZoomed {
+		a.focusedPane = i
+	}
+	a.refreshTrace()
+	a.updateLayout()
+}
+
+// renderTabs renders the group tab bar, marking the active tab
+func (a *App) renderTabs() string {
+	names := []string{"All"}
+	for _, group := range a.groups {
+		names = append(names, group.Name)
+	}
+
+	for i, name := range names {
+		if i == a.activeGroup {
+			names[i] = "[" + name + "]"
+		}
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// openHelp shows the key binding help from the top, unfiltered
+func (a *App) openHelp() {
+	a.helpMode = true
+	a.helpQuery = ""
+	a.helpScroll = 0
+}
+
+// helpLines lists every command bound to a key with its keys, by
+// category. The bindings come from the same commands key presses run, so
+// the help cannot drift from them. A query keeps only commands whose name
+// or keys contain it.
+func (a *App) helpLines() []string {
+	query := strings.ToLower(a.helpQuery)
+
+	keyWidth := 0
+	for _, cmd := range a.commands {
+		keyWidth = max(keyWidth, runewidth.StringWidth(formatKeys(cmd.keys)))
+	}
+
+	heading := lipgloss.NewStyle().Bold(true)
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+
+	var lines []string
+	for _, category := range helpCategories {
+		var rows []string
+		for _, cmd := range a.commands {
+			if cmd.category != category || len(cmd.keys) == 0 {
+				continue
+			}
+			keys := formatKeys(cmd.keys)
+			if query != "" && !strings.Contains(strings.ToLower(cmd.name+" "+keys), query) {
+				continue
+			}
+			// Cut before styling, truncate counts escape codes as text
+			name := truncate(cmd.name, a.width-keyWidth-4)
+			rows = append(rows, "  "+keyStyle.Render(runewidth.FillRight(keys, keyWidth))+"  "+name)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, heading.Render(truncate(category+":", a.width)))
+		lines = append(lines, rows...)
+	}
+	return lines
+}
+
+// handleHelpInput edits the help search and scrolls the help. Esc closes
+// it.
+func (a *App) handleHelpInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	page := max(1, a.contentHeight()-1)
+
+	switch msg.String() {
+	case "esc", "f1":
+		a.helpMode = false
+	case "up", "ctrl+k":
+		a.helpScroll--
+	case "down", "ctrl+j":
+		a.helpScroll++
+	case "pgup":
+		a.helpScroll -= page
+	case "pgdown":
+		a.helpScroll += page
+	case "home":
+		a.helpScroll = 0
+	case "backspace":
+		if len(a.helpQuery) > 0 {
+			runes := []rune(a.helpQuery)
+			a.helpQuery = string(runes[:len(runes)-1])
+			a.helpScroll = 0
+		}
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			a.helpQuery += string(msg.Runes)
+			a.helpScroll = 0
+		}
+	}
+	return a, nil
+}
+
+// renderHelp renders the help lines that fit, from the scroll position
+func (a *App) renderHelp() string {
+	height := a.contentHeight()
+	lines := a.helpLines()
+
+	// Stop scrolling once the last line is on screen
+	a.helpScroll = max(0, min(a.helpScroll, len(lines)-height))
+	end := min(len(lines), a.helpScroll+height)
+
+	visible := lines[a.helpScroll:end]
+	if len(lines) == 0 {
+		visible = append(visible, "  No matching key binding")
+	}
+
+	return lipgloss.NewStyle().Width(a.width).Height(height).Render(strings.Join(visible, "\n"))
+}
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// HighlightRule styles every match of a pattern in the lines shown
+type HighlightRule struct {
+	Pattern *regexp.Regexp
+	Style   lipgloss.Style
+}
+
+// highlightColors names the basic ANSI colors usable in highlight styles
+var highlightColors = map[string]string{
+	"black":   "0",
+	"red":     "1",
+	"green":   "2",
+	"yellow":  "3",
+	"blue":    "4",
+	"magenta": "5",
+	"cyan":    "6",
+	"white":   "7",
+	"gray":    "8",
+}
+
+// colorCode matches ANSI color numbers and #rrggbb hex colors
+var colorCode = regexp.MustCompile(`^([0-9]{1,3}|#[0-9a-f]{6})$`)
+
+// ParseHighlight parses a "pattern:style" rule. The pattern ends at the
+// last colon, the style is a comma-separated list of bold, italic,
+// underline, faint, reverse or strike and a color: a name like red, an
+// ANSI number or a #rrggbb hex value, with bg= in front for the
+// background.
+func ParseHighlight(spec string) (HighlightRule, error) {
+	i := strings.LastIndex(spec, ":")
+	if i <= 0 {
+		return HighlightRule{}, fmt.Errorf("invalid highlight %q, expected pattern:style", spec)
+	}
+
+	pattern, err := regexp.Compile(spec[:i])
+	if err != nil {
+		return HighlightRule{}, fmt.Errorf("invalid highlight pattern: %w", err)
+	}
+	style, err := parseHighlightStyle(spec[i+1:])
+	if err != nil {
+		return HighlightRule{}, err
+	}
+	return HighlightRule{Pattern: pattern, Style: style}, nil
+}
+
+// parseHighlightStyle builds a style from a comma-separated attribute list
+func parseHighlightStyle(spec string) (lipgloss.Style, error) {
+	style := lipgloss.NewStyle()
+	empty := true
+	for _, attr := range strings.Split(spec, ",") {
+		attr = strings.ToLower(strings.TrimSpace(attr))
+		if attr == "" {
+			continue
+		}
+		empty = false
+
+		switch attr {
+		case "bold":
+			style = style.Bold(true)
+		case "italic":
+			style = style.Italic(true)
+		case "underline":
+			style = style.Underline(true)
+		case "faint":
+			style = style.Faint(true)
+		case "reverse":
+			style = style.Reverse(true)
+		case "strike":
+			style = style.Strikethrough(true)
+		default:
+			name, background := strings.CutPrefix(attr, "bg=")
+			color, ok := highlightColor(name)
+			if !ok {
+				return style, fmt.Errorf("unknown highlight style %q", attr)
+			}
+			if background {
+				style = style.Background(color)
+			} else {
+				style = style.Foreground(color)
+			}
+		}
+	}
+
+	if empty {
+		return style, fmt.Errorf("highlight has no style")
+	}
+	return style, nil
+}
+
+// highlightColor resolves a color name, ANSI number or #rrggbb value
+func highlightColor(name string) (lipgloss.Color, bool) {
+	if code, ok := highlightColors[name]; ok {
+		return lipgloss.Color(code), true
+	}
+	if colorCode.MatchString(name) {
+		return lipgloss.Color(name), true
+	}
+	return "", false
+}
+
+// applyHighlights styles the matches of rules in text. Where matches of
+// several rules overlap, the rule given first wins.
+func applyHighlights(text string, rules []HighlightRule) string {
+	if len(rules) == 0 || text == "" {
+		return text
+	}
+
+	// owner[i] is the rule styling byte i, -1 for none
+	owner := make([]int, len(text))
+	for i := range owner {
+		owner[i] = -1
+	}
+	matched := false
+	for r := len(rules) - 1; r >= 0; r-- {
+		for _, loc := range rules[r].Pattern.FindAllStringIndex(text, -1) {
+			for i := loc[0]; i < loc[1]; i++ {
+				owner[i] = r
+			}
+			matched = matched || loc[1] > loc[0]
+		}
+	}
+	if !matched {
+		return text
+	}
+
+	var b strings.Builder
+	start := 0
+	for i := 1; i <= len(text); i++ {
+		if i < len(text) && owner[i] == owner[start] {
+			continue
+		}
+		if owner[start] < 0 {
+			b.WriteString(text[start:i])
+		} else {
+			b.WriteString(rules[owner[start]].Style.Render(text[start:i]))
+		}
+		start = i
+	}
+	return b.String()
+}
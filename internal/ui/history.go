@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxSearchHistory caps how many past queries are remembered
+const maxSearchHistory = 100
+
+// SearchHistory remembers past search queries and lets them be recalled
+// shell-style, newest first
+type SearchHistory struct {
+	entries []string
+	pos     int    // Index into entries while browsing, len(entries) when not
+	draft   string // The query being typed before browsing started
+	path    string // Where history persists, empty to keep it in memory
+}
+
+// NewSearchHistory creates a history, loading earlier queries from path
+// when it is not empty
+func NewSearchHistory(path string) *SearchHistory {
+	h := &SearchHistory{path: path}
+
+	if path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if line != "" {
+					h.entries = append(h.entries, line)
+				}
+			}
+		}
+	}
+
+	if len(h.entries) > maxSearchHistory {
+		h.entries = h.entries[len(h.entries)-maxSearchHistory:]
+	}
+	h.pos = len(h.entries)
+	return h
+}
+
+// Add records a submitted query and persists the history
+func (h *SearchHistory) Add(query string) {
+	defer h.Reset()
+
+	if query == "" {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == query {
+		return
+	}
+
+	h.entries = append(h.entries, query)
+	if len(h.entries) > maxSearchHistory {
+		h.entries = h.entries[len(h.entries)-maxSearchHistory:]
+	}
+	h.save()
+}
+
+// Prev returns the query before the one shown, remembering current as the
+// draft when browsing starts
+func (h *SearchHistory) Prev(current string) string {
+	if h.pos == len(h.entries) {
+		h.draft = current
+	}
+	if h.pos > 0 {
+		h.pos--
+	}
+	if h.pos == len(h.entries) {
+		return current
+	}
+	return h.entries[h.pos]
+}
+
+// Next returns the query after the one shown, ending at the draft
+func (h *SearchHistory) Next() string {
+	if h.pos < len(h.entries) {
+		h.pos++
+	}
+	if h.pos == len(h.entries) {
+		return h.draft
+	}
+	return h.entries[h.pos]
+}
+
+// Reset stops browsing so the next Prev starts from the newest query
+func (h *SearchHistory) Reset() {
+	h.pos = len(h.entries)
+	h.draft = ""
+}
+
+// save writes the history to its file, if persistence is enabled. Failing
+// to persist is not worth interrupting a search over.
+func (h *SearchHistory) save() {
+	if h.path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return
+	}
+	os.WriteFile(h.path, []byte(strings.Join(h.entries, "\n")+"\n"), 0600)
+}
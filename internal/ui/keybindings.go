@@ -1,96 +1,132 @@
 package ui
 
-import (
-	"strings"
-)
-
 // KeyMap defines the key bindings for the application
 type KeyMap struct {
 	Quit    []string
-	HelpKey []string // Renamed from Help to avoid conflict
+	Palette []string
 
 	// Navigation
 	NextPane     []string
 	PrevPane     []string
-	VimNav       []string
+	NavLeft      []string
+	NavDown      []string
+	NavUp        []string
+	NavRight     []string
 	DirectAccess []string
+	NextGroup    []string
+	PrevGroup    []string
+	GotoSource   []string
+	JumpToError  []string
 
 	// Layout
 	CycleLayout []string
 	Zoom        []string
 	ZoomOut     []string
+	Open        []string
+	Collapse    []string
+	MoveLeft    []string
+	MoveRight   []string
+	Boost       []string
+	ResetBoost  []string
+	Mute        []string
+	UnmuteAll   []string
+	SortIdle    []string
+	Truncation  []string
+	Metrics     []string
+	Throughput  []string
+	TimeColumn  []string
+	Metadata    []string
+	Fields      []string
+	Sparkline   []string
+	LevelCounts []string
+	Digest      []string
+	DigestSort  []string
+	Trace       []string
 
 	// Search
 	SearchLocal  []string
 	SearchGlobal []string
+	FuzzyFilter  []string
 
 	// Filter
 	FilterError []string
 	FilterWarn  []string
 	FilterInfo  []string
 	FilterAll   []string
+	Context     []string
+	NextPreset  []string
+	SavePreset  []string
 
 	// Control
-	Pause  []string
-	Follow []string
-	Clear  []string
-	Export []string
+	Pause    []string
+	Follow   []string
+	Clear    []string
+	ClearAll []string
+	Export   []string
+	Pipe     []string
+	Help     []string
 }
 
 // DefaultKeyMap returns the default key bindings
 func DefaultKeyMap() KeyMap {
 	return KeyMap{
 		Quit:    []string{"q", "ctrl+c"},
-		HelpKey: []string{"?"},
+		Palette: []string{"ctrl+p"},
 
 		NextPane:     []string{"tab"},
 		PrevPane:     []string{"shift+tab"},
-		VimNav:       []string{"h", "j", "k", "l"},
+		NavLeft:      []string{"h"},
+		NavDown:      []string{"j"},
+		NavUp:        []string{"k"},
+		NavRight:     []string{"l"},
 		DirectAccess: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9"},
+		NextGroup:    []string{"]"},
+		PrevGroup:    []string{"["},
+		GotoSource:   []string{":"},
+		JumpToError:  []string{"E"},
 
 		CycleLayout: []string{"L"}, // Capital L to avoid conflict with vim nav
 		Zoom:        []string{"z"},
 		ZoomOut:     []string{"Z", "esc"},
+		Open:        []string{"enter"},
+		Collapse:    []string{"m"},
+		MoveLeft:    []string{"<"},
+		MoveRight:   []string{">"},
+		Boost:       []string{"+"},
+		ResetBoost:  []string{"-"},
+		Mute:        []string{"u"},
+		UnmuteAll:   []string{"U"},
+		SortIdle:    []string{"s"},
+		Truncation:  []string{"t"},
+		Metrics:     []string{"D"},
+		Throughput:  []string{"b"},
+		TimeColumn:  []string{"T"},
+		Metadata:    []string{"M"},
+		Fields:      []string{"I"},
+		Sparkline:   []string{"S"},
+		LevelCounts: []string{"#"},
+		Digest:      []string{"d"},
+		DigestSort:  []string{"o"},
+		Trace:       []string{"R"},
 
 		SearchLocal:  []string{"/"},
 		SearchGlobal: []string{"ctrl+/", "?"},
+		FuzzyFilter:  []string{"F"},
 
 		FilterError: []string{"e"},
 		FilterWarn:  []string{"w"},
 		FilterInfo:  []string{"i"},
 		FilterAll:   []string{"a"},
+		Context:     []string{"C"},
+		NextPreset:  []string{"p"},
+		SavePreset:  []string{"P"},
 
-		Pause:  []string{" "},
-		Follow: []string{"f"},
-		Clear:  []string{"c"},
-		Export: []string{"x"},
+		Pause:    []string{" "},
+		Follow:   []string{"f"},
+		Clear:    []string{"c"},
+		ClearAll: []string{"X"},
+		Export:   []string{"x"},
+		Pipe:     []string{"|"},
+		Help:     []string{"H", "f1"},
 	}
 }
-
-// HelpText returns a help text for the key bindings
-func (k KeyMap) HelpText() string {
-	help := []string{
-		"Navigation:",
-		"  1-9: Jump to pane",
-		"  Tab/Shift+Tab: Cycle panes",
-		"  h/j/k/l: Vim navigation",
-		"",
-		"Layout & View:",
-		"  L: Cycle layouts",
-		"  z: Zoom into pane",
-		"  Z/Esc: Zoom out",
-		"",
-		"Search & Filter:",
-		"  /: Search current pane",
-		"  Ctrl+/: Global search",
-		"  e/w/i/a: Filter by level",
-		"",
-		"Control:",
-		"  Space: Pause/resume",
-		"  f: Toggle follow mode",
-		"  c: Clear current pane",
-		"  q: Quit",
-	}
-
-	return strings.Join(help, "\n")
-}
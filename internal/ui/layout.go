@@ -3,32 +3,76 @@ package ui
 
 import (
 	"math"
+	"sort"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
+// minProportionalHeight is the fewest lines a pane gets in the
+// proportional layout, enough for its summary line and latest entry
+const minProportionalHeight = 2
+
+// splitCollapsed partitions pane indices into expanded and collapsed panes,
+// preserving display order
+func (a *App) splitCollapsed() (expanded, collapsed []int) {
+	for i, paneName := range a.paneOrder {
+		if a.panes[paneName].IsCollapsed() {
+			collapsed = append(collapsed, i)
+		} else {
+			expanded = append(expanded, i)
+		}
+	}
+	return expanded, collapsed
+}
+
+// renderSummaries renders collapsed panes as one line each
+func (a *App) renderSummaries(indices []int) []string {
+	var lines []string
+	for _, i := range indices {
+		pane := a.panes[a.paneOrder[i]]
+		lines = append(lines, pane.RenderSummary(a.width, i == a.focusedPane))
+	}
+	return lines
+}
+
 // renderHorizontalLayout renders panes stacked horizontally
 func (a *App) renderHorizontalLayout(height int) string {
 	if len(a.paneOrder) == 0 {
 		return ""
 	}
 
+	expanded, collapsed := a.splitCollapsed()
+
+	// Collapsed panes take a single line each
+	height -= len(collapsed)
+
 	var paneViews []string
-	paneHeight := height / len(a.paneOrder)
+	paneHeight, remainder := 0, 0
+	if len(expanded) > 0 {
+		paneHeight = height / len(expanded)
 
-	// Distribute remaining height to first few panes
-	remainder := height % len(a.paneOrder)
+		// Distribute remaining height to first few panes
+		remainder = height % len(expanded)
+	}
 
+	expandedIndex := 0
 	for i, paneName := range a.paneOrder {
 		pane := a.panes[paneName]
 		focused := (i == a.focusedPane)
 
+		if pane.IsCollapsed() {
+			paneViews = append(paneViews, pane.RenderSummary(a.width, focused))
+			continue
+		}
+
 		currentHeight := paneHeight
-		if i < remainder {
+		if expandedIndex < remainder {
 			currentHeight++
 		}
+		expandedIndex++
 
-		paneView := pane.Render(a.width, currentHeight, focused, a.filterLevel, a.followMode)
+		paneView := pane.Render(a.width, currentHeight, focused, a.viewOptions())
 		paneViews = append(paneViews, paneView)
 	}
 
@@ -41,26 +85,36 @@ func (a *App) renderVerticalLayout(height int) string {
 		return ""
 	}
 
-	var paneViews []string
-	paneWidth := a.width / len(a.paneOrder)
+	expanded, collapsed := a.splitCollapsed()
+
+	// Collapsed panes are listed as summary lines above the others
+	paneViews := a.renderSummaries(collapsed)
+	height -= len(collapsed)
+	if len(expanded) == 0 {
+		return lipgloss.JoinVertical(lipgloss.Left, paneViews...)
+	}
+
+	var columns []string
+	paneWidth := a.width / len(expanded)
 
 	// Distribute remaining width to first few panes
-	remainder := a.width % len(a.paneOrder)
+	remainder := a.width % len(expanded)
 
-	for i, paneName := range a.paneOrder {
-		pane := a.panes[paneName]
+	for n, i := range expanded {
+		pane := a.panes[a.paneOrder[i]]
 		focused := (i == a.focusedPane)
 
 		currentWidth := paneWidth
-		if i < remainder {
+		if n < remainder {
 			currentWidth++
 		}
 
-		paneView := pane.Render(currentWidth, height, focused, a.filterLevel, a.followMode)
-		paneViews = append(paneViews, paneView)
+		paneView := pane.Render(currentWidth, height, focused, a.viewOptions())
+		columns = append(columns, paneView)
 	}
 
-	return lipgloss.JoinHorizontal(lipgloss.Top, paneViews...)
+	paneViews = append(paneViews, lipgloss.JoinHorizontal(lipgloss.Top, columns...))
+	return lipgloss.JoinVertical(lipgloss.Left, paneViews...)
 }
 
 // renderGridLayout renders panes in a grid pattern
@@ -69,22 +123,29 @@ func (a *App) renderGridLayout(height int) string {
 		return ""
 	}
 
+	expanded, collapsed := a.splitCollapsed()
+
+	// Collapsed panes are listed as summary lines above the grid
+	gridRows := a.renderSummaries(collapsed)
+	height -= len(collapsed)
+	if len(expanded) == 0 {
+		return lipgloss.JoinVertical(lipgloss.Left, gridRows...)
+	}
+
 	// Calculate grid dimensions
-	paneCount := len(a.paneOrder)
+	paneCount := len(expanded)
 	cols := int(math.Ceil(math.Sqrt(float64(paneCount))))
 	rows := int(math.Ceil(float64(paneCount) / float64(cols)))
 
 	paneWidth := a.width / cols
 	paneHeight := height / rows
 
-	var gridRows []string
-
 	for row := 0; row < rows; row++ {
 		var rowPanes []string
 
 		for col := 0; col < cols; col++ {
-			paneIndex := row*cols + col
-			if paneIndex >= len(a.paneOrder) {
+			cell := row*cols + col
+			if cell >= len(expanded) {
 				// Fill empty space
 				emptyPane := lipgloss.NewStyle().
 					Width(paneWidth).
@@ -94,11 +155,12 @@ func (a *App) renderGridLayout(height int) string {
 				continue
 			}
 
+			paneIndex := expanded[cell]
 			paneName := a.paneOrder[paneIndex]
 			pane := a.panes[paneName]
 			focused := (paneIndex == a.focusedPane)
 
-			paneView := pane.Render(paneWidth, paneHeight, focused, a.filterLevel, a.followMode)
+			paneView := pane.Render(paneWidth, paneHeight, focused, a.viewOptions())
 			rowPanes = append(rowPanes, paneView)
 		}
 
@@ -108,3 +170,102 @@ func (a *App) renderGridLayout(height int) string {
 
 	return lipgloss.JoinVertical(lipgloss.Left, gridRows...)
 }
+
+// proportionalWeights weighs the panes at indices by the entries they
+// received in the last minute, or by the lines they hold when none of them
+// received any
+func (a *App) proportionalWeights(indices []int) []int {
+	now := time.Now()
+	weights := make([]int, len(indices))
+	recent := 0
+	for n, i := range indices {
+		weights[n] = a.panes[a.paneOrder[i]].activity.total(now)
+		recent += weights[n]
+	}
+	if recent > 0 {
+		return weights
+	}
+
+	for n, i := range indices {
+		weights[n] = a.panes[a.paneOrder[i]].GetEntryCount()
+	}
+	return weights
+}
+
+// proportionalShares splits total into one share per weight, each at least
+// minimum and the rest in proportion to the weights. Rounding leftovers go
+// to the largest remainders; without any weight the split is even.
+func proportionalShares(weights []int, total, minimum int) []int {
+	shares := make([]int, len(weights))
+	if len(weights) == 0 {
+		return shares
+	}
+
+	sum := 0
+	for _, w := range weights {
+		sum += w
+	}
+	spare := total - minimum*len(weights)
+	if sum == 0 || spare <= 0 {
+		for n := range shares {
+			shares[n] = total / len(weights)
+			if n < total%len(weights) {
+				shares[n]++
+			}
+		}
+		return shares
+	}
+
+	remainders := make([]int, len(weights))
+	given := 0
+	for n, w := range weights {
+		extra := spare * w / sum
+		shares[n] = minimum + extra
+		remainders[n] = spare * w % sum
+		given += extra
+	}
+
+	order := make([]int, len(weights))
+	for n := range order {
+		order[n] = n
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+	for _, n := range order[:spare-given] {
+		shares[n]++
+	}
+	return shares
+}
+
+// renderProportionalLayout stacks panes like the horizontal layout, sizing
+// each by its activity so busy sources get more room
+func (a *App) renderProportionalLayout(height int) string {
+	if len(a.paneOrder) == 0 {
+		return ""
+	}
+
+	expanded, collapsed := a.splitCollapsed()
+
+	// Collapsed panes take a single line each
+	height -= len(collapsed)
+	heights := proportionalShares(a.proportionalWeights(expanded), height, minProportionalHeight)
+
+	var paneViews []string
+	expandedIndex := 0
+	for i, paneName := range a.paneOrder {
+		pane := a.panes[paneName]
+		focused := (i == a.focusedPane)
+
+		if pane.IsCollapsed() {
+			paneViews = append(paneViews, pane.RenderSummary(a.width, focused))
+			continue
+		}
+
+		paneView := pane.Render(a.width, heights[expandedIndex], focused, a.viewOptions())
+		paneViews = append(paneViews, paneView)
+		expandedIndex++
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, paneViews...)
+}
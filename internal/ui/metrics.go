@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/ipc"
+)
+
+// metrics tracks the dashboard's own performance for the debug overlay
+type metrics struct {
+	renderTime time.Duration // How long the last View call took
+	rate       float64       // Entries received per second
+	sampledAt  time.Time
+	sampled    uint64 // Entries received as of sampledAt
+}
+
+// sampleRate recomputes the ingest rate once at least a second has passed
+// since the previous sample
+func (m *metrics) sampleRate(received uint64, now time.Time) {
+	if m.sampledAt.IsZero() {
+		m.sampledAt = now
+		m.sampled = received
+		return
+	}
+
+	elapsed := now.Sub(m.sampledAt)
+	if elapsed < time.Second {
+		return
+	}
+
+	m.rate = float64(received-m.sampled) / elapsed.Seconds()
+	m.sampledAt = now
+	m.sampled = received
+}
+
+// throughput tracks the aggregate ingest rate shown in the status bar,
+// sampled on every tick
+type throughput struct {
+	entries   float64 // Entries received per second
+	bytes     float64 // Bytes of log lines received per second
+	sampledAt time.Time
+	sampled   ipc.Stats // Counters as of sampledAt
+}
+
+// sample recomputes the rates from the counters since the previous sample
+func (t *throughput) sample(stats ipc.Stats, now time.Time) {
+	if !t.sampledAt.IsZero() {
+		if elapsed := now.Sub(t.sampledAt).Seconds(); elapsed > 0 {
+			t.entries = float64(stats.Received-t.sampled.Received) / elapsed
+			t.bytes = float64(stats.Bytes-t.sampled.Bytes) / elapsed
+		}
+	}
+	t.sampledAt = now
+	t.sampled = stats
+}
+
+// renderThroughput renders the entries held across every pane and the
+// rolling ingest rate for the status bar
+func (a *App) renderThroughput() string {
+	held := 0
+	for _, pane := range a.panes {
+		held += pane.buffer.Count()
+	}
+	return fmt.Sprintf("%s entries, %.0f/s %s/s", formatCount(held), a.throughput.entries, formatBytes(a.throughput.bytes))
+}
+
+// formatBytes renders a byte count in B, KB or MB
+func formatBytes(n float64) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", n/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", n/1024)
+	default:
+		return fmt.Sprintf("%.0f B", n)
+	}
+}
+
+// renderMetrics renders the debug overlay line with live ingest and render
+// counters
+func (a *App) renderMetrics() string {
+	stats := a.server.Stats()
+	a.metrics.sampleRate(stats.Received, time.Now())
+
+	fields := []string{
+		fmt.Sprintf("%.0f entries/s", a.metrics.rate),
+		fmt.Sprintf("%d received", stats.Received),
+		fmt.Sprintf("%d dropped", stats.Dropped),
+		fmt.Sprintf("render %s", a.metrics.renderTime.Round(10*time.Microsecond)),
+		fmt.Sprintf("%d goroutines", runtime.NumGoroutine()),
+		fmt.Sprintf("%d clients", stats.Clients),
+		fmt.Sprintf("%d rejected", stats.Rejected),
+		fmt.Sprintf("%d malformed", stats.Malformed),
+	}
+
+	// Drop the least urgent counters first when narrow
+	line := fitFields(fields, []int{7, 6, 5, 4, 1}, a.width-a.styles.Debug.GetHorizontalFrameSize())
+	return a.styles.Debug.Width(a.width).Render(line)
+}
@@ -0,0 +1,52 @@
+package ui
+
+// isShown reports whether a source's pane belongs in the layout: it is in
+// the active group and not muted
+func (a *App) isShown(name string) bool {
+	pane, ok := a.panes[name]
+	return a.inActiveGroup(name) && !(ok && pane.muted)
+}
+
+// muteFocused takes the focused pane out of the layout and stops it
+// pausing on errors or being jumped to, while its buffer keeps filling
+func (a *App) muteFocused() {
+	if len(a.paneOrder) == 0 {
+		return
+	}
+
+	name := a.paneOrder[a.focusedPane]
+	a.panes[name].muted = true
+	a.removeFromOrder(name)
+	a.updateLayout()
+}
+
+// unmuteAll brings every muted pane back into the layout, keeping focus on
+// the pane it was on
+func (a *App) unmuteAll() {
+	if a.mutedCount() == 0 {
+		return
+	}
+
+	focused := ""
+	if len(a.paneOrder) > 0 {
+		focused = a.paneOrder[a.focusedPane]
+	}
+	for _, pane := range a.panes {
+		pane.muted = false
+	}
+	a.refreshVisiblePanes()
+	if focused != "" {
+		a.focusPaneByName(focused)
+	}
+}
+
+// mutedCount returns how many sources with a pane are muted
+func (a *App) mutedCount() int {
+	count := 0
+	for _, name := range a.allPanes {
+		if a.panes[name].muted {
+			count++
+		}
+	}
+	return count
+}
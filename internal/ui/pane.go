@@ -3,44 +3,381 @@ package ui
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
-	"github.com/charmbracelet/lipgloss"
 	"github.com/Yriskit-ai/logflow/internal/log"
+	"github.com/Yriskit-ai/logflow/internal/sinks"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// previewHeight is the height below which a bordered pane would show at
+// most one log line, so it is rendered as a compact preview instead
+const previewHeight = 5
+
+// usageWarnPercent is how full a buffer gets before its usage is highlighted
+const usageWarnPercent = 80
+
+// idleThreshold is how long a source may stay quiet before it is shown as idle
+const idleThreshold = 30 * time.Second
+
+// TruncateMode selects how lines wider than a pane are shortened
+type TruncateMode int
+
+const (
+	TruncateTail   TruncateMode = iota // Keep the start, cut the end
+	TruncateMiddle                     // Keep the start and end, cut the middle
 )
 
+// LevelAlign selects how level badges are padded so content lines up
+type LevelAlign int
+
+const (
+	LevelAlignLeft  LevelAlign = iota // Pad after the level
+	LevelAlignRight                   // Pad before the level
+	LevelAlignNone                    // No padding, content follows the level directly
+)
+
+// ParseLevelAlign converts "left", "right" or "none" to a LevelAlign
+func ParseLevelAlign(name string) (LevelAlign, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "left":
+		return LevelAlignLeft, nil
+	case "right":
+		return LevelAlignRight, nil
+	case "none":
+		return LevelAlignNone, nil
+	}
+	return 0, fmt.Errorf("unknown level alignment %q, want left, right or none", name)
+}
+
+// LevelStyle selects how an entry's level is written
+type LevelStyle int
+
+const (
+	LevelStyleFull  LevelStyle = iota // The level name, e.g. ERROR
+	LevelStyleShort                   // Its first letter, e.g. E
+	LevelStyleIcon                    // A symbol, e.g. ✗, or the first letter for custom levels
+)
+
+// ParseLevelStyle converts "full", "short" or "icon" to a LevelStyle
+func ParseLevelStyle(name string) (LevelStyle, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "full":
+		return LevelStyleFull, nil
+	case "short":
+		return LevelStyleShort, nil
+	case "icon":
+		return LevelStyleIcon, nil
+	}
+	return 0, fmt.Errorf("unknown level style %q, want full, short or icon", name)
+}
+
+// levelIcons are the icon badges of the built-in levels
+var levelIcons = map[log.LogLevel]string{
+	log.LogLevelDebug: "·",
+	log.LogLevelInfo:  "•",
+	log.LogLevelWarn:  "!",
+	log.LogLevelError: "✗",
+}
+
+// TimeColumn selects which time is shown in front of each entry
+type TimeColumn int
+
+const (
+	TimeEvent  TimeColumn = iota // The line's own timestamp, marked ~ when synthetic
+	TimeIngest                   // When logflow received the line
+)
+
+// ViewOptions carries the app-wide display settings panes render with
+type ViewOptions struct {
+	FilterLevel  log.LogLevel
+	ContextLines int
+	FollowMode   bool
+	Truncation   TruncateMode
+	LevelAlign   LevelAlign
+	LevelStyle   LevelStyle
+	FuzzyQuery   string // Narrows the focused pane to fuzzily matching lines
+	TimeColumn   TimeColumn
+	ShowMetadata bool     // Show flattened metadata after the content
+	Fields       []string // Metadata keys appended to every line, when not showing it all
+	Sparkline    bool     // Show the last minute's activity in pane headers
+	LevelCounts  bool     // Show how many entries of each level panes hold
+	Highlights   []HighlightRule
+	Since        time.Time // Hides entries older than this, zero for none
+}
+
+// equal reports whether two sets of options render a pane the same
+func (o ViewOptions) equal(other ViewOptions) bool {
+	return o.FilterLevel == other.FilterLevel &&
+		o.ContextLines == other.ContextLines &&
+		o.FollowMode == other.FollowMode &&
+		o.Truncation == other.Truncation &&
+		o.LevelAlign == other.LevelAlign &&
+		o.LevelStyle == other.LevelStyle &&
+		o.FuzzyQuery == other.FuzzyQuery &&
+		o.TimeColumn == other.TimeColumn &&
+		o.ShowMetadata == other.ShowMetadata &&
+		slices.Equal(o.Fields, other.Fields) &&
+		o.Sparkline == other.Sparkline &&
+		o.LevelCounts == other.LevelCounts &&
+		sameRules(o.Highlights, other.Highlights) &&
+		o.Since.Equal(other.Since)
+}
+
+// sameRules reports whether two rule lists are the same list. Rules are
+// set once at startup, so this spares comparing compiled patterns.
+func sameRules(a, b []HighlightRule) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+// renderKey is what a pane's rendered lines depend on besides its entries,
+// its scroll position and the view options
+type renderKey struct {
+	width, height    int
+	focused, holding bool
+}
+
+// renderCache holds the lines a pane rendered last frame
+type renderCache struct {
+	key     renderKey
+	opts    ViewOptions
+	content string
+	total   int // Entries passing the filter
+
+	// The whole bordered pane, reused while the header and the border
+	// style stay the same too
+	header string
+	idle   bool
+	frame  string
+}
+
 // Pane represents a single log display pane
 type Pane struct {
 	name       string
 	buffer     *log.Buffer
 	scrollPos  int
+	anchorSeq  uint64 // Seq of the top visible entry, survives eviction
+	entries    []log.LogEntry
 	width      int
 	height     int
 	focused    bool
+	collapsed  bool
+	muted      bool // Left out of the layout and alerts, still buffering
+	waiting    bool // Expected by --watch, nothing received yet
 	lastSearch string
+
+	// boostedFrom is the buffer capacity before it was boosted, zero when
+	// it is not boosted
+	boostedFrom int
+
+	// viewedSeq is the newest entry when focus last left the pane; later
+	// entries come after a "new" divider. Zero until it has been focused.
+	viewedSeq uint64
+
+	// lastEntryTime is when the pane last received an entry, by local clock
+	lastEntryTime time.Time
+	activity      activity
+
+	// lastErrorTime is when the pane last received an entry at the jump to
+	// error level, zero if it never has
+	lastErrorTime time.Time
+
+	// errorTimes are when the newest entries at error level arrived, as
+	// many as the --error-rate threshold needs; rateAlert is set while
+	// errorRate is exceeded
+	errorTimes []time.Time
+	rateAlert  bool
+	errorRate  ErrorRate
+
+	// holdSeq is an entry follow mode is held on until holdUntil, so it
+	// stays on screen; holdPlaced is set once the viewport shows it
+	holdSeq    uint64
+	holdUntil  time.Time
+	holdPlaced bool
+
+	// pipe is the command new entries are teed to, nil when not piped,
+	// and pipeFilter the filter it was last handed
+	pipe       *sinks.CommandSink
+	pipeFilter pipeFilter
+
+	// dirty is set whenever the entries or the viewport change, so the next
+	// frame re-renders the lines instead of reusing cache
+	dirty bool
+	cache renderCache
 }
 
 // NewPane creates a new log pane
 func NewPane(name string, bufferSize int) *Pane {
 	return &Pane{
-		name:   name,
-		buffer: log.NewBuffer(bufferSize),
+		name:          name,
+		buffer:        log.NewBuffer(bufferSize),
+		lastEntryTime: time.Now(),
 	}
 }
 
 // AddEntry adds a log entry to the pane
 func (p *Pane) AddEntry(entry log.LogEntry) {
 	p.buffer.Add(entry)
+	p.dirty = true
+	p.waiting = false
+	p.lastEntryTime = time.Now()
+	p.activity.record(p.lastEntryTime)
+}
+
+// HoldOn pauses follow mode on the entry with the given sequence number
+// for duration, unless the pane is already holding
+func (p *Pane) HoldOn(seq uint64, duration time.Duration) {
+	if p.IsHolding() {
+		return
+	}
+	p.holdSeq = seq
+	p.holdUntil = time.Now().Add(duration)
+	p.holdPlaced = false
+	p.dirty = true
+}
+
+// ReleaseHold resumes follow mode immediately
+func (p *Pane) ReleaseHold() {
+	if p.holdSeq != 0 {
+		p.dirty = true
+	}
+	p.holdSeq = 0
+	p.holdUntil = time.Time{}
+}
+
+// IsHolding reports whether follow mode is held on an entry
+func (p *Pane) IsHolding() bool {
+	return p.holdSeq != 0 && time.Now().Before(p.holdUntil)
+}
+
+// MarkViewed records that everything in the pane has been seen, moving
+// the "new" divider below the current newest entry
+func (p *Pane) MarkViewed() {
+	p.viewedSeq = p.LastSeq()
+	p.dirty = true
+}
+
+// LastSeq returns the sequence number of the newest entry in the pane
+func (p *Pane) LastSeq() uint64 {
+	return p.buffer.LastSeq()
+}
+
+// IdleFor returns how long the pane has gone without a new entry
+func (p *Pane) IdleFor() time.Duration {
+	return time.Since(p.lastEntryTime)
+}
+
+// IsIdle reports whether the source has been quiet past the idle threshold
+func (p *Pane) IsIdle() bool {
+	return p.IdleFor() >= idleThreshold
 }
 
 // Render renders the pane content
-func (p *Pane) Render(width, height int, focused bool, filterLevel log.LogLevel, followMode bool) string {
+func (p *Pane) Render(width, height int, focused bool, opts ViewOptions) string {
 	p.width = width
 	p.height = height
 	p.focused = focused
 
+	if height < previewHeight {
+		return p.renderPreview(width, height, focused, opts)
+	}
+
+	content := p.cachedBody(opts)
+
+	// Create pane header
+	position := p.scrollIndicator(p.cache.total, max(1, height-2))
+	holding := p.IsHolding()
+	if holding {
+		position = "[PAUSED ON ERROR]"
+	}
+	header := p.renderHeader(position, width-4, opts)
+
+	idle := p.IsIdle()
+	if p.cache.frame != "" && p.cache.header == header && p.cache.idle == idle {
+		return p.cache.frame
+	}
+
+	// Apply styling based on focus state
+	var style lipgloss.Style
+	if focused {
+		style = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("39")). // Bright blue
+			Padding(0, 1)
+	} else if p.rateAlert {
+		style = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("9")). // Red, over its error rate
+			Padding(0, 1)
+	} else if idle {
+		style = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("236")). // Dark gray
+			Foreground(lipgloss.Color("243")).
+			Padding(0, 1)
+	} else {
+		style = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("240")). // Gray
+			Padding(0, 1)
+	}
+
+	// Combine header and content
+	paneContent := lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		content,
+	)
+
+	p.cache.header, p.cache.idle = header, idle
+	p.cache.frame = style.Width(width).Height(height).Render(paneContent)
+	return p.cache.frame
+}
+
+// RenderPlain renders only the pane's lines, filling width and height
+// without a border or header, for the single source view
+func (p *Pane) RenderPlain(width, height int, opts ViewOptions) string {
+	// The body leaves room for the borders and padding it goes without here
+	p.width = width + 4
+	p.height = height + 2
+	p.focused = true
+	return p.cachedBody(opts)
+}
+
+// cachedBody returns the body for the pane's current size, reusing the
+// last frame's lines unless the pane or the view changed. Only the header,
+// with its idle time and sparkline, changes by itself.
+func (p *Pane) cachedBody(opts ViewOptions) string {
+	holding := p.IsHolding()
+	key := renderKey{width: p.width, height: p.height, focused: p.focused, holding: holding}
+	if p.dirty || p.cache.key != key || !p.cache.opts.equal(opts) {
+		p.cache = renderCache{
+			key:     key,
+			opts:    opts,
+			content: p.renderBody(p.height, opts, holding),
+			total:   len(p.entries),
+		}
+		p.dirty = false
+	}
+	return p.cache.content
+}
+
+// renderBody filters the pane's entries, places the viewport and renders
+// the visible lines, height rows tall with the borders
+func (p *Pane) renderBody(height int, opts ViewOptions, holding bool) string {
 	// Get filtered entries
-	entries := p.buffer.Filter(filterLevel)
+	entries := p.buffer.FilterWithContext(opts.FilterLevel, opts.ContextLines)
+	if !opts.Since.IsZero() {
+		entries = log.FilterTimeRange(entries, opts.Since, time.Time{})
+	}
+	if p.focused && opts.FuzzyQuery != "" {
+		entries = fuzzyFilter(entries, opts.FuzzyQuery)
+	}
+	p.entries = entries
 
 	// Calculate visible area
 	contentHeight := height - 2 // Account for borders
@@ -48,8 +385,25 @@ func (p *Pane) Render(width, height int, focused bool, filterLevel log.LogLevel,
 		contentHeight = 1
 	}
 
+	// Re-anchor the viewport to the entry it was showing, since evictions
+	// shift indices underneath scrollPos
+	if p.anchorSeq != 0 {
+		p.scrollPos = sort.Search(len(entries), func(i int) bool {
+			return entries[i].Seq >= p.anchorSeq
+		})
+	}
+
+	// While holding, bring the held entry to the bottom once, then stay put
+	if holding && !p.holdPlaced {
+		idx := sort.Search(len(entries), func(i int) bool {
+			return entries[i].Seq >= p.holdSeq
+		})
+		p.scrollPos = idx - contentHeight + 1
+		p.holdPlaced = true
+	}
+
 	// Auto-scroll to bottom if follow mode is enabled
-	if followMode && len(entries) > contentHeight {
+	if opts.FollowMode && !holding && len(entries) > contentHeight {
 		p.scrollPos = len(entries) - contentHeight
 	}
 
@@ -64,6 +418,8 @@ func (p *Pane) Render(width, height int, focused bool, filterLevel log.LogLevel,
 		}
 	}
 
+	p.updateAnchor()
+
 	// Get visible entries
 	var visibleEntries []log.LogEntry
 	if len(entries) > 0 {
@@ -77,101 +433,405 @@ func (p *Pane) Render(width, height int, focused bool, filterLevel log.LogLevel,
 		}
 	}
 
-	// Render entries
+	// Render entries, marking where the lines new since the pane was last
+	// focused begin
 	var lines []string
+	divided := p.viewedSeq == 0
 	for _, entry := range visibleEntries {
-		line := p.formatLogEntry(entry, width-4) // Account for borders and padding
+		if !divided && entry.Seq > p.viewedSeq {
+			lines = append(lines, renderNewDivider(p.width-4))
+			divided = true
+		}
+		line := formatLogEntry(entry, p.width-4, opts) // Account for borders and padding
 		lines = append(lines, line)
 	}
 
+	// The divider takes a row, give up the oldest line for it
+	if len(lines) > contentHeight {
+		lines = lines[len(lines)-contentHeight:]
+	}
+
 	// Fill remaining space with empty lines
 	for len(lines) < contentHeight {
 		lines = append(lines, "")
 	}
 
-	content := strings.Join(lines, "\n")
+	return strings.Join(lines, "\n")
+}
 
-	// Create pane header
-	header := p.renderHeader()
+// renderHeader creates the pane header with name, stats and scroll
+// position, adding the activity sparkline when it fits in width
+func (p *Pane) renderHeader(position string, width int, opts ViewOptions) string {
+	count := p.buffer.Count()
+	status := "●●●" // Active indicator
+	switch {
+	case p.waiting:
+		// Flag a watched source that has not shown up for a while
+		status = "···"
+		waiting := "waiting " + formatIdle(p.IdleFor())
+		if p.IsIdle() {
+			waiting = lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(waiting)
+		}
+		position = waiting + " " + position
+	case p.IsIdle():
+		status = "○○○"
+		position = fmt.Sprintf("idle %s %s", formatIdle(p.IdleFor()), position)
+	}
 
-	// Apply styling based on focus state
-	var style lipgloss.Style
-	if focused {
-		style = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("39")). // Bright blue
-			Padding(0, 1)
-	} else {
-		style = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("240")). // Gray
-			Padding(0, 1)
+	usage := p.renderUsage(count)
+	if p.boostedFrom > 0 {
+		usage += lipgloss.NewStyle().Foreground(lipgloss.Color("14")).
+			Render(fmt.Sprintf(" boost ×%d", p.buffer.Capacity()/p.boostedFrom))
 	}
 
-	// Combine header and content
-	paneContent := lipgloss.JoinVertical(
-		lipgloss.Left,
-		header,
-		content,
-	)
+	header := fmt.Sprintf("%s %s - %s %s", status, p.name, usage, position)
+	if opts.LevelCounts {
+		// Left out rather than cut when the header has no room
+		if counts := p.renderLevelCounts(); counts != "" && lipgloss.Width(header)+1+lipgloss.Width(counts) <= width {
+			header = fmt.Sprintf("%s %s - %s %s %s", status, p.name, usage, counts, position)
+		}
+	}
+	if p.pipe != nil {
+		header += lipgloss.NewStyle().Foreground(lipgloss.Color("13")).
+			Render(" | " + truncate(p.pipe.Command(), maxSourceNameWidth))
+	}
+	if p.rateAlert {
+		header = p.renderRateAlert() + " " + header
+	}
+	if opts.Sparkline {
+		// Counts every entry, so spikes show even through a filter
+		spark := lipgloss.NewStyle().Foreground(lipgloss.Color("39")).
+			Render(sparkline(p.activity.buckets(time.Now()), sparklineWidth))
+		if lipgloss.Width(header)+1+sparklineWidth <= width {
+			header += " " + spark
+		}
+	}
+	return header
+}
 
-	return style.Width(width).Height(height).Render(paneContent)
+// renderLevelCounts lists how many held entries there are of each level,
+// most severe first, like E:12 W:40 I:230. Levels without entries are left
+// out.
+func (p *Pane) renderLevelCounts() string {
+	counts := p.buffer.LevelCounts()
+	levels := log.Levels()
+
+	var badges []string
+	for i := len(levels) - 1; i >= 0; i-- {
+		n := counts[levels[i].Name]
+		if n == 0 {
+			continue
+		}
+		badge := levelBadge(levels[i].Name, LevelStyleShort, LevelAlignNone) + ":" + formatCount(n)
+		if color := levels[i].Color; color != "" {
+			badge = lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(badge)
+		}
+		badges = append(badges, badge)
+	}
+	return strings.Join(badges, " ")
 }
 
-// renderHeader creates the pane header with name and stats
-func (p *Pane) renderHeader() string {
-	count := p.buffer.Count()
-	status := "●●●" // Active indicator
+// renderRateAlert flags a pane over its error rate threshold
+func (p *Pane) renderRateAlert() string {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Background(lipgloss.Color("9")).Bold(true).
+		Render(" errors >" + p.errorRate.String() + " ")
+}
+
+// renderUsage shows how full the buffer is as count/capacity, turning
+// yellow as it nears capacity and red once old lines are being evicted
+func (p *Pane) renderUsage(count int) string {
+	capacity := p.buffer.Capacity()
+	usage := fmt.Sprintf("%s/%s", formatCount(count), formatCount(capacity))
+	if spilled := p.buffer.Spilled(); spilled > 0 {
+		// Older entries live on disk, the buffer being full is expected
+		return fmt.Sprintf("%s +%s on disk", usage, formatCount(spilled))
+	}
+
+	switch {
+	case count >= capacity:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render(usage)
+	case count*100 >= capacity*usageWarnPercent:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(usage)
+	default:
+		return usage
+	}
+}
+
+// formatCount abbreviates large line counts
+func formatCount(n int) string {
+	if n >= 10000 {
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// formatIdle renders an idle duration at its coarsest useful unit
+func formatIdle(d time.Duration) string {
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+}
 
-	if count >= 1000 {
-		countStr := fmt.Sprintf("%.1fk lines", float64(count)/1000)
-		return fmt.Sprintf("%s %s - %s", status, p.name, countStr)
+// formatWindow renders a duration without its zero trailing units, 5m
+// rather than 5m0s
+func formatWindow(d time.Duration) string {
+	text := d.String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
+
+// scrollIndicator describes where the viewport sits within total entries,
+// or reports that it is showing the live tail
+func (p *Pane) scrollIndicator(total, contentHeight int) string {
+	bottom := p.scrollPos + contentHeight
+	if bottom >= total {
+		return "[LIVE]"
 	}
 
-	return fmt.Sprintf("%s %s - %d lines", status, p.name, count)
+	percent := bottom * 100 / total
+	return fmt.Sprintf("%d%% [%d/%d]", percent, bottom, total)
 }
 
 // formatLogEntry formats a log entry for display
-func (p *Pane) formatLogEntry(entry log.LogEntry, maxWidth int) string {
-	timestamp := entry.Timestamp.Format("15:04:05")
+func formatLogEntry(entry log.LogEntry, maxWidth int, opts ViewOptions) string {
+	// A synthetic timestamp is only the ingest time, mark it with ~
+	shown, separator := entry.Timestamp, " "
+	switch {
+	case opts.TimeColumn == TimeIngest && !entry.IngestTime.IsZero():
+		shown = entry.IngestTime
+	case entry.SyntheticTime:
+		separator = "~"
+	}
+	timestamp := log.DisplayTime(shown).Format("15:04:05")
 
 	// Get level color
-	var levelStyle lipgloss.Style
-	switch entry.Level {
-	case log.LogLevelError:
-		levelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9")) // Red
-	case log.LogLevelWarn:
-		levelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11")) // Yellow
-	case log.LogLevelInfo:
-		levelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("12")) // Blue
-	case log.LogLevelDebug:
-		levelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8")) // Gray
+	levelStyle := lipgloss.NewStyle()
+	if color := log.LevelColor(entry.Level); color != "" {
+		levelStyle = levelStyle.Foreground(lipgloss.Color(color))
+	}
+
+	// Format the line, clipping only the content so the level stays visible.
+	// The badge is padded to the widest level so content columns line up.
+	levelStr := levelStyle.Render(levelBadge(entry.Level, opts.LevelStyle, opts.LevelAlign))
+	prefix := fmt.Sprintf("%s%s%s ", timestamp, separator, levelStr)
+
+	// Entries from containers merged into one source carry their container
+	if container, ok := entry.Metadata["container"].(string); ok && container != "" {
+		tag := truncate(container, maxSourceNameWidth)
+		prefix += lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render("["+tag+"]") + " "
+	}
+
+	contentWidth := maxWidth - lipgloss.Width(prefix)
+	if contentWidth < 1 {
+		return truncate(timestamp+separator+string(entry.Level), maxWidth)
+	}
+
+	var content string
+	switch opts.Truncation {
+	case TruncateMiddle:
+		content = truncateMiddle(entry.Content, contentWidth)
+	default:
+		content = truncate(entry.Content, contentWidth)
+	}
+	styled := applyHighlights(content, opts.Highlights)
+
+	// Metadata fills whatever room the content leaves
+	var meta string
+	if opts.ShowMetadata {
+		meta = formatMetadata(entry.Metadata)
+	} else if len(opts.Fields) > 0 {
+		meta = formatFields(entry.Metadata, opts.Fields)
+	}
+	if metaWidth := contentWidth - lipgloss.Width(content) - 2; meta != "" && metaWidth > 0 {
+		styled += "  " + lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(truncate(meta, metaWidth))
+	}
+
+	return prefix + styled
+}
+
+// renderNewDivider renders the "── new ──" rule placed before the first
+// entry that arrived since the pane was last focused
+func renderNewDivider(width int) string {
+	label := " new "
+	side := max(0, width-len(label)) / 2
+	rule := strings.Repeat("─", side) + label + strings.Repeat("─", max(0, width-len(label)-side))
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render(truncate(rule, width))
+}
+
+// formatMetadata renders an entry's metadata as dotted key=value pairs,
+// leaving out the container already shown as a tag
+func formatMetadata(meta map[string]interface{}) string {
+	if _, ok := meta["container"]; ok {
+		rest := make(map[string]interface{}, len(meta))
+		for k, v := range meta {
+			if k != "container" {
+				rest[k] = v
+			}
+		}
+		meta = rest
+	}
+	return log.FormatMetadata(meta, log.DefaultMetadataDepth)
+}
+
+// formatFields renders the named metadata fields as key=value pairs in
+// the order given, leaving out those the entry lacks
+func formatFields(meta map[string]interface{}, fields []string) string {
+	var parts []string
+	for _, key := range fields {
+		if value, ok := log.LookupMetadata(meta, key); ok {
+			parts = append(parts, key+"="+value)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// fuzzyFilter keeps the entries whose content fuzzily matches query, in
+// their original order
+func fuzzyFilter(entries []log.LogEntry, query string) []log.LogEntry {
+	var matched []log.LogEntry
+	for _, entry := range entries {
+		if _, ok := fuzzyScore(query, entry.Content); ok {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+// levelBadge writes the level in style. Full names are padded to the
+// widest registered level; short and icon badges are one column already.
+func levelBadge(level log.LogLevel, style LevelStyle, align LevelAlign) string {
+	switch style {
+	case LevelStyleIcon:
+		if icon, ok := levelIcons[level]; ok {
+			return icon
+		}
+		fallthrough
+	case LevelStyleShort:
+		if r, _ := utf8.DecodeRuneInString(string(level)); r != utf8.RuneError {
+			return string(r)
+		}
+		return "?"
 	default:
-		levelStyle = lipgloss.NewStyle()
+		return padLevel(level, align)
+	}
+}
+
+// padLevel pads the level name to the widest registered level
+func padLevel(level log.LogLevel, align LevelAlign) string {
+	name := string(level)
+	padding := strings.Repeat(" ", max(0, log.LevelWidth()-len(name)))
+
+	switch align {
+	case LevelAlignLeft:
+		return name + padding
+	case LevelAlignRight:
+		return padding + name
+	default:
+		return name
+	}
+}
+
+// updateAnchor records the sequence number of the top visible entry
+func (p *Pane) updateAnchor() {
+	if p.scrollPos < len(p.entries) {
+		p.anchorSeq = p.entries[p.scrollPos].Seq
+	} else {
+		p.anchorSeq = 0
+	}
+}
+
+// renderPreview renders a pane too short for a bordered view: a summary
+// line followed by the most recent entries, making sure the latest error
+// stays visible
+func (p *Pane) renderPreview(width, height int, focused bool, opts ViewOptions) string {
+	if height <= 1 {
+		return p.RenderSummary(width, focused)
+	}
+
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	if focused {
+		style = lipgloss.NewStyle().Foreground(lipgloss.Color("39")) // Bright blue
+	}
+	lines := []string{style.Render(truncate(fmt.Sprintf("▸ %s - %d lines", p.name, p.buffer.Count()), width))}
+
+	entries := p.buffer.Filter(opts.FilterLevel)
+	if !opts.Since.IsZero() {
+		entries = log.FilterTimeRange(entries, opts.Since, time.Time{})
+	}
+	rows := height - 1
+	recent := entries[max(0, len(entries)-rows):]
+
+	// Swap the oldest row for the latest error if it scrolled out of view
+	for i := len(entries) - 1; i >= 0 && len(recent) > 0; i-- {
+		if !entries[i].Level.AtLeast(log.ResolveLevel(log.LogLevelError, 0)) {
+			continue
+		}
+		if entries[i].Seq < recent[0].Seq {
+			recent = append([]log.LogEntry{entries[i]}, recent[1:]...)
+		}
+		break
 	}
 
-	// Format the line
-	levelStr := levelStyle.Render(string(entry.Level))
-	line := fmt.Sprintf("%s %s %s", timestamp, levelStr, entry.Content)
+	for _, entry := range recent {
+		lines = append(lines, formatLogEntry(entry, width, opts))
+	}
+
+	return lipgloss.NewStyle().Width(width).Height(height).Render(strings.Join(lines, "\n"))
+}
+
+// RenderSummary renders the collapsed form of the pane: a single line with
+// the name, entry count and most recent entry
+func (p *Pane) RenderSummary(width int, focused bool) string {
+	summary := fmt.Sprintf("▸ %s - %d lines", p.name, p.buffer.Count())
+	if p.waiting {
+		summary += " │ waiting " + formatIdle(p.IdleFor())
+	}
+	if p.rateAlert {
+		summary += " │ errors >" + p.errorRate.String()
+	}
+	if recent := p.buffer.GetRecent(1); len(recent) > 0 {
+		summary += " │ " + recent[0].Content
+	}
 
-	// Truncate if too long
-	if len(line) > maxWidth {
-		line = line[:maxWidth-3] + "..."
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	if focused {
+		style = lipgloss.NewStyle().Foreground(lipgloss.Color("39")) // Bright blue
 	}
 
-	return line
+	return style.Width(width).Render(truncate(summary, width))
+}
+
+// ToggleCollapsed switches the pane between its full and summary form
+func (p *Pane) ToggleCollapsed() {
+	p.collapsed = !p.collapsed
+}
+
+// IsCollapsed reports whether the pane is shown as a summary line
+func (p *Pane) IsCollapsed() bool {
+	return p.collapsed
 }
 
 // ScrollDown scrolls the pane down
 func (p *Pane) ScrollDown() {
-	entries := p.buffer.GetAll()
-	maxScroll := len(entries) - (p.height - 2)
+	maxScroll := len(p.entries) - (p.height - 2)
 	if maxScroll < 0 {
 		maxScroll = 0
 	}
 
 	if p.scrollPos < maxScroll {
 		p.scrollPos++
+		p.updateAnchor()
+		p.dirty = true
 	}
 }
 
@@ -179,6 +839,8 @@ func (p *Pane) ScrollDown() {
 func (p *Pane) ScrollUp() {
 	if p.scrollPos > 0 {
 		p.scrollPos--
+		p.updateAnchor()
+		p.dirty = true
 	}
 }
 
@@ -186,6 +848,11 @@ func (p *Pane) ScrollUp() {
 func (p *Pane) Clear() {
 	p.buffer.Clear()
 	p.scrollPos = 0
+	p.anchorSeq = 0
+	p.entries = nil
+	p.lastErrorTime = time.Time{}
+	p.dirty = true
+	p.ReleaseHold()
 }
 
 // Search searches for a term in the pane
@@ -198,3 +865,8 @@ func (p *Pane) Search(term string) []log.LogEntry {
 func (p *Pane) GetEntryCount() int {
 	return p.buffer.Count()
 }
+
+// GetEntry returns the entry with the given sequence number
+func (p *Pane) GetEntry(seq uint64) (log.LogEntry, bool) {
+	return p.buffer.Get(seq)
+}
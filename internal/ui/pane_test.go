@@ -0,0 +1,54 @@
+// internal/ui/pane_test.go
+package ui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+)
+
+// benchmarkPane returns a pane with a full buffer of mixed levels
+func benchmarkPane() *Pane {
+	levels := []log.LogLevel{log.LogLevelDebug, log.LogLevelInfo, log.LogLevelWarn, log.LogLevelError}
+	p := NewPane("api", 1000)
+	now := time.Now()
+	for i := 0; i < 1000; i++ {
+		p.AddEntry(log.LogEntry{
+			Timestamp: now.Add(time.Duration(i) * time.Millisecond),
+			Level:     levels[i%len(levels)],
+			Source:    "api",
+			Content:   fmt.Sprintf("request %d handled in %dms path=/api/users/%d", i, i%250, i),
+		})
+	}
+	return p
+}
+
+// benchmarkOptions are the display settings of a default dashboard
+func benchmarkOptions() ViewOptions {
+	return ViewOptions{FilterLevel: log.LowestLevel(), FollowMode: true, LevelCounts: true}
+}
+
+// BenchmarkPaneRenderCached renders unchanged frames, which the render
+// cache serves
+func BenchmarkPaneRenderCached(b *testing.B) {
+	p := benchmarkPane()
+	opts := benchmarkOptions()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Render(120, 40, true, opts)
+	}
+}
+
+// BenchmarkPaneRenderUncached renders every frame from scratch, as when
+// entries keep arriving
+func BenchmarkPaneRenderUncached(b *testing.B) {
+	p := benchmarkPane()
+	opts := benchmarkOptions()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.dirty = true
+		p.Render(120, 40, true, opts)
+	}
+}
@@ -0,0 +1,123 @@
+package ui
+
+import "sort"
+
+// SetMaxPanes caps how many sources get a pane, zero for no cap. Past it,
+// the least recently active source is hidden to make room for a new one;
+// its buffer is kept for search and dumps unless dropHidden is set.
+func (a *App) SetMaxPanes(max int, dropHidden bool) {
+	a.maxPanes = max
+	a.dropHidden = dropHidden
+}
+
+// showPane gives a source a pane, bringing it back if it was hidden, and
+// hides others over the cap
+func (a *App) showPane(name string) {
+	a.unhide(name)
+	a.allPanes = append(a.allPanes, name)
+	if a.isShown(name) {
+		a.paneOrder = append(a.paneOrder, name)
+	}
+
+	for a.maxPanes > 0 && len(a.allPanes) > a.maxPanes {
+		a.hidePane(a.leastRecentlyActive(name))
+	}
+	a.updateLayout()
+}
+
+// leastRecentlyActive returns the shown source that last received an entry
+// the longest ago, other than keep
+func (a *App) leastRecentlyActive(keep string) string {
+	var oldest string
+	for _, name := range a.allPanes {
+		if name == keep {
+			continue
+		}
+		if oldest == "" || a.panes[name].lastEntryTime.Before(a.panes[oldest].lastEntryTime) {
+			oldest = name
+		}
+	}
+	return oldest
+}
+
+// hidePane takes a source's pane off screen, keeping focus on the pane it
+// was on where possible
+func (a *App) hidePane(name string) {
+	a.allPanes = removeName(a.allPanes, name)
+	a.removeFromOrder(name)
+
+	if a.dropHidden {
+		delete(a.panes, name)
+	}
+	a.hiddenPanes[name] = struct{}{}
+	a.refreshTrace()
+}
+
+// removeFromOrder takes a pane out of the layout, keeping focus on the
+// pane it was on where possible
+func (a *App) removeFromOrder(name string) {
+	for i, paneName := range a.paneOrder {
+		if paneName != name {
+			continue
+		}
+		a.paneOrder = append(a.paneOrder[:i], a.paneOrder[i+1:]...)
+		if a.focusedPane > i {
+			a.focusedPane--
+		}
+		break
+	}
+	a.focusedPane = max(0, min(a.focusedPane, len(a.paneOrder)-1))
+	a.dropStaleZoom()
+}
+
+// unhide forgets that a source was hidden
+func (a *App) unhide(name string) {
+	delete(a.hiddenPanes, name)
+}
+
+// isHidden reports whether a source's pane was hidden over the cap
+func (a *App) isHidden(name string) bool {
+	_, hidden := a.hiddenPanes[name]
+	return hidden
+}
+
+// forgetExited forgets hidden sources whose buffer was dropped once their
+// feeder has gone, so a stream of short-lived sources does not pile up. A
+// kept buffer stays, for search and dumps.
+func (a *App) forgetExited() {
+	for name := range a.hiddenPanes {
+		if _, kept := a.panes[name]; !kept && !a.server.Connected(name) {
+			delete(a.hiddenPanes, name)
+		}
+	}
+}
+
+// retainedPanes returns every source whose buffer is still held, shown
+// ones first, then hidden ones least recently active first, the order
+// they were hidden in
+func (a *App) retainedPanes() []string {
+	var hidden []string
+	for name := range a.hiddenPanes {
+		if _, ok := a.panes[name]; ok {
+			hidden = append(hidden, name)
+		}
+	}
+	sort.Slice(hidden, func(i, j int) bool {
+		ti, tj := a.panes[hidden[i]].lastEntryTime, a.panes[hidden[j]].lastEntryTime
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return hidden[i] < hidden[j]
+	})
+	return append(append([]string(nil), a.allPanes...), hidden...)
+}
+
+// removeName returns names without name
+func removeName(names []string, name string) []string {
+	for i, n := range names {
+		if n == name {
+			return append(names[:i], names[i+1:]...)
+		}
+	}
+	return names
+}
@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Yriskit-ai/logflow/internal/ipc"
+	"github.com/Yriskit-ai/logflow/internal/paths"
+	"github.com/Yriskit-ai/logflow/internal/sources"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// containerRuntimes lists the runtimes the picker queries for containers
+var containerRuntimes = []string{"docker", "podman"}
+
+// PickerItem is a container that can be attached from the picker
+type PickerItem struct {
+	Runtime   string
+	Container sources.Container
+}
+
+// Picker lists running containers when no sources are connected yet
+type Picker struct {
+	hint     string
+	items    []PickerItem
+	cursor   int
+	selected map[int]bool
+	loading  bool
+	err      error
+}
+
+// NewPicker creates an empty container picker for a dashboard listening on
+// socketPath
+func NewPicker(socketPath string) *Picker {
+	return &Picker{
+		hint:     feederHint(socketPath),
+		selected: make(map[int]bool),
+		loading:  true,
+	}
+}
+
+// ContainersMsg carries the result of listing running containers
+type ContainersMsg struct {
+	Items []PickerItem
+	Err   error
+}
+
+// SourceErrorMsg reports a source launched from the app that failed
+type SourceErrorMsg struct {
+	Name string
+	Err  error
+}
+
+// loadContainers returns a command that lists containers from every runtime
+func loadContainers() tea.Cmd {
+	return func() tea.Msg {
+		var items []PickerItem
+		var lastErr error
+		for _, runtime := range containerRuntimes {
+			containers, err := sources.ListContainers(runtime, "")
+			if err != nil {
+				// A missing runtime is expected, only report if none work
+				lastErr = err
+				continue
+			}
+			for _, c := range containers {
+				items = append(items, PickerItem{Runtime: runtime, Container: c})
+			}
+		}
+		if len(items) > 0 {
+			lastErr = nil
+		}
+		return ContainersMsg{Items: items, Err: lastErr}
+	}
+}
+
+// SetItems replaces the picker contents
+func (p *Picker) SetItems(items []PickerItem, err error) {
+	p.items = items
+	p.err = err
+	p.loading = false
+	p.cursor = 0
+	p.selected = make(map[int]bool)
+}
+
+// MoveUp moves the cursor to the previous item
+func (p *Picker) MoveUp() {
+	if p.cursor > 0 {
+		p.cursor--
+	}
+}
+
+// MoveDown moves the cursor to the next item
+func (p *Picker) MoveDown() {
+	if p.cursor < len(p.items)-1 {
+		p.cursor++
+	}
+}
+
+// Toggle selects or deselects the item under the cursor
+func (p *Picker) Toggle() {
+	if len(p.items) > 0 {
+		p.selected[p.cursor] = !p.selected[p.cursor]
+	}
+}
+
+// Chosen returns the selected items, or the item under the cursor if
+// nothing has been selected
+func (p *Picker) Chosen() []PickerItem {
+	var chosen []PickerItem
+	for i, item := range p.items {
+		if p.selected[i] {
+			chosen = append(chosen, item)
+		}
+	}
+	if len(chosen) == 0 && p.cursor < len(p.items) {
+		chosen = append(chosen, p.items[p.cursor])
+	}
+	return chosen
+}
+
+// View renders the picker
+func (p *Picker) View() string {
+	lines := []string{"Waiting for log sources...", ""}
+
+	switch {
+	case p.loading:
+		lines = append(lines, "Looking for running containers...")
+	case len(p.items) == 0:
+		if p.err != nil {
+			lines = append(lines, fmt.Sprintf("No containers found: %v", p.err))
+		} else {
+			lines = append(lines, "No running containers found.")
+		}
+	default:
+		lines = append(lines, "Attach to a running container:", "")
+		for i, item := range p.items {
+			cursor := "  "
+			if i == p.cursor {
+				cursor = "> "
+			}
+			check := "[ ]"
+			if p.selected[i] {
+				check = "[x]"
+			}
+			lines = append(lines, fmt.Sprintf("%s%s %s (%s, %s)", cursor, check,
+				item.Container.Name, item.Runtime, item.Container.ID))
+		}
+		lines = append(lines, "", "↑/↓ move • space select • enter attach • r refresh")
+	}
+
+	lines = append(lines, "", "Or start sending logs with:", p.hint)
+	return strings.Join(lines, "\n")
+}
+
+// feederHint builds a copy-pasteable feeder command for the socket the
+// dashboard actually listens on
+func feederHint(socketPath string) string {
+	if socketPath == paths.Socket() {
+		return "python app.py | logflow --source backend"
+	}
+	return fmt.Sprintf("python app.py | logflow --socket %s --source backend", socketPath)
+}
+
+// attachContainer returns a command that streams a container's logs into
+// the running dashboard through its own IPC server
+func attachContainer(item PickerItem, server *ipc.Server) tea.Cmd {
+	return func() tea.Msg {
+		name := item.Container.Name
+
+		client, err := server.Connect()
+		if err != nil {
+			return SourceErrorMsg{Name: name, Err: err}
+		}
+		defer client.Close()
+
+		if err := client.InitSource(name, item.Runtime); err != nil {
+			return SourceErrorMsg{Name: name, Err: err}
+		}
+
+		var src sources.Source
+		switch item.Runtime {
+		case "docker":
+			src = sources.NewDockerSource(name, item.Container.ID)
+		case "podman":
+			src = sources.NewPodmanSource(name, item.Container.ID)
+		default:
+			return SourceErrorMsg{Name: name, Err: fmt.Errorf("unknown container runtime: %s", item.Runtime)}
+		}
+
+		if err := src.Stream(client); err != nil {
+			return SourceErrorMsg{Name: name, Err: err}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,185 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+	"github.com/Yriskit-ai/logflow/internal/sinks"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PipeExitedMsg reports that a command a pane was piped to has exited
+type PipeExitedMsg struct {
+	Source string
+	Sink   *sinks.CommandSink
+	Err    error
+}
+
+// togglePipe prompts for a command to pipe the focused pane to, or stops
+// the pane's pipe when one is running
+func (a *App) togglePipe() {
+	if len(a.paneOrder) == 0 {
+		return
+	}
+	if pane := a.panes[a.paneOrder[a.focusedPane]]; pane.pipe != nil {
+		a.stopPipe(pane)
+		return
+	}
+	a.pipeMode = true
+	a.pipeQuery = ""
+}
+
+// pipeFilter is the part of a pane's view a pipe follows: the entries
+// it is sent are those the pane would show, and, while a search covers
+// the pane, match the search
+type pipeFilter struct {
+	level  log.LogLevel
+	fuzzy  string
+	search string
+}
+
+// match reports whether an entry passes the filter
+func (f pipeFilter) match(entry log.LogEntry) bool {
+	if !entry.Level.AtLeast(f.level) {
+		return false
+	}
+	if f.fuzzy != "" {
+		if _, ok := fuzzyScore(f.fuzzy, entry.Content); !ok {
+			return false
+		}
+	}
+	if f.search != "" {
+		query := strings.ToLower(f.search)
+		if !strings.Contains(strings.ToLower(entry.Content), query) &&
+			!strings.Contains(strings.ToLower(entry.Raw), query) {
+			return false
+		}
+	}
+	return true
+}
+
+// pipeFilterFor returns the filter of a pane as it is now. The fuzzy
+// filter narrows the focused pane only, a search the panes it covers.
+func (a *App) pipeFilterFor(pane *Pane) pipeFilter {
+	filter := pipeFilter{level: a.filterLevel}
+	focused := len(a.paneOrder) > 0 && a.paneOrder[a.focusedPane] == pane.name
+	if focused {
+		filter.fuzzy = a.fuzzyQuery
+	}
+	if a.searchScope == SearchGlobal && a.inActiveGroup(pane.name) || a.searchScope == SearchLocal && focused {
+		filter.search = a.searchQuery
+	}
+	return filter
+}
+
+// syncPipeFilters hands each pipe its pane's filter when it has changed.
+// Pipes are written to from the server's goroutines, so they get a copy
+// rather than reading the app's state.
+func (a *App) syncPipeFilters() {
+	for _, pane := range a.panes {
+		if pane.pipe == nil {
+			continue
+		}
+		if filter := a.pipeFilterFor(pane); filter != pane.pipeFilter {
+			pane.pipeFilter = filter
+			pane.pipe.SetFilter(filter.match)
+		}
+	}
+}
+
+// startPipe spawns command and tees the focused pane's new entries that
+// pass its filter to its stdin
+func (a *App) startPipe(command string) tea.Cmd {
+	if len(a.paneOrder) == 0 {
+		return nil
+	}
+	pane := a.panes[a.paneOrder[a.focusedPane]]
+	sink, err := sinks.NewCommandSink(command, a.filterLevel)
+	if err != nil {
+		a.notice = fmt.Sprintf("Pipe failed: %v", err)
+		return nil
+	}
+	pane.pipe = sink
+	pane.pipeFilter = a.pipeFilterFor(pane)
+	sink.SetFilter(pane.pipeFilter.match)
+	a.server.Subscribe(pane.name, sink)
+	a.notice = fmt.Sprintf("Piping %s to %s", pane.name, command)
+
+	source := pane.name
+	return func() tea.Msg {
+		<-sink.Done()
+		return PipeExitedMsg{Source: source, Sink: sink, Err: sink.Err()}
+	}
+}
+
+// stopPipe ends a pane's pipe, closing the command's input
+func (a *App) stopPipe(pane *Pane) {
+	sink := pane.pipe
+	pane.pipe = nil
+	a.server.Unsubscribe(pane.name, sink)
+	sink.Close()
+
+	a.notice = fmt.Sprintf("Stopped piping %s to %s", pane.name, sink.Command())
+	if dropped := sink.Dropped(); dropped > 0 {
+		a.notice += fmt.Sprintf(", %d entries dropped", dropped)
+	}
+}
+
+// stopPipes ends every pane's pipe, as the dashboard exits, and waits for
+// the commands to finish what they were sent. A command that does not is
+// killed once its stop timeout is up, so this does not wait for long.
+func (a *App) stopPipes() {
+	var stopped []*sinks.CommandSink
+	for _, pane := range a.panes {
+		if pane.pipe != nil {
+			stopped = append(stopped, pane.pipe)
+			a.stopPipe(pane)
+		}
+	}
+	for _, sink := range stopped {
+		<-sink.Done()
+	}
+}
+
+// handlePipeExited forgets a pipe whose command exited by itself. One
+// stopped from the dashboard has already been forgotten.
+func (a *App) handlePipeExited(msg PipeExitedMsg) {
+	pane := a.panes[msg.Source]
+	if pane == nil || pane.pipe != msg.Sink {
+		return
+	}
+	pane.pipe = nil
+	a.server.Unsubscribe(msg.Source, msg.Sink)
+	msg.Sink.Close()
+
+	if msg.Err != nil {
+		a.notice = fmt.Sprintf("Pipe of %s to %s exited: %v", msg.Source, msg.Sink.Command(), msg.Err)
+	} else {
+		a.notice = fmt.Sprintf("Pipe of %s to %s finished", msg.Source, msg.Sink.Command())
+	}
+}
+
+// handlePipeInput edits the command the focused pane is piped to. Enter
+// starts it, esc cancels.
+func (a *App) handlePipeInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		a.pipeMode = false
+		if command := strings.TrimSpace(a.pipeQuery); command != "" {
+			return a, a.startPipe(command)
+		}
+	case "esc":
+		a.pipeMode = false
+	case "backspace":
+		if len(a.pipeQuery) > 0 {
+			runes := []rune(a.pipeQuery)
+			a.pipeQuery = string(runes[:len(runes)-1])
+		}
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			a.pipeQuery += string(msg.Runes)
+		}
+	}
+	return a, nil
+}
@@ -0,0 +1,228 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Preset is a saved combination of filters, recalled by name
+type Preset struct {
+	Name   string       `json:"name"`
+	Level  log.LogLevel `json:"level,omitempty"`  // Minimum level shown, empty for every level
+	Group  string       `json:"group,omitempty"`  // Group tab shown, empty for all sources
+	Search string       `json:"search,omitempty"` // Search run when applied
+	Global bool         `json:"global,omitempty"` // Search every pane rather than the focused one
+	Fuzzy  string       `json:"fuzzy,omitempty"`  // Fuzzy filter of the focused pane
+}
+
+// builtinPresets are offered before any saved preset. A saved preset of
+// the same name replaces one.
+var builtinPresets = []Preset{
+	{Name: "all"},
+	{Name: "errors-only", Level: log.LogLevelError},
+	{Name: "warnings", Level: log.LogLevelWarn},
+}
+
+// LoadPresets reads saved presets from path; a missing file holds none
+func LoadPresets(path string) ([]Preset, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read presets: %w", err)
+	}
+
+	var presets []Preset
+	if err := json.Unmarshal(data, &presets); err != nil {
+		return nil, fmt.Errorf("failed to parse presets in %s: %w", path, err)
+	}
+	return presets, nil
+}
+
+// savePresets writes presets to path as indented JSON
+func savePresets(path string, presets []Preset) error {
+	data, err := json.MarshalIndent(presets, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// SetPresetsFile loads the presets saved in path and saves new ones there
+func (a *App) SetPresetsFile(path string) error {
+	presets, err := LoadPresets(path)
+	if err != nil {
+		return err
+	}
+	a.presetsFile = path
+	a.presets = presets
+	a.commands = a.buildCommands(DefaultKeyMap())
+	return nil
+}
+
+// allPresets returns the built-in presets followed by the saved ones, the
+// saved ones replacing built-ins of the same name
+func (a *App) allPresets() []Preset {
+	var presets []Preset
+	for _, builtin := range builtinPresets {
+		if _, ok := findPreset(a.presets, builtin.Name); !ok {
+			presets = append(presets, builtin)
+		}
+	}
+	return append(presets, a.presets...)
+}
+
+// findPreset returns the preset called name
+func findPreset(presets []Preset, name string) (Preset, bool) {
+	for _, preset := range presets {
+		if strings.EqualFold(preset.Name, name) {
+			return preset, true
+		}
+	}
+	return Preset{}, false
+}
+
+// ApplyPreset applies the built-in or saved preset called name once the
+// first entry arrives, for --preset at startup
+func (a *App) ApplyPreset(name string) error {
+	preset, ok := findPreset(a.allPresets(), name)
+	if !ok {
+		return fmt.Errorf("no preset named %q", name)
+	}
+	a.startPreset = &preset
+	return nil
+}
+
+// applyPreset sets the filter level, group tab, fuzzy filter and search
+// the preset holds, returning the search to run
+func (a *App) applyPreset(preset Preset) tea.Cmd {
+	a.presetName = preset.Name
+	a.notice = "Preset: " + preset.Name
+
+	level := log.LogLevel(strings.ToUpper(string(preset.Level)))
+	if level == "" {
+		level = log.LowestLevel()
+	} else if !log.IsKnownLevel(level) {
+		a.notice = fmt.Sprintf("Preset %s: level %s is not defined", preset.Name, level)
+		level = log.LowestLevel()
+	}
+	a.filterLevel = level
+
+	group := 0
+	for i, g := range a.groups {
+		if preset.Group != "" && g.Name == preset.Group {
+			group = i + 1
+		}
+	}
+	if preset.Group != "" && group == 0 {
+		a.notice = fmt.Sprintf("Preset %s: no group %s", preset.Name, preset.Group)
+	}
+	if group != a.activeGroup {
+		a.activeGroup = group
+		a.refreshVisiblePanes()
+	}
+
+	a.fuzzyQuery = preset.Fuzzy
+
+	a.searchMode = SearchLocal
+	if preset.Global {
+		a.searchMode = SearchGlobal
+	}
+	a.searchQuery = preset.Search
+	cmd := a.performSearch()
+	a.searchMode = SearchNone
+	return cmd
+}
+
+// nextPreset applies the preset after the last one applied, wrapping
+// around
+func (a *App) nextPreset() tea.Cmd {
+	presets := a.allPresets()
+	next := 0
+	for i, preset := range presets {
+		if preset.Name == a.presetName {
+			next = (i + 1) % len(presets)
+		}
+	}
+	return a.applyPreset(presets[next])
+}
+
+// currentPreset captures the filters in effect as a preset called name
+func (a *App) currentPreset(name string) Preset {
+	preset := Preset{
+		Name:   name,
+		Search: a.searchQuery,
+		Global: a.searchScope == SearchGlobal,
+		Fuzzy:  a.fuzzyQuery,
+	}
+	if a.filterLevel != log.LowestLevel() {
+		preset.Level = a.filterLevel
+	}
+	if a.activeGroup > 0 {
+		preset.Group = a.groups[a.activeGroup-1].Name
+	}
+	return preset
+}
+
+// savePreset saves the filters in effect under name, replacing a saved
+// preset of that name, and persists the presets
+func (a *App) savePreset(name string) {
+	preset := a.currentPreset(name)
+	replaced := false
+	for i, saved := range a.presets {
+		if strings.EqualFold(saved.Name, name) {
+			a.presets[i] = preset
+			replaced = true
+		}
+	}
+	if !replaced {
+		a.presets = append(a.presets, preset)
+	}
+	a.presetName = name
+	a.commands = a.buildCommands(DefaultKeyMap())
+
+	if a.presetsFile == "" {
+		a.notice = fmt.Sprintf("Preset %s saved for this session", name)
+		return
+	}
+	if err := savePresets(a.presetsFile, a.presets); err != nil {
+		a.notice = fmt.Sprintf("Preset %s not saved: %v", name, err)
+		return
+	}
+	a.notice = fmt.Sprintf("Preset %s saved", name)
+}
+
+// handlePresetNameInput edits the name the current filters are saved
+// under. Enter saves, esc cancels.
+func (a *App) handlePresetNameInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		a.presetMode = false
+		if name := strings.TrimSpace(a.presetQuery); name != "" {
+			a.savePreset(name)
+		}
+	case "esc":
+		a.presetMode = false
+	case "backspace":
+		if len(a.presetQuery) > 0 {
+			runes := []rune(a.presetQuery)
+			a.presetQuery = string(runes[:len(runes)-1])
+		}
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			a.presetQuery += string(msg.Runes)
+		}
+	}
+	return a, nil
+}
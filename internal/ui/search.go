@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchCheckInterval is how many entries a search scans between checks
+// for cancellation
+const searchCheckInterval = 1024
+
+// SearchResultsMsg carries the matches of a finished background search
+type SearchResultsMsg struct {
+	Gen     uint64 // The search it answers, stale ones are dropped
+	Results []SearchResult
+}
+
+// searchTarget is a pane's buffer captured for a background search
+type searchTarget struct {
+	name   string
+	buffer *log.Buffer
+}
+
+// performSearch cancels any search in flight and starts one for the
+// current query in the background, over the focused pane or every visible
+// pane depending on the search mode
+func (a *App) performSearch() tea.Cmd {
+	a.cancelSearch()
+	a.searchScope = a.searchMode
+	a.searchResults = nil
+
+	if a.searchQuery == "" {
+		return nil
+	}
+
+	var targets []searchTarget
+	if a.searchMode == SearchLocal && len(a.paneOrder) > 0 {
+		name := a.paneOrder[a.focusedPane]
+		targets = append(targets, searchTarget{name: name, buffer: a.panes[name].buffer})
+	} else if a.searchMode == SearchGlobal {
+		// Hidden sources with a buffer still held are searched too
+		for _, name := range a.retainedPanes() {
+			if a.inActiveGroup(name) {
+				targets = append(targets, searchTarget{name: name, buffer: a.panes[name].buffer})
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.searchGen++
+	a.searchCancel = cancel
+	a.searching = true
+
+	gen, query := a.searchGen, a.searchQuery
+	return func() tea.Msg {
+		results, ok := searchBuffers(ctx, query, targets)
+		if !ok {
+			return nil
+		}
+		return SearchResultsMsg{Gen: gen, Results: results}
+	}
+}
+
+// cancelSearch stops the search in flight, if any
+func (a *App) cancelSearch() {
+	if a.searchCancel != nil {
+		a.searchCancel()
+		a.searchCancel = nil
+	}
+	a.searching = false
+}
+
+// handleSearchResults keeps the results of the latest search
+func (a *App) handleSearchResults(msg SearchResultsMsg) {
+	if msg.Gen != a.searchGen {
+		return
+	}
+	a.searchResults = msg.Results
+	a.cancelSearch()
+}
+
+// searchBuffers finds the entries whose content or raw line contains
+// query, ignoring case. It reports false if ctx was cancelled first.
+func searchBuffers(ctx context.Context, query string, targets []searchTarget) ([]SearchResult, bool) {
+	query = strings.ToLower(query)
+
+	var results []SearchResult
+	scanned := 0
+	for _, target := range targets {
+		// Index counts from the oldest entry spilled to disk, if any
+		index := 0
+		match := func(entry log.LogEntry) error {
+			if scanned++; scanned%searchCheckInterval == 0 && ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if strings.Contains(strings.ToLower(entry.Content), query) ||
+				strings.Contains(strings.ToLower(entry.Raw), query) {
+				results = append(results, SearchResult{
+					PaneName: target.name,
+					Entry:    entry,
+					Index:    index,
+					Seq:      entry.Seq,
+				})
+			}
+			index++
+			return nil
+		}
+
+		// Older entries on disk first, then those in memory
+		if target.buffer.EachEntry(match) != nil && ctx.Err() != nil {
+			return nil, false
+		}
+	}
+	return results, ctx.Err() == nil
+}
@@ -2,7 +2,9 @@
 package ui
 
 import (
+	"github.com/Yriskit-ai/logflow/internal/log"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 // Styles contains all the styling for the application
@@ -10,6 +12,7 @@ type Styles struct {
 	Header     lipgloss.Style
 	StatusBar  lipgloss.Style
 	EmptyState lipgloss.Style
+	Debug      lipgloss.Style
 
 	// Pane styles
 	PaneFocused   lipgloss.Style
@@ -41,6 +44,11 @@ func NewStyles() Styles {
 			Foreground(lipgloss.Color("255")).
 			Padding(0, 1),
 
+		Debug: lipgloss.NewStyle().
+			Background(lipgloss.Color("236")).
+			Foreground(lipgloss.Color("214")).
+			Padding(0, 1),
+
 		EmptyState: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("240")).
 			Padding(2).
@@ -84,3 +92,14 @@ func NewStyles() Styles {
 			Padding(0, 1),
 	}
 }
+
+// applyColorMode points lipgloss at the process-wide color decision,
+// leaving terminal detection to lipgloss in auto mode
+func applyColorMode() {
+	switch log.CurrentColorMode() {
+	case log.ColorNever:
+		lipgloss.SetColorProfile(termenv.Ascii)
+	case log.ColorAlways:
+		lipgloss.SetColorProfile(termenv.ANSI256)
+	}
+}
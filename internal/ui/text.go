@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	fieldSeparator = " │ "
+	ellipsis       = "…"
+
+	// maxSourceNameWidth bounds source names shown in the header and status bar
+	maxSourceNameWidth = 20
+)
+
+// truncate shortens s to at most width cells, ending it with an ellipsis
+// when anything was cut
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	return runewidth.Truncate(s, width, ellipsis)
+}
+
+// truncateMiddle shortens s to at most width cells by replacing its middle
+// with an ellipsis, keeping both the start and the end visible
+func truncateMiddle(s string, width int) string {
+	if runewidth.StringWidth(s) <= width {
+		return s
+	}
+	if width <= 1 {
+		return truncate(s, width)
+	}
+
+	tailWidth := (width - 1) / 2
+	headWidth := width - 1 - tailWidth
+
+	head := runewidth.Truncate(s, headWidth, "")
+
+	// Walk back from the end, taking whole runes while they fit
+	runes := []rune(s)
+	start := len(runes)
+	used := 0
+	for start > 0 {
+		w := runewidth.RuneWidth(runes[start-1])
+		if used+w > tailWidth {
+			break
+		}
+		used += w
+		start--
+	}
+
+	return head + ellipsis + string(runes[start:])
+}
+
+// fitFields joins fields with the bar separator. While the result is wider
+// than width, fields are dropped in dropOrder (indices into fields); if it
+// still does not fit, the result is truncated.
+func fitFields(fields []string, dropOrder []int, width int) string {
+	joined := strings.Join(fields, fieldSeparator)
+	if width <= 0 || lipgloss.Width(joined) <= width {
+		return joined
+	}
+
+	dropped := make(map[int]bool)
+	for _, idx := range dropOrder {
+		dropped[idx] = true
+
+		var kept []string
+		for i, field := range fields {
+			if !dropped[i] {
+				kept = append(kept, field)
+			}
+		}
+
+		joined = strings.Join(kept, fieldSeparator)
+		if lipgloss.Width(joined) <= width {
+			return joined
+		}
+	}
+
+	return truncate(joined, width)
+}
+
+// fuzzyScore reports whether the characters of pattern appear in s in
+// order, ignoring case, and scores the match so that prefixes and
+// consecutive characters rank higher
+func fuzzyScore(pattern, s string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(s))
+
+	score := 0
+	matched := 0
+	prev := -2
+	for i, r := range t {
+		if matched == len(p) {
+			break
+		}
+		if r != p[matched] {
+			continue
+		}
+
+		score++
+		if i == prev+1 {
+			score += 2 // Consecutive characters
+		}
+		if i == 0 {
+			score += 3 // Prefix
+		}
+		prev = i
+		matched++
+	}
+
+	if matched < len(p) {
+		return 0, false
+	}
+
+	// The greedy scan above can miss a later contiguous run
+	if strings.Contains(string(t), string(p)) {
+		score += 2 * len(p)
+	}
+	return score, true
+}
+
+// fuzzyRank returns the names fuzzily matching pattern, best match first.
+// Equal scores prefer the shorter name, then the original order.
+func fuzzyRank(pattern string, names []string) []string {
+	type match struct {
+		name  string
+		score int
+	}
+
+	var matches []match
+	for _, name := range names {
+		if score, ok := fuzzyScore(pattern, name); ok {
+			matches = append(matches, match{name, score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].name) < len(matches[j].name)
+	})
+
+	ranked := make([]string, len(matches))
+	for i, m := range matches {
+		ranked[i] = m.name
+	}
+	return ranked
+}
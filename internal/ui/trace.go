@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// SetTraceKeys sets the metadata keys, dotted for nested ones, a trace or
+// request ID is looked up under when following a trace
+func (a *App) SetTraceKeys(keys []string) {
+	a.traceKeys = keys
+}
+
+// toggleTrace follows the trace of the newest entry on screen in the
+// focused pane that carries one, or goes back to the panes when already
+// following one
+func (a *App) toggleTrace() {
+	if a.viewMode == ViewTrace {
+		a.viewMode = ViewMultiPane
+		a.trace, a.traceStreams = nil, nil
+		a.updateLayout()
+		return
+	}
+	if len(a.paneOrder) == 0 {
+		return
+	}
+
+	id, ok := a.panes[a.paneOrder[a.focusedPane]].visibleTraceID(a.traceKeys)
+	if !ok {
+		return
+	}
+	a.traceID = id
+	a.traceScroll = 0
+	a.viewMode = ViewTrace
+	a.refreshTrace()
+	a.updateLayout()
+}
+
+// visibleTraceID returns the trace ID of the lowest entry shown in the
+// pane that has one
+func (p *Pane) visibleTraceID(keys []string) (string, bool) {
+	end := min(len(p.entries), p.scrollPos+max(1, p.height-2))
+	for i := end - 1; i >= p.scrollPos && i >= 0; i-- {
+		if id, ok := log.TraceID(p.entries[i].Metadata, keys); ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// inTrace reports whether an entry carries the followed trace ID
+func (a *App) inTrace(entry log.LogEntry) bool {
+	id, ok := log.TraceID(entry.Metadata, a.traceKeys)
+	return ok && id == a.traceID
+}
+
+// refreshTrace recollects the followed trace while the trace view is
+// open, after the panes it is drawn from were cleared, hidden or regrouped.
+// Each source in the active group is one stream of the merge, so its
+// entries keep their arrival order whatever their timestamps say.
+func (a *App) refreshTrace() {
+	if a.viewMode != ViewTrace {
+		return
+	}
+
+	var streams [][]log.LogEntry
+	a.traceStreams = make(map[string]int)
+	for _, name := range a.retainedPanes() {
+		if !a.inActiveGroup(name) {
+			continue
+		}
+		var stream []log.LogEntry
+		for _, entry := range a.panes[name].buffer.GetAll() {
+			if a.inTrace(entry) {
+				stream = append(stream, entry)
+			}
+		}
+		a.traceStreams[name] = len(streams)
+		streams = append(streams, stream)
+	}
+	a.trace = log.Merge(streams...)
+}
+
+// addToTrace inserts an arriving entry into the open trace view if it
+// belongs to the followed trace, where log.Merge would have put it: after
+// the last entry of its source, before the first later entry, sources
+// with equal timestamps ordered as in the merge
+func (a *App) addToTrace(entry log.LogEntry) {
+	if a.viewMode != ViewTrace || !a.inActiveGroup(entry.Source) || !a.inTrace(entry) {
+		return
+	}
+	stream, ok := a.traceStreams[entry.Source]
+	if !ok {
+		stream = len(a.traceStreams)
+		a.traceStreams[entry.Source] = stream
+	}
+
+	i := len(a.trace)
+	for i > 0 && a.trace[i-1].Source != entry.Source {
+		i--
+	}
+	for ; i < len(a.trace); i++ {
+		other := a.trace[i]
+		if entry.Timestamp.Before(other.Timestamp) ||
+			(entry.Timestamp.Equal(other.Timestamp) && stream < a.traceStreams[other.Source]) {
+			break
+		}
+	}
+
+	// Keep the lines on screen in place while scrolled back
+	if a.traceScroll > 0 && i >= len(a.trace)-a.traceScroll {
+		a.traceScroll++
+	}
+	a.trace = append(a.trace, log.LogEntry{})
+	copy(a.trace[i+1:], a.trace[i:])
+	a.trace[i] = entry
+}
+
+// scrollTrace moves the trace view delta lines back in time, zero being
+// the newest entries
+func (a *App) scrollTrace(delta int) {
+	a.traceScroll = max(0, a.traceScroll+delta)
+}
+
+// renderTraceView renders the entries sharing the followed trace ID from
+// every source as one list, each line tagged with its source
+func (a *App) renderTraceView() string {
+	height := a.contentHeight()
+	entries := a.trace
+
+	sources := make(map[string]bool)
+	nameWidth := 0
+	for _, entry := range entries {
+		sources[entry.Source] = true
+		nameWidth = max(nameWidth, runewidth.StringWidth(entry.Source))
+	}
+	nameWidth = min(nameWidth, maxSourceNameWidth)
+
+	title := fmt.Sprintf("Trace %s - %d entries across %d sources", a.traceID, len(entries), len(sources))
+	lines := []string{a.styles.PaneHeader.Render(truncate(title, a.width))}
+
+	// Show the newest entries that fit, traceScroll lines back
+	visible := max(1, height-1)
+	a.traceScroll = min(a.traceScroll, max(0, len(entries)-visible))
+	end := len(entries) - a.traceScroll
+	start := max(0, end-visible)
+
+	opts := a.viewOptions()
+	sourceStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	for _, entry := range entries[start:end] {
+		label := sourceStyle.Render(runewidth.FillRight(truncate(entry.Source, nameWidth), nameWidth)) + " "
+		lines = append(lines, label+formatLogEntry(entry, a.width-lipgloss.Width(label), opts))
+	}
+
+	return lipgloss.NewStyle().Width(a.width).Height(height).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,287 @@
+// internal/web/web.go
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+	"github.com/Yriskit-ai/logflow/internal/sinks"
+)
+
+// streamBuffer is how many entries a stream client may fall behind by
+// before entries are dropped for it
+const streamBuffer = 256
+
+// shutdownTimeout bounds how long Close waits for requests in flight
+const shutdownTimeout = 2 * time.Second
+
+// Server serves the entries the dashboard receives over HTTP, read-only.
+// It is a sink of the IPC server, keeping a buffer per source of its own
+// so requests never touch the TUI's state:
+//
+//	GET /sources          every source with its entry count, as JSON
+//	GET /logs/{source}    the source's buffered entries, as a JSON array
+//	GET /stream/{source}  the source's new entries as server-sent events
+type Server struct {
+	bufferSize int
+	listener   net.Listener
+	http       *http.Server
+
+	mutex   sync.RWMutex
+	buffers map[string]*log.Buffer
+	order   []string // Sources in the order they first sent an entry
+	streams map[string]map[*sinks.ChannelSink]struct{}
+	closed  bool
+}
+
+// sourceInfo describes a source in the /sources listing
+type sourceInfo struct {
+	Name    string `json:"name"`
+	Entries int    `json:"entries"`
+	LastSeq uint64 `json:"last_seq"`
+}
+
+// NewServer starts serving on addr, keeping up to bufferSize entries per
+// source. An address without a host, like ":8088", listens on localhost
+// only. Requests must name this machine or addr's host in their Host
+// header.
+func NewServer(addr string, bufferSize int) (*Server, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid web address %q: %w", addr, err)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s := &Server{
+		bufferSize: bufferSize,
+		listener:   listener,
+		buffers:    make(map[string]*log.Buffer),
+		streams:    make(map[string]map[*sinks.ChannelSink]struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sources", s.handleSources)
+	mux.HandleFunc("/logs/", s.handleLogs)
+	mux.HandleFunc("/stream/", s.handleStream)
+	s.http = &http.Server{Handler: allowHosts(host, readOnly(mux)), ReadHeaderTimeout: 10 * time.Second}
+
+	go s.http.Serve(listener)
+	return s, nil
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Write buffers the entry and hands it to the source's stream clients,
+// dropping it for those that fall behind
+func (s *Server) Write(entry log.LogEntry) error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+	buffer, ok := s.buffers[entry.Source]
+	if !ok {
+		buffer = log.NewBuffer(s.bufferSize)
+		s.buffers[entry.Source] = buffer
+		s.order = append(s.order, entry.Source)
+	}
+	buffer.Add(entry)
+	entry.Seq = buffer.LastSeq() // Event IDs match /logs
+	s.mutex.Unlock()
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for stream := range s.streams[entry.Source] {
+		stream.Write(entry)
+	}
+	return nil
+}
+
+// Close stops serving and ends every stream
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	s.closed = true
+	for _, streams := range s.streams {
+		for stream := range streams {
+			stream.Close()
+		}
+	}
+	s.streams = make(map[string]map[*sinks.ChannelSink]struct{})
+	s.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return s.http.Shutdown(ctx)
+}
+
+// readOnly rejects every method but GET and HEAD
+func readOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "read-only", http.StatusMethodNotAllowed)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowHosts rejects requests whose Host header names anything but this
+// machine or listenHost, so a web page can't read the entries by pointing
+// a domain name of its own at 127.0.0.1
+func allowHosts(listenHost string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hostAllowed(r.Host, listenHost) {
+			http.Error(w, fmt.Sprintf("unknown host %q", r.Host), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// hostAllowed reports whether a Host header names localhost, a loopback
+// address or listenHost. Listening on every interface, any of the
+// machine's addresses is allowed, but no other name.
+func hostAllowed(header, listenHost string) bool {
+	host := header
+	if h, _, err := net.SplitHostPort(header); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if host == "" {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") || strings.EqualFold(host, listenHost) {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	listenIP := net.ParseIP(listenHost)
+	return ip.IsLoopback() || listenIP != nil && (listenIP.Equal(ip) || listenIP.IsUnspecified())
+}
+
+// handleSources lists every source, in the order they first sent an entry
+func (s *Server) handleSources(w http.ResponseWriter, r *http.Request) {
+	s.mutex.RLock()
+	sources := make([]sourceInfo, 0, len(s.order))
+	for _, name := range s.order {
+		buffer := s.buffers[name]
+		sources = append(sources, sourceInfo{Name: name, Entries: buffer.Count(), LastSeq: buffer.LastSeq()})
+	}
+	s.mutex.RUnlock()
+
+	writeJSON(w, sources)
+}
+
+// handleLogs returns a source's buffered entries, oldest first
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	buffer, ok := s.buffer(strings.TrimPrefix(r.URL.Path, "/logs/"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, buffer.GetAll())
+}
+
+// handleStream sends a source's new entries as server-sent events until
+// the client goes away or the server closes. Sources that have not sent
+// anything yet can be streamed too.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/stream/")
+	flusher, ok := w.(http.Flusher)
+	if name == "" || !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	stream := sinks.NewChannelSink(streamBuffer)
+	if !s.subscribe(name, stream) {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	defer s.unsubscribe(name, stream)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case entry, ok := <-stream.Entries():
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(entry)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", entry.Seq, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// buffer returns a source's buffer
+func (s *Server) buffer(name string) (*log.Buffer, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	buffer, ok := s.buffers[name]
+	return buffer, ok
+}
+
+// subscribe registers a stream client of a source, reporting false once
+// the server is closed
+func (s *Server) subscribe(name string, stream *sinks.ChannelSink) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return false
+	}
+	if s.streams[name] == nil {
+		s.streams[name] = make(map[*sinks.ChannelSink]struct{})
+	}
+	s.streams[name][stream] = struct{}{}
+	return true
+}
+
+// unsubscribe forgets a stream client and closes its channel
+func (s *Server) unsubscribe(name string, stream *sinks.ChannelSink) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.streams[name], stream)
+	if len(s.streams[name]) == 0 {
+		delete(s.streams, name)
+	}
+	stream.Close()
+}
+
+// writeJSON writes v as the JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
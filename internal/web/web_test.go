@@ -0,0 +1,341 @@
+// internal/web/web_test.go
+package web
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Yriskit-ai/logflow/internal/log"
+)
+
+// testTimeout bounds every wait on the server, so a broken stream fails
+// instead of hanging
+const testTimeout = 5 * time.Second
+
+// startServer starts a server on a free localhost port, closed when the
+// test ends
+func startServer(t *testing.T, bufferSize int) *Server {
+	t.Helper()
+	s, err := NewServer("127.0.0.1:0", bufferSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// get requests path from the server and returns the response body,
+// failing the test unless the status is want
+func get(t *testing.T, s *Server, path string, want int) []byte {
+	t.Helper()
+	resp, err := http.Get("http://" + s.Addr() + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != want {
+		t.Fatalf("GET %s returned %d, want %d: %s", path, resp.StatusCode, want, body)
+	}
+	return body
+}
+
+// writeLines writes entries numbered first to last from source
+func writeLines(s *Server, source string, first, last int) {
+	for i := first; i <= last; i++ {
+		s.Write(log.LogEntry{Source: source, Level: log.LogLevelInfo, Content: fmt.Sprintf("line %d", i)})
+	}
+}
+
+// streamCount returns how many stream clients a source has
+func streamCount(s *Server, source string) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.streams[source])
+}
+
+// waitFor polls cond until it holds, failing the test after testTimeout
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSources(t *testing.T) {
+	s := startServer(t, 3)
+	writeLines(s, "db", 1, 2)
+	writeLines(s, "api", 1, 5)
+
+	var sources []sourceInfo
+	if err := json.Unmarshal(get(t, s, "/sources", http.StatusOK), &sources); err != nil {
+		t.Fatal(err)
+	}
+	want := []sourceInfo{{Name: "db", Entries: 2, LastSeq: 2}, {Name: "api", Entries: 3, LastSeq: 5}}
+	if fmt.Sprint(sources) != fmt.Sprint(want) {
+		t.Errorf("/sources = %+v, want %+v", sources, want)
+	}
+}
+
+func TestSourcesEmpty(t *testing.T) {
+	s := startServer(t, 3)
+	if body := strings.TrimSpace(string(get(t, s, "/sources", http.StatusOK))); body != "[]" {
+		t.Errorf("/sources with no sources = %s, want []", body)
+	}
+}
+
+func TestLogs(t *testing.T) {
+	s := startServer(t, 3)
+	writeLines(s, "api", 1, 5)
+	writeLines(s, "api#2", 1, 1)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "/logs/api", want: []string{"line 3", "line 4", "line 5"}},
+		{path: "/logs/api%232", want: []string{"line 1"}},
+	}
+	for _, tt := range tests {
+		var entries []log.LogEntry
+		if err := json.Unmarshal(get(t, s, tt.path, http.StatusOK), &entries); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, entry := range entries {
+			got = append(got, entry.Content)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	get(t, s, "/logs/missing", http.StatusNotFound)
+	get(t, s, "/logs/", http.StatusNotFound)
+}
+
+// openStream starts streaming a source, returning a reader of the events
+// and a function that hangs up
+func openStream(t *testing.T, s *Server, source string) (*bufio.Reader, context.CancelFunc) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+s.Addr()+"/stream/"+source, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("stream answered %d with %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	return bufio.NewReader(resp.Body), cancel
+}
+
+// readEvent reads one server-sent event, returning its id and entry
+func readEvent(t *testing.T, r *bufio.Reader) (string, log.LogEntry) {
+	t.Helper()
+	var id string
+	var entry log.LogEntry
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended mid-event: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return id, entry
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &entry); err != nil {
+				t.Fatalf("event data %q: %v", line, err)
+			}
+		default:
+			t.Fatalf("unexpected event line %q", line)
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	s := startServer(t, 10)
+	// Entries from before the client connected are not streamed
+	writeLines(s, "api", 1, 2)
+
+	events, hangUp := openStream(t, s, "api")
+	waitFor(t, "the stream to subscribe", func() bool { return streamCount(s, "api") == 1 })
+
+	writeLines(s, "db", 1, 1)
+	writeLines(s, "api", 3, 4)
+	for i := 3; i <= 4; i++ {
+		id, entry := readEvent(t, events)
+		if want := fmt.Sprintf("line %d", i); entry.Content != want || entry.Source != "api" {
+			t.Errorf("event = %+v, want api's %s", entry, want)
+		}
+		if id != fmt.Sprint(i) {
+			t.Errorf("event id = %s, want the entry's seq %d", id, i)
+		}
+	}
+
+	// Hanging up unsubscribes
+	hangUp()
+	waitFor(t, "the stream to unsubscribe", func() bool { return streamCount(s, "api") == 0 })
+	writeLines(s, "api", 5, 5)
+}
+
+func TestStreamSourceNotSeenYet(t *testing.T) {
+	s := startServer(t, 10)
+	events, _ := openStream(t, s, "later")
+	waitFor(t, "the stream to subscribe", func() bool { return streamCount(s, "later") == 1 })
+
+	writeLines(s, "later", 1, 1)
+	if _, entry := readEvent(t, events); entry.Content != "line 1" {
+		t.Errorf("event = %+v, want the first entry", entry)
+	}
+}
+
+func TestCloseEndsStreams(t *testing.T) {
+	s := startServer(t, 10)
+	events, _ := openStream(t, s, "api")
+	waitFor(t, "the stream to subscribe", func() bool { return streamCount(s, "api") == 1 })
+
+	closed := make(chan error, 1)
+	go func() { closed <- s.Close() }()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Errorf("Close returned %v", err)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("Close did not return with a stream open")
+	}
+
+	if _, err := events.ReadString('\n'); err == nil {
+		t.Error("the stream stayed open after Close")
+	}
+
+	// Closed, the server takes no new streams or entries
+	rec := httptest.NewRecorder()
+	s.handleStream(rec, httptest.NewRequest(http.MethodGet, "/stream/api", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("stream after Close answered %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	writeLines(s, "api", 1, 1)
+	if _, ok := s.buffer("api"); ok {
+		t.Error("an entry written after Close was kept")
+	}
+}
+
+func TestWriteRacesClose(t *testing.T) {
+	s := startServer(t, 10)
+	for i := 0; i < 3; i++ {
+		openStream(t, s, "api")
+	}
+	waitFor(t, "the streams to subscribe", func() bool { return streamCount(s, "api") == 3 })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(source string) {
+			defer wg.Done()
+			writeLines(s, source, 1, 500)
+		}([]string{"api", "db"}[i%2])
+	}
+	writeLines(s, "api", 1, 50)
+	s.Close()
+	wg.Wait()
+}
+
+func TestReadOnly(t *testing.T) {
+	s := startServer(t, 10)
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodPatch} {
+		rec := httptest.NewRecorder()
+		s.http.Handler.ServeHTTP(rec, httptest.NewRequest(method, "http://localhost/sources", nil))
+		if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, HEAD" {
+			t.Errorf("%s answered %d with Allow %q", method, rec.Code, rec.Header().Get("Allow"))
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	s.http.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "http://localhost/sources", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("HEAD answered %d", rec.Code)
+	}
+}
+
+func TestHostAllowed(t *testing.T) {
+	tests := []struct {
+		header string
+		listen string
+		want   bool
+	}{
+		{header: "localhost:8088", listen: "127.0.0.1", want: true},
+		{header: "LocalHost", listen: "127.0.0.1", want: true},
+		{header: "127.0.0.1:8088", listen: "127.0.0.1", want: true},
+		{header: "127.0.0.1", listen: "127.0.0.1", want: true},
+		{header: "[::1]:8088", listen: "127.0.0.1", want: true},
+		{header: "[::1]", listen: "127.0.0.1", want: true},
+		{header: "evil.example:8088", listen: "127.0.0.1"},
+		{header: "evil.example", listen: "127.0.0.1"},
+		{header: "localhost.evil.example:8088", listen: "127.0.0.1"},
+		{header: "192.168.1.5:8088", listen: "127.0.0.1"},
+		{header: "", listen: "127.0.0.1"},
+		{header: "logs.internal:8088", listen: "logs.internal", want: true},
+		{header: "other.internal:8088", listen: "logs.internal"},
+		{header: "192.168.1.5:8088", listen: "192.168.1.5", want: true},
+		{header: "192.168.1.6:8088", listen: "192.168.1.5"},
+		{header: "192.168.1.5:8088", listen: "0.0.0.0", want: true},
+		{header: "[fe80::1]:8088", listen: "::", want: true},
+		{header: "evil.example:8088", listen: "0.0.0.0"},
+	}
+
+	for _, tt := range tests {
+		if got := hostAllowed(tt.header, tt.listen); got != tt.want {
+			t.Errorf("hostAllowed(%q, %q) = %v, want %v", tt.header, tt.listen, got, tt.want)
+		}
+	}
+}
+
+func TestUnknownHostRejected(t *testing.T) {
+	s := startServer(t, 10)
+	writeLines(s, "api", 1, 1)
+
+	// As a page on a domain rebound to 127.0.0.1 would ask
+	req, err := http.NewRequest(http.MethodGet, "http://"+s.Addr()+"/logs/api", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Host = "evil.example:8088"
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusForbidden || strings.Contains(string(body), "line 1") {
+		t.Errorf("request for evil.example answered %d: %s", resp.StatusCode, body)
+	}
+
+	get(t, s, "/logs/api", http.StatusOK)
+}